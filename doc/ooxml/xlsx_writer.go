@@ -0,0 +1,308 @@
+package ooxml
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/askasoft/pango/iox"
+	"github.com/askasoft/pango/str"
+)
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`
+
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+
+	xlsxWorkbookHead = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="`
+
+	xlsxWorkbookTail = `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	// cellXfs: 0 = General, 1 = yyyy-mm-dd hh:mm:ss
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy\-mm\-dd\ hh:mm:ss"/></numFmts>` +
+		`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>` +
+		`</styleSheet>`
+
+	xlsxSheetHead = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+	xlsxSheetTail = `</sheetData></worksheet>`
+)
+
+// xlsxEpoch is the base date of the excel date serial number (1900 date system)
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// ErrXlsxWriterClosed is returned by XlsxWriter.WriteRow after the writer is closed.
+var ErrXlsxWriterClosed = errors.New("ooxml: xlsx writer is closed")
+
+// XlsxWriter is a minimal streaming xlsx writer which writes a single worksheet row by row.
+// Strings are written as inline strings, so no shared strings table is buffered in memory.
+type XlsxWriter struct {
+	// SheetName the name of the worksheet, default is "Sheet1".
+	SheetName string
+
+	zw     *zip.Writer
+	sw     *bufio.Writer
+	row    int
+	closed bool
+}
+
+// NewXlsxWriter returns a new XlsxWriter that writes the xlsx document to w.
+func NewXlsxWriter(w io.Writer) *XlsxWriter {
+	return &XlsxWriter{
+		zw: zip.NewWriter(w),
+	}
+}
+
+func (xw *XlsxWriter) writeEntry(name string, data ...string) error {
+	w, err := xw.zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range data {
+		if _, err := iox.WriteString(w, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (xw *XlsxWriter) start() error {
+	name := xw.SheetName
+	if name == "" {
+		name = "Sheet1"
+	}
+
+	if err := xw.writeEntry("[Content_Types].xml", xlsxContentTypes); err != nil {
+		return err
+	}
+	if err := xw.writeEntry("_rels/.rels", xlsxRels); err != nil {
+		return err
+	}
+	if err := xw.writeEntry("xl/_rels/workbook.xml.rels", xlsxWorkbookRels); err != nil {
+		return err
+	}
+	if err := xw.writeEntry("xl/workbook.xml", xlsxWorkbookHead, xlsxEscapeAttr(name), xlsxWorkbookTail); err != nil {
+		return err
+	}
+	if err := xw.writeEntry("xl/styles.xml", xlsxStyles); err != nil {
+		return err
+	}
+
+	w, err := xw.zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+
+	xw.sw = bufio.NewWriter(w)
+	_, err = xw.sw.WriteString(xlsxSheetHead)
+	return err
+}
+
+// Row returns the count of the written rows.
+func (xw *XlsxWriter) Row() int {
+	return xw.row
+}
+
+// WriteRow writes a row with the cell values.
+// The supported cell value types are string, []byte, bool, integers, floats, time.Time,
+// fmt.Stringer and nil (empty cell). Other types are formatted with fmt.Sprint().
+func (xw *XlsxWriter) WriteRow(cells ...any) error {
+	if xw.closed {
+		return ErrXlsxWriterClosed
+	}
+
+	if xw.sw == nil {
+		if err := xw.start(); err != nil {
+			return err
+		}
+	}
+
+	xw.row++
+
+	sw := xw.sw
+	sw.WriteString(`<row r="`)
+	sw.WriteString(strconv.Itoa(xw.row))
+	sw.WriteString(`">`)
+
+	for i, v := range cells {
+		if err := xw.writeCell(xlsxCellName(i, xw.row), v); err != nil {
+			return err
+		}
+	}
+
+	_, err := sw.WriteString(`</row>`)
+	return err
+}
+
+func (xw *XlsxWriter) writeCell(ref string, v any) error {
+	switch c := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return xw.writeString(ref, c)
+	case []byte:
+		return xw.writeString(ref, string(c))
+	case bool:
+		b := "0"
+		if c {
+			b = "1"
+		}
+		return xw.writeValue(ref, ` t="b"`, b)
+	case int:
+		return xw.writeValue(ref, "", strconv.FormatInt(int64(c), 10))
+	case int8:
+		return xw.writeValue(ref, "", strconv.FormatInt(int64(c), 10))
+	case int16:
+		return xw.writeValue(ref, "", strconv.FormatInt(int64(c), 10))
+	case int32:
+		return xw.writeValue(ref, "", strconv.FormatInt(int64(c), 10))
+	case int64:
+		return xw.writeValue(ref, "", strconv.FormatInt(c, 10))
+	case uint:
+		return xw.writeValue(ref, "", strconv.FormatUint(uint64(c), 10))
+	case uint8:
+		return xw.writeValue(ref, "", strconv.FormatUint(uint64(c), 10))
+	case uint16:
+		return xw.writeValue(ref, "", strconv.FormatUint(uint64(c), 10))
+	case uint32:
+		return xw.writeValue(ref, "", strconv.FormatUint(uint64(c), 10))
+	case uint64:
+		return xw.writeValue(ref, "", strconv.FormatUint(c, 10))
+	case float32:
+		return xw.writeFloat(ref, float64(c), 32)
+	case float64:
+		return xw.writeFloat(ref, c, 64)
+	case time.Time:
+		if c.IsZero() {
+			return nil
+		}
+		// excel has no time zone, use the wall clock of the time
+		wc := time.Date(c.Year(), c.Month(), c.Day(), c.Hour(), c.Minute(), c.Second(), c.Nanosecond(), time.UTC)
+		days := float64(wc.Unix()-xlsxEpoch.Unix())/86400 + float64(wc.Nanosecond())/86400e9
+		return xw.writeValue(ref, ` s="1"`, strconv.FormatFloat(days, 'f', -1, 64))
+	case fmt.Stringer:
+		return xw.writeString(ref, c.String())
+	default:
+		return xw.writeString(ref, fmt.Sprint(v))
+	}
+}
+
+func (xw *XlsxWriter) writeFloat(ref string, f float64, bitSize int) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return xw.writeString(ref, strconv.FormatFloat(f, 'g', -1, bitSize))
+	}
+	return xw.writeValue(ref, "", strconv.FormatFloat(f, 'g', -1, bitSize))
+}
+
+func (xw *XlsxWriter) writeValue(ref, attrs, val string) error {
+	sw := xw.sw
+	sw.WriteString(`<c r="`)
+	sw.WriteString(ref)
+	sw.WriteString(`"`)
+	sw.WriteString(attrs)
+	sw.WriteString(`><v>`)
+	sw.WriteString(val)
+	_, err := sw.WriteString(`</v></c>`)
+	return err
+}
+
+func (xw *XlsxWriter) writeString(ref, s string) error {
+	if s == "" {
+		return nil
+	}
+
+	sw := xw.sw
+	sw.WriteString(`<c r="`)
+	sw.WriteString(ref)
+	sw.WriteString(`" t="inlineStr"><is><t xml:space="preserve">`)
+	if err := xml.EscapeText(sw, str.UnsafeBytes(s)); err != nil {
+		return err
+	}
+	_, err := sw.WriteString(`</t></is></c>`)
+	return err
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (xw *XlsxWriter) Flush() error {
+	if xw.sw != nil {
+		if err := xw.sw.Flush(); err != nil {
+			return err
+		}
+	}
+	return xw.zw.Flush()
+}
+
+// Close finishes writing the worksheet and the xlsx document.
+// It does not close the underlying writer.
+func (xw *XlsxWriter) Close() error {
+	if xw.closed {
+		return nil
+	}
+
+	if xw.sw == nil {
+		if err := xw.start(); err != nil {
+			return err
+		}
+	}
+	xw.closed = true
+
+	if _, err := xw.sw.WriteString(xlsxSheetTail); err != nil {
+		return err
+	}
+	if err := xw.sw.Flush(); err != nil {
+		return err
+	}
+	return xw.zw.Close()
+}
+
+// xlsxCellName returns the cell reference name, e.g. (0, 1) -> "A1", (27, 2) -> "AB2"
+func xlsxCellName(col, row int) string {
+	var bs [8]byte
+
+	i := len(bs)
+	for col++; col > 0; col = (col - 1) / 26 {
+		i--
+		bs[i] = byte('A' + (col-1)%26)
+	}
+	return string(bs[i:]) + strconv.Itoa(row)
+}
+
+func xlsxEscapeAttr(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
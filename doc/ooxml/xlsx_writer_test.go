@@ -0,0 +1,91 @@
+package ooxml
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestXlsxCellName(t *testing.T) {
+	cs := []struct {
+		col, row int
+		w        string
+	}{
+		{0, 1, "A1"},
+		{25, 2, "Z2"},
+		{26, 3, "AA3"},
+		{27, 4, "AB4"},
+		{701, 5, "ZZ5"},
+		{702, 6, "AAA6"},
+	}
+
+	for i, c := range cs {
+		a := xlsxCellName(c.col, c.row)
+		if a != c.w {
+			t.Errorf("[%d] xlsxCellName(%d, %d) = %q, want %q", i, c.col, c.row, a, c.w)
+		}
+	}
+}
+
+func TestXlsxWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	xw := NewXlsxWriter(buf)
+	xw.SheetName = "R&D"
+	if err := xw.WriteRow("Name", "Age", "Active", "Joined"); err != nil {
+		t.Fatal(err)
+	}
+	if err := xw.WriteRow("a<b>", 12, true, time.Date(2000, 1, 2, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	if err := xw.WriteRow(nil, 1.5, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := xw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := xw.WriteRow("x"); err != ErrXlsxWriterClosed {
+		t.Errorf("WriteRow() after Close() = %v, want %v", err, ErrXlsxWriterClosed)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{}
+	for _, zf := range zr.File {
+		r, err := zf.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		bs, _ := io.ReadAll(r)
+		r.Close()
+		files[zf.Name] = string(bs)
+	}
+
+	for _, n := range []string{"[Content_Types].xml", "_rels/.rels", "xl/_rels/workbook.xml.rels", "xl/workbook.xml", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[n]; !ok {
+			t.Errorf("missing zip entry %q", n)
+		}
+	}
+
+	if !strings.Contains(files["xl/workbook.xml"], `<sheet name="R&amp;D"`) {
+		t.Errorf("invalid workbook.xml: %s", files["xl/workbook.xml"])
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	wants := []string{
+		`<row r="1"><c r="A1" t="inlineStr"><is><t xml:space="preserve">Name</t></is></c>`,
+		`<c r="A2" t="inlineStr"><is><t xml:space="preserve">a&lt;b&gt;</t></is></c><c r="B2"><v>12</v></c><c r="C2" t="b"><v>1</v></c><c r="D2" s="1"><v>36527.5</v></c></row>`,
+		`<row r="3"><c r="B3"><v>1.5</v></c><c r="C3" t="b"><v>0</v></c></row></sheetData></worksheet>`,
+	}
+	for _, w := range wants {
+		if !strings.Contains(sheet, w) {
+			t.Errorf("sheet1.xml does not contains %q:\n%s", w, sheet)
+		}
+	}
+}
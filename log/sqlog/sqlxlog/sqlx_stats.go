@@ -0,0 +1,176 @@
+package sqlxlog
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	reNormSpace  = regexp.MustCompile(`\s+`)
+	reNormString = regexp.MustCompile(`'(?:[^']|'')*'`)
+	reNormNumber = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	reNormParam  = regexp.MustCompile(`\$\d+|:arg\d+|@p\d+`)
+	reNormIn     = regexp.MustCompile(`(?i)\bIN\s*\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	reNormValues = regexp.MustCompile(`(?i)\bVALUES\s*(\([^()]*\))(?:\s*,\s*\([^()]*\))+`)
+)
+
+// NormalizeSQL normalize the sql statement for grouping the identical statements.
+// The literals and placeholders are replaced with "?", the "IN (?, ?, ...)" list is collapsed to "IN (?)",
+// and the multi-row "VALUES (?, ?), (?, ?), ..." list is collapsed to the first row "VALUES (?, ?)".
+func NormalizeSQL(sql string) string {
+	sql = strings.TrimSpace(reNormSpace.ReplaceAllString(sql, " "))
+	sql = reNormString.ReplaceAllString(sql, "?")
+	sql = reNormParam.ReplaceAllString(sql, "?")
+	sql = reNormNumber.ReplaceAllString(sql, "?")
+	sql = reNormIn.ReplaceAllString(sql, "IN (?)")
+	sql = reNormValues.ReplaceAllString(sql, "VALUES $1")
+	return sql
+}
+
+// StatementStats the latency statistics of a normalized statement.
+// The durations are exported as nanoseconds in JSON.
+type StatementStats struct {
+	SQL    string        `json:"sql"`
+	Count  int64         `json:"count"`
+	Errors int64         `json:"errors"`
+	Total  time.Duration `json:"total"`
+	Max    time.Duration `json:"max"`
+	P50    time.Duration `json:"p50"`
+	P99    time.Duration `json:"p99"`
+}
+
+type stmtStats struct {
+	StatementStats
+	samples []time.Duration
+	next    int
+}
+
+func (ss *stmtStats) add(d time.Duration, err error, maxSamples int) {
+	ss.Count++
+	ss.Total += d
+	ss.Max = max(ss.Max, d)
+	if err != nil {
+		ss.Errors++
+	}
+
+	if len(ss.samples) < maxSamples {
+		ss.samples = append(ss.samples, d)
+	} else {
+		ss.samples[ss.next] = d
+		ss.next = (ss.next + 1) % len(ss.samples)
+	}
+}
+
+func (ss *stmtStats) snapshot() StatementStats {
+	st := ss.StatementStats
+
+	ds := make([]time.Duration, len(ss.samples))
+	copy(ds, ss.samples)
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+
+	st.P50 = percentile(ds, 0.50)
+	st.P99 = percentile(ds, 0.99)
+	return st
+}
+
+func percentile(ds []time.Duration, p float64) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p*float64(len(ds)))) - 1
+	return ds[max(i, 0)]
+}
+
+// DefaultMaxStatements the default max number of the statements of Stats
+const DefaultMaxStatements = 1000
+
+// Stats aggregates the per statement latency statistics.
+// The percentiles are calculated from the latest MaxSamples durations of each statement.
+type Stats struct {
+	MaxSamples int
+
+	// MaxStatements the max number of the statements, 0 means no limit.
+	// If the limit is reached, the statement with the least total duration is evicted for a new statement.
+	MaxStatements int
+
+	mu    sync.Mutex
+	stmts map[string]*stmtStats
+}
+
+// NewStats create a Stats
+func NewStats(maxSamples int) *Stats {
+	return &Stats{
+		MaxSamples:    maxSamples,
+		MaxStatements: DefaultMaxStatements,
+		stmts:         make(map[string]*stmtStats),
+	}
+}
+
+// Add adds a execution of the normalized statement
+func (s *Stats) Add(sql string, d time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stmts == nil {
+		s.stmts = make(map[string]*stmtStats)
+	}
+
+	ss, ok := s.stmts[sql]
+	if !ok {
+		if s.MaxStatements > 0 {
+			for len(s.stmts) >= s.MaxStatements {
+				s.evict()
+			}
+		}
+		ss = &stmtStats{StatementStats: StatementStats{SQL: sql}}
+		s.stmts[sql] = ss
+	}
+	ss.add(d, err, max(s.MaxSamples, 1))
+}
+
+// evict removes the statement with the least total duration
+func (s *Stats) evict() {
+	var least *stmtStats
+	for _, ss := range s.stmts {
+		if least == nil || ss.Total < least.Total {
+			least = ss
+		}
+	}
+	delete(s.stmts, least.SQL)
+}
+
+// Snapshot returns the statistics of all statements, sorted by the total duration descending.
+func (s *Stats) Snapshot() []StatementStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sts := make([]StatementStats, 0, len(s.stmts))
+	for _, ss := range s.stmts {
+		sts = append(sts, ss.snapshot())
+	}
+
+	sort.Slice(sts, func(i, j int) bool {
+		if sts[i].Total == sts[j].Total {
+			return sts[i].SQL < sts[j].SQL
+		}
+		return sts[i].Total > sts[j].Total
+	})
+	return sts
+}
+
+// Reset clears all statistics
+func (s *Stats) Reset() {
+	s.mu.Lock()
+	s.stmts = make(map[string]*stmtStats)
+	s.mu.Unlock()
+}
+
+// MarshalJSON implements json.Marshaler, returns the JSON array of the Snapshot().
+func (s *Stats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Snapshot())
+}
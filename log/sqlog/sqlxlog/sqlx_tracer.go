@@ -0,0 +1,218 @@
+package sqlxlog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/askasoft/pango/log"
+	"github.com/askasoft/pango/sqx"
+	"github.com/askasoft/pango/sqx/sqlx"
+	"github.com/askasoft/pango/str"
+	"github.com/askasoft/pango/tmu"
+)
+
+type nplusoneKey struct{}
+
+type nplusone struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (n *nplusone) incr(sql string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.counts[sql]++
+	return n.counts[sql]
+}
+
+// WithNPlusOne returns a context which counts the identical normalized SELECT statements
+// executed with it, for the N+1 pattern detection of the SqlxTracer (e.g. per http request).
+func WithNPlusOne(ctx context.Context) context.Context {
+	return context.WithValue(ctx, nplusoneKey{}, &nplusone{counts: make(map[string]int)})
+}
+
+// SqlxTracer a sqlx.ContextTrace implementation which logs the statements with fields (log.Event.Props),
+// flags the slow statements, captures the EXPLAIN plan of the slow SELECT statements,
+// detects the N+1 patterns and aggregates the per statement latency statistics.
+//
+//	st := sqlxlog.NewSqlxTracer(log.GetLogger("SQL"), time.Second)
+//	st.Explainer = db.DB()
+//	st.Stats = sqlxlog.NewStats(1000)
+//	db.SetContextTrace(st.Trace)
+type SqlxTracer struct {
+	Logger         log.Logger
+	SQLLevel       log.Level
+	ErrorSQLLevel  log.Level
+	SlowSQLLevel   log.Level
+	SlowThreshold  time.Duration
+	MaxParamLength int
+	TraceErrNoRows bool
+
+	// Explainer the database to run EXPLAIN for the slow SELECT statements, nil to disable.
+	// It must not be traced by this tracer (e.g. the underlying *sql.DB).
+	// The EXPLAIN runs synchronously in Trace, so it adds the latency of a EXPLAIN query to every slow SELECT statement.
+	Explainer     sqx.ContextQueryer
+	ExplainPrefix string
+
+	// NPlusOne the count of the identical SELECT statements executed in a context (see WithNPlusOne)
+	// to report a N+1 pattern, 0 to disable.
+	NPlusOne      int
+	NPlusOneLevel log.Level
+
+	// Stats the statistics of the statements, nil to disable.
+	Stats *Stats
+}
+
+// NewSqlxTracer create a SqlxTracer
+func NewSqlxTracer(logger log.Logger, slowSQL time.Duration) *SqlxTracer {
+	return &SqlxTracer{
+		Logger:         logger,
+		SQLLevel:       log.LevelDebug,
+		ErrorSQLLevel:  log.LevelError,
+		SlowSQLLevel:   log.LevelWarn,
+		SlowThreshold:  slowSQL,
+		MaxParamLength: 100,
+		ExplainPrefix:  "EXPLAIN ",
+		NPlusOneLevel:  log.LevelWarn,
+	}
+}
+
+func (st *SqlxTracer) write(lvl log.Level, fields map[string]any, msg string, data ...any) {
+	le := log.NewEvent(st.Logger, lvl, fmt.Sprintf(msg, data...))
+
+	props := make(map[string]any, len(le.Props)+len(fields))
+	for k, v := range le.Props {
+		props[k] = v
+	}
+	for k, v := range fields {
+		props[k] = v
+	}
+	le.Props = props
+
+	if st.Logger.GetCallerSkip() > 0 {
+		le.CallerStop("/sqx/sqlx/", st.Logger.GetTraceLevel() >= lvl)
+	}
+	st.Logger.Write(le)
+}
+
+func isSelect(sql string) bool {
+	return str.StartsWithFold(str.StripLeft(sql), "SELECT")
+}
+
+// isPseudoSQL returns true if the sql is a pseudo statement traced by sqlx,
+// e.g. "Begin()", "Commit()", "Prepare: SELECT ...".
+func isPseudoSQL(sql string) bool {
+	switch sql {
+	case "Ping()", "PingContext()", "Begin()", "Commit()", "Rollback()":
+		return true
+	}
+	return str.StartsWith(sql, "BeginTx(") || str.StartsWith(sql, "Prepare: ") || str.StartsWith(sql, "PrepareContext: ")
+}
+
+// Trace implements sqlx.ContextTrace.
+// The pseudo statements (e.g. "Begin()", "Commit()", "Prepare: ...") are logged, but not aggregated by the Stats.
+func (st *SqlxTracer) Trace(ctx context.Context, bind sqlx.Binder, start time.Time, sql string, args []any, rows int64, err error) {
+	td := time.Since(start)
+
+	var norm string
+	if (st.Stats != nil || st.NPlusOne > 0) && !isPseudoSQL(sql) {
+		norm = NormalizeSQL(sql)
+	}
+
+	if st.Stats != nil && norm != "" {
+		st.Stats.Add(norm, td, err)
+	}
+
+	if st.NPlusOne > 0 && norm != "" && isSelect(sql) {
+		if n, ok := ctx.Value(nplusoneKey{}).(*nplusone); ok {
+			if cnt := n.incr(norm); cnt == st.NPlusOne && st.Logger.IsLevelEnabled(st.NPlusOneLevel) {
+				st.write(st.NPlusOneLevel, map[string]any{"sql": norm, "nplusone": cnt}, "N+1 >= %d %s", cnt, norm)
+			}
+		}
+	}
+
+	fields := map[string]any{
+		"elapsed": td.Milliseconds(),
+	}
+	if rows >= 0 {
+		fields["rows"] = rows
+	}
+
+	switch {
+	case err != nil && (st.TraceErrNoRows || !errors.Is(err, sqlx.ErrNoRows)):
+		if st.Logger.IsLevelEnabled(st.ErrorSQLLevel) {
+			sql = bind.Explain(sql, st.MaxParamLength, args...)
+			fields["sql"] = sql
+			fields["error"] = err.Error()
+			st.write(st.ErrorSQLLevel, fields, "%s [%s] %s", err, tmu.HumanDuration(td), sql)
+		}
+	case st.SlowThreshold != 0 && td > st.SlowThreshold:
+		if st.Logger.IsLevelEnabled(st.SlowSQLLevel) {
+			plan := st.explain(ctx, sql, args)
+
+			sql = bind.Explain(sql, st.MaxParamLength, args...)
+			fields["sql"] = sql
+			fields["slow"] = true
+
+			msg := fmt.Sprintf("SLOW >= %s [%s] %s", tmu.HumanDuration(st.SlowThreshold), tmu.HumanDuration(td), sql)
+			if plan != "" {
+				fields["plan"] = plan
+				msg += "\n" + plan
+			}
+			st.write(st.SlowSQLLevel, fields, "%s", msg)
+		}
+	default:
+		if st.Logger.IsLevelEnabled(st.SQLLevel) {
+			sql = bind.Explain(sql, st.MaxParamLength, args...)
+			fields["sql"] = sql
+			st.write(st.SQLLevel, fields, "[%s] %s", tmu.HumanDuration(td), sql)
+		}
+	}
+}
+
+// explain returns the EXPLAIN plan of the SELECT statement, the rows are joined by "\n" and the columns are joined by " | ".
+func (st *SqlxTracer) explain(ctx context.Context, query string, args []any) string {
+	if st.Explainer == nil || !isSelect(query) {
+		return ""
+	}
+
+	rows, err := st.Explainer.QueryContext(context.WithoutCancel(ctx), st.ExplainPrefix+query, args...)
+	if err != nil {
+		return "EXPLAIN: " + err.Error()
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return "EXPLAIN: " + err.Error()
+	}
+
+	vals := make([]sql.NullString, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+
+	var lines []string
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return "EXPLAIN: " + err.Error()
+		}
+
+		ss := make([]string, len(vals))
+		for i, v := range vals {
+			ss[i] = v.String
+		}
+		lines = append(lines, strings.Join(ss, " | "))
+	}
+	if err := rows.Err(); err != nil {
+		return "EXPLAIN: " + err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,201 @@
+package sqlxlog
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/askasoft/pango/log"
+	"github.com/askasoft/pango/sqx/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type testWriter struct {
+	mu     sync.Mutex
+	events []*log.Event
+}
+
+func (tw *testWriter) Write(le *log.Event) {
+	tw.mu.Lock()
+	tw.events = append(tw.events, le)
+	tw.mu.Unlock()
+}
+
+func (tw *testWriter) Flush() {
+}
+
+func (tw *testWriter) Close() {
+}
+
+func TestNormalizeSQL(t *testing.T) {
+	cs := []struct {
+		s string
+		w string
+	}{
+		{"SELECT *\n  FROM t WHERE id = $1", "SELECT * FROM t WHERE id = ?"},
+		{"SELECT * FROM t1 WHERE name = 'a''b' AND age > 10", "SELECT * FROM t1 WHERE name = ? AND age > ?"},
+		{"SELECT * FROM t WHERE id IN (?, ?, ?)", "SELECT * FROM t WHERE id IN (?)"},
+		{"SELECT * FROM t WHERE id in (@p1,@p2)", "SELECT * FROM t WHERE id IN (?)"},
+		{"INSERT INTO t (a, b) VALUES ($1, $2), ($3, $4), ($5, $6)", "INSERT INTO t (a, b) VALUES (?, ?)"},
+		{"INSERT INTO t (a, b) values (1, 'x'),(2, 'y') ON CONFLICT (a) DO NOTHING", "INSERT INTO t (a, b) VALUES (?, ?) ON CONFLICT (a) DO NOTHING"},
+	}
+
+	for i, c := range cs {
+		if a := NormalizeSQL(c.s); a != c.w {
+			t.Errorf("#%d NormalizeSQL(%q) = %q, want %q", i, c.s, a, c.w)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	st := NewStats(100)
+	for i := 1; i <= 100; i++ {
+		st.Add("a", time.Duration(i)*time.Millisecond, nil)
+	}
+	st.Add("b", time.Second, context.Canceled)
+
+	ss := st.Snapshot()
+	if len(ss) != 2 {
+		t.Fatalf("len(Snapshot()) = %d, want 2", len(ss))
+	}
+
+	a := ss[0]
+	if a.SQL != "a" || a.Count != 100 || a.P50 != 50*time.Millisecond || a.P99 != 99*time.Millisecond || a.Max != 100*time.Millisecond {
+		t.Errorf("Snapshot()[0] = %+v", a)
+	}
+	if b := ss[1]; b.SQL != "b" || b.Errors != 1 || b.P99 != time.Second {
+		t.Errorf("Snapshot()[1] = %+v", b)
+	}
+
+	bs, err := json.Marshal(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bs), `"sql":"a","count":100`) {
+		t.Errorf("json.Marshal() = %s", bs)
+	}
+
+	st.Reset()
+	if ss := st.Snapshot(); len(ss) != 0 {
+		t.Errorf("Snapshot() after Reset() = %v", ss)
+	}
+}
+
+func TestStatsMaxStatements(t *testing.T) {
+	st := NewStats(10)
+	st.MaxStatements = 2
+
+	st.Add("a", 3*time.Second, nil)
+	st.Add("b", time.Second, nil)
+	st.Add("c", 2*time.Second, nil)
+
+	ss := st.Snapshot()
+	if len(ss) != 2 || ss[0].SQL != "a" || ss[1].SQL != "c" {
+		t.Errorf("Snapshot() = %+v", ss)
+	}
+
+	// the existing statement is not limited
+	st.Add("a", time.Second, nil)
+	if ss := st.Snapshot(); len(ss) != 2 || ss[0].Count != 2 {
+		t.Errorf("Snapshot() = %+v", ss)
+	}
+}
+
+func TestSqlxTracer(t *testing.T) {
+	tw := &testWriter{}
+
+	lg := log.NewLog()
+	lg.SetLevel(log.LevelDebug)
+	lg.SetWriter(tw)
+
+	db, err := sqlx.Open("sqlite3", filepath.Join(t.TempDir(), "trace.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	st := NewSqlxTracer(lg.GetLogger("SQL"), time.Nanosecond)
+	st.Explainer = db.DB()
+	st.ExplainPrefix = "EXPLAIN QUERY PLAN "
+	st.NPlusOne = 3
+	st.Stats = NewStats(10)
+	db.SetContextTrace(st.Trace)
+
+	ctx := WithNPlusOne(context.Background())
+	if _, err := db.ExecContext(ctx, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 5; i++ {
+		if _, err := db.ExecContext(ctx, "INSERT INTO users (id, name) VALUES (?, ?)", i, "u"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 1; i <= 4; i++ {
+		var name string
+		if err := db.GetContext(ctx, &name, "SELECT name FROM users WHERE id = ?", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, "INSERT INTO nothing VALUES (1)"); err == nil {
+		t.Fatal("expect error")
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	lg.Close()
+
+	var nplus1, plans, errs int
+	for _, le := range tw.events {
+		if le.Props["nplusone"] != nil {
+			nplus1++
+			if le.Level != log.LevelWarn {
+				t.Errorf("N+1 level = %v", le.Level)
+			}
+			continue
+		}
+		if p, ok := le.Props["plan"].(string); ok {
+			plans++
+			if !strings.Contains(p, "users") {
+				t.Errorf("plan = %q", p)
+			}
+		}
+		if le.Props["error"] != nil {
+			errs++
+		}
+		if le.Props["sql"] == nil || le.Props["elapsed"] == nil {
+			t.Errorf("missing fields: %v", le.Props)
+		}
+	}
+
+	if nplus1 != 1 {
+		t.Errorf("N+1 events = %d, want 1", nplus1)
+	}
+	if plans != 4 {
+		t.Errorf("plan events = %d, want 4", plans)
+	}
+	if errs != 1 {
+		t.Errorf("error events = %d, want 1", errs)
+	}
+
+	var sel *StatementStats
+	for _, ss := range st.Stats.Snapshot() {
+		if ss.SQL == "SELECT name FROM users WHERE id = ?" {
+			sel = &ss
+		}
+		if isPseudoSQL(ss.SQL) {
+			t.Errorf("stats contains the pseudo statement %q", ss.SQL)
+		}
+	}
+	if sel == nil || sel.Count != 4 {
+		t.Errorf("stats of select = %+v", sel)
+	}
+}
@@ -0,0 +1,145 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidRange is returned by ParseRange if the range header is invalid.
+	ErrInvalidRange = errors.New("invalid range")
+
+	// ErrNoOverlap is returned by ParseRange if first-byte-pos of all of the byte-range-spec values is greater than the content size.
+	ErrNoOverlap = errors.New("invalid range: failed to overlap")
+)
+
+// HttpRange specifies the byte range to be sent to the client.
+type HttpRange struct {
+	Start  int64
+	Length int64
+}
+
+// ContentRange returns the value of the Content-Range header.
+func (r HttpRange) ContentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.Start, r.Start+r.Length-1, size)
+}
+
+// MimeHeader returns the header of the multipart/byteranges part.
+func (r HttpRange) MimeHeader(contentType string, size int64) textproto.MIMEHeader {
+	return textproto.MIMEHeader{
+		"Content-Range": {r.ContentRange(size)},
+		"Content-Type":  {contentType},
+	}
+}
+
+// ParseRange parses a Range header string as per RFC 7233.
+// ErrNoOverlap is returned if none of the ranges overlap.
+func ParseRange(s string, size int64) ([]HttpRange, error) {
+	if s == "" {
+		return nil, nil // header not present
+	}
+
+	const b = "bytes="
+	if !strings.HasPrefix(s, b) {
+		return nil, ErrInvalidRange
+	}
+
+	var ranges []HttpRange
+
+	noOverlap := false
+	for ra := range strings.SplitSeq(s[len(b):], ",") {
+		ra = textproto.TrimString(ra)
+		if ra == "" {
+			continue
+		}
+
+		start, end, ok := strings.Cut(ra, "-")
+		if !ok {
+			return nil, ErrInvalidRange
+		}
+		start, end = textproto.TrimString(start), textproto.TrimString(end)
+
+		var r HttpRange
+		if start == "" {
+			// If no start is specified, end specifies the
+			// range start relative to the end of the file,
+			// and we are dealing with <suffix-length>
+			// which has to be a non-negative integer as per
+			// RFC 7233 Section 2.1 "Byte-Ranges".
+			if end == "" || end[0] == '-' {
+				return nil, ErrInvalidRange
+			}
+			i, err := strconv.ParseInt(end, 10, 64)
+			if i < 0 || err != nil {
+				return nil, ErrInvalidRange
+			}
+			if i > size {
+				i = size
+			}
+			r.Start = size - i
+			r.Length = size - r.Start
+		} else {
+			i, err := strconv.ParseInt(start, 10, 64)
+			if err != nil || i < 0 {
+				return nil, ErrInvalidRange
+			}
+			if i >= size {
+				// If the range begins after the size of the content,
+				// then it does not overlap.
+				noOverlap = true
+				continue
+			}
+			r.Start = i
+			if end == "" {
+				// If no end is specified, range extends to end of the file.
+				r.Length = size - r.Start
+			} else {
+				i, err := strconv.ParseInt(end, 10, 64)
+				if err != nil || r.Start > i {
+					return nil, ErrInvalidRange
+				}
+				if i >= size {
+					i = size - 1
+				}
+				r.Length = i - r.Start + 1
+			}
+		}
+		ranges = append(ranges, r)
+	}
+
+	if noOverlap && len(ranges) == 0 {
+		// The specified ranges did not overlap with the content.
+		return nil, ErrNoOverlap
+	}
+	return ranges, nil
+}
+
+// CheckIfRange checks the If-Range header of the request as per RFC 9110 Section 13.1.5.
+// Returns true if the If-Range header is not present, or it matches the etag (strong comparison)
+// or the modified time, which means the Range header should be evaluated.
+func CheckIfRange(req *http.Request, etag string, modtime time.Time) bool {
+	ir := req.Header.Get("If-Range")
+	if ir == "" {
+		return true
+	}
+
+	if ir[0] == '"' || strings.HasPrefix(ir, "W/") {
+		// weak etag never matches in If-Range
+		return etag != "" && !strings.HasPrefix(etag, "W/") && ir == etag
+	}
+
+	if modtime.IsZero() {
+		return false
+	}
+
+	t, err := http.ParseTime(ir)
+	if err != nil {
+		return false
+	}
+	return modtime.Truncate(time.Second).Equal(t)
+}
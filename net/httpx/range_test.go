@@ -0,0 +1,71 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/askasoft/pango/test/assert"
+)
+
+func TestParseRange(t *testing.T) {
+	cs := []struct {
+		s    string
+		size int64
+		want []HttpRange
+		err  error
+	}{
+		{"", 10, nil, nil},
+		{"bytes=0-4", 10, []HttpRange{{0, 5}}, nil},
+		{"bytes=2-", 10, []HttpRange{{2, 8}}, nil},
+		{"bytes=-3", 10, []HttpRange{{7, 3}}, nil},
+		{"bytes=-20", 10, []HttpRange{{0, 10}}, nil},
+		{"bytes=5-20", 10, []HttpRange{{5, 5}}, nil},
+		{"bytes=0-1, 4-5", 10, []HttpRange{{0, 2}, {4, 2}}, nil},
+		{"bytes=0-1,10-", 10, []HttpRange{{0, 2}}, nil},
+		{"bytes=10-", 10, nil, ErrNoOverlap},
+		{"bytes=5-4", 10, nil, ErrInvalidRange},
+		{"bytes=a-", 10, nil, ErrInvalidRange},
+		{"bytes=--1", 10, nil, ErrInvalidRange},
+		{"bytes=1", 10, nil, ErrInvalidRange},
+		{"items=0-1", 10, nil, ErrInvalidRange},
+	}
+
+	for i, c := range cs {
+		a, err := ParseRange(c.s, c.size)
+		assert.Equal(t, c.err, err, "[%d] %s", i, c.s)
+		assert.Equal(t, c.want, a, "[%d] %s", i, c.s)
+	}
+}
+
+func TestHttpRangeContentRange(t *testing.T) {
+	assert.Equal(t, "bytes 2-5/10", HttpRange{2, 4}.ContentRange(10))
+}
+
+func TestCheckIfRange(t *testing.T) {
+	modtime := time.Date(2020, 1, 2, 3, 4, 5, 600, time.UTC)
+
+	cs := []struct {
+		ifRange string
+		etag    string
+		want    bool
+	}{
+		{"", "", true},
+		{`"a"`, `"a"`, true},
+		{`"a"`, `"b"`, false},
+		{`"a"`, "", false},
+		{`W/"a"`, `W/"a"`, false},
+		{modtime.Format(http.TimeFormat), "", true},
+		{modtime.Add(time.Second).Format(http.TimeFormat), "", false},
+		{"invalid", "", false},
+	}
+
+	for i, c := range cs {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if c.ifRange != "" {
+			req.Header.Set("If-Range", c.ifRange)
+		}
+		assert.Equal(t, c.want, CheckIfRange(req, c.etag, modtime), "[%d] %s", i, c.ifRange)
+	}
+}
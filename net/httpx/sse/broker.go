@@ -0,0 +1,322 @@
+package sse
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrBrokerClosed is returned by the Broker.Serve if the broker is closed.
+var ErrBrokerClosed = errors.New("sse: broker closed")
+
+// ErrSlowSubscriber is returned by the Broker.Serve if the subscriber can not keep up with the published events.
+// The client can reconnect with the Last-Event-ID header to receive the missed events.
+var ErrSlowSubscriber = errors.New("sse: slow subscriber")
+
+// Subscriber is a subscriber of a topic
+type Subscriber struct {
+	events chan *Event
+	gap    bool
+}
+
+// Gap returns true if the Last-Event-ID of the subscription is not found in the replay buffer
+// (unknown or expired), so the missed events can not be replayed.
+func (s *Subscriber) Gap() bool {
+	return s.gap
+}
+
+// Events returns the event channel of the subscriber.
+// The channel is closed if the subscriber is removed or the broker is closed.
+func (s *Subscriber) Events() <-chan *Event {
+	return s.events
+}
+
+type topic struct {
+	buffer []*Event // the replay ring buffer
+	head   int      // the index of the oldest event in the buffer
+	subs   map[*Subscriber]struct{}
+	active time.Time // the last publish or (un)subscribe time
+}
+
+func (t *topic) append(ev *Event, size int) {
+	if size <= 0 {
+		return
+	}
+
+	if len(t.buffer) < size {
+		t.buffer = append(t.buffer, ev)
+		return
+	}
+
+	t.buffer[t.head] = ev
+	t.head = (t.head + 1) % len(t.buffer)
+}
+
+// since returns the buffered events after the event of the id.
+// If the event of the id is not found in the buffer (unknown or expired), returns nil and false.
+func (t *topic) since(id string) ([]*Event, bool) {
+	n := len(t.buffer)
+
+	for i := n - 1; i >= 0; i-- {
+		if t.buffer[(t.head+i)%n].ID == id {
+			evs := make([]*Event, 0, n-i-1)
+			for j := i + 1; j < n; j++ {
+				evs = append(evs, t.buffer[(t.head+j)%n])
+			}
+			return evs, true
+		}
+	}
+	return nil, false
+}
+
+// Broker is a Server-Sent Events broker which fans out the published events to the subscribers of the topic.
+// Each topic keeps a bounded replay buffer, so the reconnecting client sending the Last-Event-ID header
+// receives the missed events. If the Last-Event-ID is unknown or expired from the buffer, nothing is replayed.
+//
+//	broker := sse.NewBroker()
+//	router.GET("/events/:topic", func(c *xin.Context) {
+//		broker.Serve(c.Writer, c.Request, c.Param("topic"))
+//	})
+//	broker.Publish("news", sse.Event{Event: "news", Data: "hello"})
+type Broker struct {
+	// BufferSize the size of the replay buffer of each topic, default is 100. 0 to disable replay.
+	BufferSize int
+
+	// QueueSize the size of the event queue of each subscriber, default is 64.
+	// A subscriber is removed if its queue is full.
+	QueueSize int
+
+	// Heartbeat the interval of the heartbeat comment, default is 15 seconds. 0 to disable heartbeat.
+	Heartbeat time.Duration
+
+	// Retry the reconnection time in milliseconds sent to the client on connect. 0 to not send.
+	Retry uint
+
+	// IdleTimeout the topic without subscribers is removed with its replay buffer
+	// if no event is published for the duration, default is 10 minutes. 0 to keep the topics.
+	IdleTimeout time.Duration
+
+	mu     sync.Mutex
+	seq    uint64
+	topics map[string]*topic
+	swept  time.Time
+	closed bool
+}
+
+// NewBroker create a Broker with default settings
+func NewBroker() *Broker {
+	return &Broker{
+		BufferSize:  100,
+		QueueSize:   64,
+		Heartbeat:   time.Second * 15,
+		IdleTimeout: time.Minute * 10,
+		topics:      make(map[string]*topic),
+	}
+}
+
+// topic returns the topic of the name (creates it if not exists), and removes the idle topics.
+func (b *Broker) topic(name string) *topic {
+	if b.topics == nil {
+		b.topics = make(map[string]*topic)
+	}
+
+	now := time.Now()
+	b.sweep(now)
+
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{subs: make(map[*Subscriber]struct{})}
+		b.topics[name] = t
+	}
+	t.active = now
+	return t
+}
+
+// sweep removes the topics without subscribers which are idle for the IdleTimeout.
+// The topics are checked at most once per half of the IdleTimeout.
+func (b *Broker) sweep(now time.Time) {
+	if b.IdleTimeout <= 0 || now.Sub(b.swept) < b.IdleTimeout/2 {
+		return
+	}
+	b.swept = now
+
+	for name, t := range b.topics {
+		if len(t.subs) == 0 && now.Sub(t.active) >= b.IdleTimeout {
+			delete(b.topics, name)
+		}
+	}
+}
+
+// Publish publishes the event to the subscribers of the topic, and returns the event id.
+// If the event id is empty, a sequential id of the broker is assigned,
+// so the id is not reused by the same topic after the topic is removed as idle.
+func (b *Broker) Publish(topic string, event Event) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ""
+	}
+
+	t := b.topic(topic)
+
+	b.seq++
+	if event.ID == "" {
+		event.ID = strconv.FormatUint(b.seq, 10)
+	}
+
+	ev := &event
+	t.append(ev, b.BufferSize)
+
+	for s := range t.subs {
+		select {
+		case s.events <- ev:
+		default:
+			// drop the slow subscriber, it can reconnect and replay the missed events
+			delete(t.subs, s)
+			close(s.events)
+		}
+	}
+
+	return event.ID
+}
+
+// Subscribe subscribes the topic.
+// If the lastEventID is not empty, the buffered events after the lastEventID are queued to the subscriber.
+// If the lastEventID is not found in the replay buffer, nothing is queued and the Subscriber.Gap() returns true.
+func (b *Broker) Subscribe(topic, lastEventID string) *Subscriber {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var (
+		replay []*Event
+		found  = true
+	)
+
+	t := b.topic(topic)
+	if lastEventID != "" {
+		replay, found = t.since(lastEventID)
+	}
+
+	size := max(b.QueueSize, 1)
+	s := &Subscriber{events: make(chan *Event, size+len(replay)), gap: !found}
+	for _, ev := range replay {
+		s.events <- ev
+	}
+
+	if b.closed {
+		close(s.events)
+		return s
+	}
+
+	t.subs[s] = struct{}{}
+	return s
+}
+
+// Unsubscribe removes the subscriber from the topic.
+func (b *Broker) Unsubscribe(topic string, s *Subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if t, ok := b.topics[topic]; ok {
+		if _, ok := t.subs[s]; ok {
+			delete(t.subs, s)
+			close(s.events)
+			t.active = time.Now()
+		}
+	}
+}
+
+// Subscribers returns the subscriber count of the topic.
+func (b *Broker) Subscribers(topic string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if t, ok := b.topics[topic]; ok {
+		return len(t.subs)
+	}
+	return 0
+}
+
+// Close removes all subscribers, the following Publish is ignored.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for _, t := range b.topics {
+		for s := range t.subs {
+			close(s.events)
+		}
+		t.subs = make(map[*Subscriber]struct{})
+	}
+}
+
+// Serve subscribes the topic and streams the events to the client until the client disconnects
+// (the request context is done), the subscriber is dropped or the broker is closed.
+// The Last-Event-ID request header is used to replay the missed events.
+func (b *Broker) Serve(w http.ResponseWriter, r *http.Request, topic string) error {
+	s := b.Subscribe(topic, r.Header.Get("Last-Event-ID"))
+	defer b.Unsubscribe(topic, s)
+
+	rc := http.NewResponseController(w)
+
+	header := w.Header()
+	header["Content-Type"] = contentType
+	header["Cache-Control"] = noCache
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	sw := wrapWriter(w)
+	if b.Retry > 0 {
+		writeRetry(sw, b.Retry)
+		if _, err := sw.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return err
+	}
+
+	var heartbeat <-chan time.Time
+	if b.Heartbeat > 0 {
+		ticker := time.NewTicker(b.Heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	done := r.Context().Done()
+	for {
+		select {
+		case <-done:
+			return nil
+		case ev, ok := <-s.events:
+			if !ok {
+				if b.isClosed() {
+					return ErrBrokerClosed
+				}
+				return ErrSlowSubscriber
+			}
+			if err := Encode(sw, *ev); err != nil {
+				return err
+			}
+		case <-heartbeat:
+			if _, err := sw.WriteString(":\n\n"); err != nil {
+				return err
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+func (b *Broker) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.closed
+}
@@ -0,0 +1,148 @@
+package sse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/askasoft/pango/test/assert"
+	"github.com/askasoft/pango/test/require"
+)
+
+func eventIDs(evs []*Event) []string {
+	ids := make([]string, 0, len(evs))
+	for _, ev := range evs {
+		ids = append(ids, ev.ID)
+	}
+	return ids
+}
+
+func drain(s *Subscriber) (evs []*Event) {
+	for {
+		select {
+		case ev, ok := <-s.Events():
+			if !ok {
+				return
+			}
+			evs = append(evs, ev)
+		default:
+			return
+		}
+	}
+}
+
+func TestBrokerPublish(t *testing.T) {
+	b := NewBroker()
+	b.BufferSize = 3
+
+	s1 := b.Subscribe("a", "")
+	s2 := b.Subscribe("b", "")
+	assert.Equal(t, 1, b.Subscribers("a"))
+
+	for i := 0; i < 5; i++ {
+		b.Publish("a", Event{Data: i})
+	}
+	assert.Equal(t, "x", b.Publish("a", Event{ID: "x", Data: "x"}))
+
+	assert.Equal(t, []string{"1", "2", "3", "4", "5", "x"}, eventIDs(drain(s1)))
+	assert.Equal(t, []string{}, eventIDs(drain(s2)))
+
+	// replay
+	assert.Equal(t, []string{"5", "x"}, eventIDs(drain(b.Subscribe("a", "4"))))
+	assert.Equal(t, []string{}, eventIDs(drain(b.Subscribe("a", "x"))))
+	assert.Equal(t, []string{}, eventIDs(drain(b.Subscribe("a", ""))))
+
+	// the expired or unknown id replays nothing
+	s3 := b.Subscribe("a", "1")
+	assert.True(t, s3.Gap())
+	assert.Equal(t, []string{}, eventIDs(drain(s3)))
+	assert.True(t, b.Subscribe("a", "unknown").Gap())
+	assert.False(t, b.Subscribe("a", "4").Gap())
+
+	b.Unsubscribe("a", s1)
+	_, ok := <-s1.Events()
+	assert.False(t, ok)
+}
+
+func TestBrokerIdleTopic(t *testing.T) {
+	b := NewBroker()
+	b.IdleTimeout = time.Millisecond * 10
+
+	b.Publish("a", Event{Data: "1"})
+	s := b.Subscribe("b", "")
+	b.Publish("b", Event{Data: "2"})
+
+	time.Sleep(time.Millisecond * 20)
+	b.Publish("c", Event{Data: "3"})
+
+	b.mu.Lock()
+	_, aok := b.topics["a"]
+	_, bok := b.topics["b"]
+	b.mu.Unlock()
+	assert.False(t, aok)
+	assert.True(t, bok)
+
+	// the id is not reused after the topic is removed
+	assert.Equal(t, "4", b.Publish("a", Event{Data: "4"}))
+	assert.True(t, b.Subscribe("a", "1").Gap())
+
+	b.Unsubscribe("b", s)
+}
+
+func TestBrokerSlowSubscriber(t *testing.T) {
+	b := NewBroker()
+	b.QueueSize = 2
+
+	s := b.Subscribe("a", "")
+	for i := 0; i < 3; i++ {
+		b.Publish("a", Event{Data: i})
+	}
+
+	assert.Equal(t, 0, b.Subscribers("a"))
+	assert.Equal(t, []string{"1", "2"}, eventIDs(drain(s)))
+}
+
+func TestBrokerClose(t *testing.T) {
+	b := NewBroker()
+
+	s := b.Subscribe("a", "")
+	b.Close()
+
+	_, ok := <-s.Events()
+	assert.False(t, ok)
+	assert.Equal(t, "", b.Publish("a", Event{Data: "x"}))
+}
+
+func TestBrokerServe(t *testing.T) {
+	b := NewBroker()
+	b.Retry = 1500
+	b.Heartbeat = time.Millisecond * 10
+
+	b.Publish("a", Event{Data: "1"})
+	b.Publish("a", Event{Data: "2"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	req.Header.Set("Last-Event-ID", "1")
+
+	w := httptest.NewRecorder()
+
+	done := make(chan error)
+	go func() {
+		done <- b.Serve(w, req, "a")
+	}()
+
+	time.Sleep(time.Millisecond * 30)
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Equal(t, 0, b.Subscribers("a"))
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "retry:1500\n\nid:2\ndata:2\n\n"), body)
+	assert.Contains(t, body, ":\n\n")
+}
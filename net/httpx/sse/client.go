@@ -0,0 +1,166 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// ErrStopReconnect can be returned by the event handler of the Client.Subscribe to stop the subscription.
+var ErrStopReconnect = errors.New("sse: stop reconnect")
+
+// Client is a Server-Sent Events client with automatic reconnection.
+type Client struct {
+	// URL the url of the event stream
+	URL string
+
+	// Client the http client, default is http.DefaultClient
+	Client *http.Client
+
+	// Header the extra request headers
+	Header http.Header
+
+	// LastEventID the last event id, it is sent as the Last-Event-ID header on reconnect.
+	LastEventID string
+
+	// Retry the reconnection time, it is updated by the `retry:` field of the event stream.
+	// Default is 3 seconds.
+	Retry time.Duration
+
+	// MaxRetries the maximum count of the continuous reconnection failures, 0 means no limit.
+	MaxRetries int
+
+	// OnError the callback of the connection error before reconnect.
+	OnError func(err error)
+}
+
+// NewClient create a Client for the url
+func NewClient(url string) *Client {
+	return &Client{
+		URL:   url,
+		Retry: time.Second * 3,
+	}
+}
+
+// Subscribe connects to the event stream and calls the handler for each event.
+// The client reconnects automatically when the connection is lost, until the context is done,
+// the server responds with the status 204 No Content, or the handler returns an error.
+// If the handler returns ErrStopReconnect, Subscribe returns nil.
+func (c *Client) Subscribe(ctx context.Context, handler func(*Event) error) error {
+	retries := 0
+	for {
+		received, err := c.connect(ctx, handler)
+		if err == nil || errors.Is(err, ErrStopReconnect) {
+			return nil
+		}
+
+		var fe *fatalError
+		if errors.As(err, &fe) {
+			return fe.err
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if received {
+			retries = 0
+		}
+		retries++
+		if c.MaxRetries > 0 && retries > c.MaxRetries {
+			return err
+		}
+
+		if c.OnError != nil {
+			c.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.Retry):
+		}
+	}
+}
+
+// fatalError is the error which stops the reconnection
+type fatalError struct {
+	err error
+}
+
+func (fe *fatalError) Error() string {
+	return fe.err.Error()
+}
+
+// connect connects the event stream, returns nil if the server responds 204 No Content.
+func (c *Client) connect(ctx context.Context, handler func(*Event) error) (received bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return false, &fatalError{err}
+	}
+
+	for k, vs := range c.Header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Accept", ContentType)
+	req.Header.Set("Cache-Control", "no-cache")
+	if c.LastEventID != "" {
+		req.Header.Set("Last-Event-ID", c.LastEventID)
+	}
+
+	hc := c.Client
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	res, err := hc.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent:
+		return false, nil
+	default:
+		return false, &fatalError{fmt.Errorf("sse: unexpected response status %s", res.Status)}
+	}
+
+	if mt, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mt != ContentType {
+		return false, &fatalError{fmt.Errorf("sse: unexpected response content type %q", res.Header.Get("Content-Type"))}
+	}
+
+	dec := NewDecoder(res.Body)
+	defer func() {
+		if dec.Retry > 0 {
+			c.Retry = time.Duration(dec.Retry) * time.Millisecond
+		}
+	}()
+
+	for {
+		ev, err := dec.Decode()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return received, err
+		}
+
+		received = true
+		if ev.ID != "" {
+			c.LastEventID = ev.ID
+		}
+
+		if err := handler(ev); err != nil {
+			if errors.Is(err, ErrStopReconnect) {
+				return received, err
+			}
+			return received, &fatalError{err}
+		}
+	}
+}
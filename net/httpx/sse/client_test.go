@@ -0,0 +1,133 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/askasoft/pango/test/assert"
+	"github.com/askasoft/pango/test/require"
+)
+
+func TestClientReconnect(t *testing.T) {
+	b := NewBroker()
+	b.Retry = 10
+
+	var mu sync.Mutex
+	var lastIDs []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+		n := len(lastIDs)
+		mu.Unlock()
+
+		if n > 2 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		// close the stream after the first event
+		s := b.Subscribe("a", r.Header.Get("Last-Event-ID"))
+		defer b.Unsubscribe("a", s)
+
+		w.Header().Set("Content-Type", ContentType)
+		_ = Encode(w, Event{Retry: b.Retry, Data: ""})
+		ev := <-s.Events()
+		_ = Encode(w, *ev)
+	}))
+	defer ts.Close()
+
+	go func() {
+		for i := 0; i < 2; i++ {
+			for b.Subscribers("a") == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			b.Publish("a", Event{Event: "tick", Data: i})
+			for b.Subscribers("a") != 0 {
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+
+	c := NewClient(ts.URL)
+
+	var evs []*Event
+	err := c.Subscribe(context.Background(), func(ev *Event) error {
+		evs = append(evs, ev)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"", "1", "2"}, lastIDs)
+	assert.Equal(t, time.Millisecond*10, c.Retry)
+	assert.Equal(t, "2", c.LastEventID)
+	if assert.Len(t, evs, 2) {
+		assert.Equal(t, "tick", evs[0].Event)
+		assert.Equal(t, "0", evs[0].Data)
+		assert.Equal(t, "1", evs[1].Data)
+	}
+}
+
+func TestClientStop(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		_ = Encode(w, Event{ID: "1", Data: "a"})
+		_ = Encode(w, Event{ID: "2", Data: "b"})
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL)
+
+	n := 0
+	err := c.Subscribe(context.Background(), func(ev *Event) error {
+		n++
+		return ErrStopReconnect
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	errFail := errors.New("fail")
+	err = c.Subscribe(context.Background(), func(ev *Event) error {
+		return errFail
+	})
+	assert.Equal(t, errFail, err)
+}
+
+func TestClientStatusError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	err := NewClient(ts.URL).Subscribe(context.Background(), func(ev *Event) error {
+		return nil
+	})
+	assert.EqualError(t, err, "sse: unexpected response status 404 Not Found")
+}
+
+func TestClientMaxRetries(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL)
+	c.Retry = time.Millisecond
+	c.MaxRetries = 2
+
+	n := 0
+	c.OnError = func(err error) {
+		n++
+	}
+
+	err := c.Subscribe(context.Background(), func(ev *Event) error {
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 2, n)
+}
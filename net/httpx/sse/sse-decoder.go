@@ -1,21 +1,114 @@
 package sse
 
 import (
+	"bufio"
 	"bytes"
 	"io"
+	"strconv"
+
+	"github.com/askasoft/pango/str"
 )
 
-type decoder struct {
-	events []Event
+// Decoder reads and decodes the events from an input stream.
+type Decoder struct {
+	// Retry the last received reconnection time in milliseconds
+	Retry uint
+
+	r      *bufio.Reader
+	line   []byte
+	skipLF bool
+	event  Event
+	data   bytes.Buffer
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
 }
 
 // Decode decode events
 func Decode(r io.Reader) ([]Event, error) {
-	var dec decoder
-	return dec.decode(r)
+	var events []Event
+
+	d := NewDecoder(r)
+	for {
+		ev, err := d.Decode()
+		if err != nil {
+			if err == io.EOF {
+				return events, nil
+			}
+			return nil, err
+		}
+		events = append(events, *ev)
+	}
+}
+
+// Decode reads the next dispatched event from the input stream.
+// Returns io.EOF if no more event is available.
+func (d *Decoder) Decode() (*Event, error) {
+	for {
+		line, err := d.readLine()
+		if err != nil {
+			if err == io.EOF {
+				// Once the end of the file is reached, the user agent must dispatch the event one final time.
+				if ev := d.dispatchEvent(); ev != nil {
+					return ev, nil
+				}
+			}
+			return nil, err
+		}
+
+		if len(line) == 0 {
+			// If the line is empty (a blank line). Dispatch the event.
+			if ev := d.dispatchEvent(); ev != nil {
+				return ev, nil
+			}
+			continue
+		}
+
+		d.processLine(line)
+	}
+}
+
+// readLine reads a line which is terminated by either a U+000D CARRIAGE RETURN U+000A LINE FEED (CRLF) character pair,
+// a single U+000A LINE FEED (LF) character, or a single U+000D CARRIAGE RETURN (CR) character.
+func (d *Decoder) readLine() ([]byte, error) {
+	d.line = d.line[:0]
+	for {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(d.line) > 0 {
+				return d.line, nil
+			}
+			return nil, err
+		}
+
+		if d.skipLF {
+			d.skipLF = false
+			if b == '\n' {
+				continue
+			}
+		}
+
+		switch b {
+		case '\n':
+			return d.line, nil
+		case '\r':
+			d.skipLF = true
+			return d.line, nil
+		default:
+			d.line = append(d.line, b)
+		}
+	}
 }
 
-func (d *decoder) dispatchEvent(event Event, data string) {
+func (d *Decoder) dispatchEvent() *Event {
+	event, data := d.event, d.data.String()
+
+	// reset current event and data buffer
+	d.event = Event{}
+	d.data.Reset()
+
 	dataLength := len(data)
 	if dataLength > 0 {
 		//If the data buffer's last character is a U+000A LINE FEED (LF) character, then remove the last character from the data buffer.
@@ -23,91 +116,66 @@ func (d *decoder) dispatchEvent(event Event, data string) {
 		dataLength--
 	}
 	if dataLength == 0 && event.Event == "" {
-		return
+		return nil
 	}
 	if event.Event == "" {
 		event.Event = "message"
 	}
 	event.Data = data
-	d.events = append(d.events, event)
+	return &event
 }
 
-func (d *decoder) decode(r io.Reader) ([]Event, error) {
-	buf, err := io.ReadAll(r)
-	if err != nil {
-		return nil, err
+func (d *Decoder) processLine(line []byte) {
+	if line[0] == byte(':') {
+		// If the line starts with a U+003A COLON character (:), ignore the line.
+		return
 	}
 
-	var currentEvent Event
-
-	dataBuffer := new(bytes.Buffer)
-	// TODO (and unit tests)
-	// Lines must be separated by either a U+000D CARRIAGE RETURN U+000A LINE FEED (CRLF) character pair,
-	// a single U+000A LINE FEED (LF) character,
-	// or a single U+000D CARRIAGE RETURN (CR) character.
-	lines := bytes.Split(buf, []byte{'\n'})
-	for _, line := range lines {
-		if len(line) == 0 {
-			// If the line is empty (a blank line). Dispatch the event.
-			d.dispatchEvent(currentEvent, dataBuffer.String())
-
-			// reset current event and data buffer
-			currentEvent = Event{}
-			dataBuffer.Reset()
-			continue
-		}
-		if line[0] == byte(':') {
-			// If the line starts with a U+003A COLON character (:), ignore the line.
-			continue
+	var field, value []byte
+	colonIndex := bytes.IndexByte(line, ':')
+	if colonIndex != -1 {
+		// If the line contains a U+003A COLON character character (:)
+		// Collect the characters on the line before the first U+003A COLON character (:),
+		// and let field be that string.
+		field = line[:colonIndex]
+		// Collect the characters on the line after the first U+003A COLON character (:),
+		// and let value be that string.
+		value = line[colonIndex+1:]
+		// If value starts with a single U+0020 SPACE character, remove it from value.
+		if len(value) > 0 && value[0] == ' ' {
+			value = value[1:]
 		}
+	} else {
+		// Otherwise, the string is not empty but does not contain a U+003A COLON character character (:)
+		// Use the whole line as the field name, and the empty string as the field value.
+		field = line
+		value = []byte{}
+	}
 
-		var field, value []byte
-		colonIndex := bytes.IndexRune(line, ':')
-		if colonIndex != -1 {
-			// If the line contains a U+003A COLON character character (:)
-			// Collect the characters on the line before the first U+003A COLON character (:),
-			// and let field be that string.
-			field = line[:colonIndex]
-			// Collect the characters on the line after the first U+003A COLON character (:),
-			// and let value be that string.
-			value = line[colonIndex+1:]
-			// If value starts with a single U+0020 SPACE character, remove it from value.
-			if len(value) > 0 && value[0] == ' ' {
-				value = value[1:]
-			}
-		} else {
-			// Otherwise, the string is not empty but does not contain a U+003A COLON character character (:)
-			// Use the whole line as the field name, and the empty string as the field value.
-			field = line
-			value = []byte{}
-		}
-		// The steps to process the field given a field name and a field value depend on the field name,
-		// as given in the following list. Field names must be compared literally,
-		// with no case folding performed.
-		switch string(field) {
-		case "event":
-			// Set the event name buffer to field value.
-			currentEvent.Event = string(value)
-		case "id":
-			// Set the event stream's last event ID to the field value.
-			currentEvent.ID = string(value)
-		case "retry":
-			// If the field value consists of only characters in the range U+0030 DIGIT ZERO (0) to U+0039 DIGIT NINE (9),
-			// then interpret the field value as an integer in base ten, and set the event stream's reconnection time to that integer.
-			// Otherwise, ignore the field.
-			currentEvent.ID = string(value)
-		case "data":
-			// Append the field value to the data buffer,
-			dataBuffer.Write(value)
-			// then append a single U+000A LINE FEED (LF) character to the data buffer.
-			dataBuffer.WriteString("\n")
-		default:
-			//Otherwise. The field is ignored.
-			continue
+	// The steps to process the field given a field name and a field value depend on the field name,
+	// as given in the following list. Field names must be compared literally,
+	// with no case folding performed.
+	switch str.UnsafeString(field) {
+	case "event":
+		// Set the event name buffer to field value.
+		d.event.Event = string(value)
+	case "id":
+		// Set the event stream's last event ID to the field value.
+		d.event.ID = string(value)
+	case "retry":
+		// If the field value consists of only characters in the range U+0030 DIGIT ZERO (0) to U+0039 DIGIT NINE (9),
+		// then interpret the field value as an integer in base ten, and set the event stream's reconnection time to that integer.
+		// Otherwise, ignore the field.
+		if n, err := strconv.ParseUint(string(value), 10, 0); err == nil && str.IsNumber(string(value)) {
+			d.event.Retry = uint(n)
+			d.Retry = uint(n)
 		}
+	case "data":
+		// Append the field value to the data buffer,
+		d.data.Write(value)
+		// then append a single U+000A LINE FEED (LF) character to the data buffer.
+		d.data.WriteByte('\n')
+	default:
+		//Otherwise. The field is ignored.
 	}
-	// Once the end of the file is reached, the user agent must dispatch the event one final time.
-	d.dispatchEvent(currentEvent, dataBuffer.String())
-
-	return d.events, nil
 }
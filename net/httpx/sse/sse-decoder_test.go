@@ -110,3 +110,17 @@ data:
 	assert.NoError(t, err)
 	assert.Len(t, events, 1)
 }
+
+func TestDecodeLineEndings(t *testing.T) {
+	events, err := Decode(bytes.NewBufferString("id:1\r\ndata:a\r\n\r\nid:2\rdata:b\r\rretry:30\nretry:x\ndata:c\n\n"))
+
+	assert.NoError(t, err)
+	if assert.Len(t, events, 3) {
+		assert.Equal(t, "1", events[0].ID)
+		assert.Equal(t, "a", events[0].Data)
+		assert.Equal(t, "2", events[1].ID)
+		assert.Equal(t, "b", events[1].Data)
+		assert.Equal(t, uint(30), events[2].Retry)
+		assert.Equal(t, "c", events[2].Data)
+	}
+}
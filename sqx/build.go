@@ -445,20 +445,59 @@ func (b *Builder) buildSelect() string {
 		sb.WriteString(o)
 	}
 
-	if b.limit > 0 {
-		sb.WriteString(" LIMIT ")
-		sb.WriteString(num.Itoa(b.limit))
-	}
-	if b.offset > 0 {
-		sb.WriteString(" OFFSET ")
-		sb.WriteString(num.Itoa(b.offset))
-	}
+	b.appendLimit(sb)
+
 	if b.forUpdate {
 		sb.WriteString(" FOR UPDATE")
 	}
 	return sb.String()
 }
 
+// appendLimit append the LIMIT/OFFSET clause of the dialect.
+// SQL Server and Oracle use "OFFSET n ROWS FETCH NEXT m ROWS ONLY",
+// a dummy "ORDER BY (SELECT NULL)" is added for SQL Server which requires ORDER BY.
+// MySQL and SQLite require LIMIT with OFFSET.
+func (b *Builder) appendLimit(sb *strings.Builder) {
+	if b.limit <= 0 && b.offset <= 0 {
+		return
+	}
+
+	switch b.Binder {
+	case BindAt, BindColon:
+		if b.Binder == BindAt && len(b.orders) == 0 {
+			sb.WriteString(" ORDER BY (SELECT NULL)")
+		}
+		sb.WriteString(" OFFSET ")
+		sb.WriteString(num.Itoa(b.offset))
+		sb.WriteString(" ROWS")
+		if b.limit > 0 {
+			sb.WriteString(" FETCH NEXT ")
+			sb.WriteString(num.Itoa(b.limit))
+			sb.WriteString(" ROWS ONLY")
+		}
+	case BindQuestion:
+		sb.WriteString(" LIMIT ")
+		if b.limit > 0 {
+			sb.WriteString(num.Itoa(b.limit))
+		} else {
+			sb.WriteString("9223372036854775807")
+		}
+		if b.offset > 0 {
+			sb.WriteString(" OFFSET ")
+			sb.WriteString(num.Itoa(b.offset))
+		}
+	default:
+		if b.limit > 0 {
+			sb.WriteString(" LIMIT ")
+			sb.WriteString(num.Itoa(b.limit))
+		}
+		if b.offset > 0 {
+			sb.WriteString(" OFFSET ")
+			sb.WriteString(num.Itoa(b.offset))
+		}
+	}
+}
+
 func (b *Builder) buildUpdate() string {
 	sb := &strings.Builder{}
 
@@ -0,0 +1,308 @@
+package sqx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/askasoft/pango/asg"
+)
+
+// MaxParams returns the maximum number of the placeholder parameters of a statement.
+func (b Binder) MaxParams() int {
+	switch b {
+	case BindDollar, BindColon:
+		return 65535
+	case BindAt:
+		return 2100
+	default:
+		// SQLite (SQLITE_MAX_VARIABLE_NUMBER) is smaller than MySQL (65535)
+		return 32766
+	}
+}
+
+// Upsert mode of the BulkInserter
+const (
+	UpsertNone           = iota
+	UpsertOnConflict     // INSERT ... ON CONFLICT (keys) DO UPDATE SET c = EXCLUDED.c (PostgreSQL, SQLite)
+	UpsertOnDuplicateKey // INSERT ... ON DUPLICATE KEY UPDATE c = VALUES(c) (MySQL)
+)
+
+// BulkInserter builds the multi-row "INSERT ... VALUES (...), (...)" statements.
+// The rows of a statement are limited by the BatchSize and the placeholder limit of the Binder.
+//
+//	bi := &sqx.BulkInserter{Binder: sqx.BindDollar, Quoter: sqx.QuoteDefault, Table: "users", Columns: []string{"id", "name"}}
+//	n, err := bi.Exec(ctx, db, rows)
+type BulkInserter struct {
+	Binder  Binder
+	Quoter  Quoter
+	Table   string
+	Columns []string
+
+	// BatchSize the maximum rows of a statement, 0 means only limited by MaxParams.
+	BatchSize int
+
+	// MaxParams the maximum placeholder parameters of a statement, use Binder.MaxParams() if zero.
+	MaxParams int
+
+	// Upsert the upsert mode
+	Upsert int
+
+	// Keys the conflict key columns for UpsertOnConflict
+	Keys []string
+
+	// Updates the columns to update on conflict, default is all columns except the Keys.
+	// If no column to update, the conflicted rows are ignored.
+	Updates []string
+}
+
+// NewBulkInserter create a BulkInserter with the binder and quoter of the driver
+func NewBulkInserter(driverName, table string, columns ...string) *BulkInserter {
+	return &BulkInserter{
+		Binder:  GetBinder(driverName),
+		Quoter:  GetQuoter(driverName),
+		Table:   table,
+		Columns: columns,
+	}
+}
+
+// RowsPerStatement returns the maximum rows of a statement
+func (bi *BulkInserter) RowsPerStatement() int {
+	maxParams := bi.MaxParams
+	if maxParams <= 0 {
+		maxParams = bi.Binder.MaxParams()
+	}
+
+	n := max(maxParams/max(len(bi.Columns), 1), 1)
+	if bi.BatchSize > 0 {
+		n = min(n, bi.BatchSize)
+	}
+	return n
+}
+
+// SQL returns the insert statement for n rows
+func (bi *BulkInserter) SQL(n int) string {
+	sb := &strings.Builder{}
+
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(bi.Quoter.Quote(bi.Table))
+	sb.WriteString(" (")
+	for i, c := range bi.Columns {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(bi.Quoter.Quote(c))
+	}
+	sb.WriteString(") VALUES ")
+
+	p := 0
+	for r := 0; r < n; r++ {
+		if r > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('(')
+		for i := range bi.Columns {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			p++
+			sb.WriteString(bi.Binder.Placeholder(p))
+		}
+		sb.WriteByte(')')
+	}
+
+	bi.appendUpsert(sb)
+
+	return sb.String()
+}
+
+func (bi *BulkInserter) updates() []string {
+	if len(bi.Updates) > 0 {
+		return bi.Updates
+	}
+
+	var cs []string
+	for _, c := range bi.Columns {
+		if !asg.Contains(bi.Keys, c) {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+func (bi *BulkInserter) appendUpsert(sb *strings.Builder) {
+	switch bi.Upsert {
+	case UpsertOnConflict:
+		sb.WriteString(" ON CONFLICT (")
+		for i, k := range bi.Keys {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(bi.Quoter.Quote(k))
+		}
+		sb.WriteByte(')')
+
+		ucs := bi.updates()
+		if len(ucs) == 0 {
+			sb.WriteString(" DO NOTHING")
+			return
+		}
+
+		sb.WriteString(" DO UPDATE SET ")
+		for i, c := range ucs {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			c = bi.Quoter.Quote(c)
+			sb.WriteString(c)
+			sb.WriteString(" = EXCLUDED.")
+			sb.WriteString(c)
+		}
+	case UpsertOnDuplicateKey:
+		sb.WriteString(" ON DUPLICATE KEY UPDATE ")
+
+		ucs := bi.updates()
+		if len(ucs) == 0 {
+			// no-op update to ignore the duplicate row
+			c := bi.Quoter.Quote(bi.Columns[0])
+			sb.WriteString(c)
+			sb.WriteString(" = ")
+			sb.WriteString(c)
+			return
+		}
+
+		for i, c := range ucs {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			c = bi.Quoter.Quote(c)
+			sb.WriteString(c)
+			sb.WriteString(" = VALUES(")
+			sb.WriteString(c)
+			sb.WriteByte(')')
+		}
+	}
+}
+
+func (bi *BulkInserter) validate() error {
+	if bi.Table == "" || len(bi.Columns) == 0 {
+		return errors.New("sqx: bulk insert table or columns is empty")
+	}
+	if bi.Upsert == UpsertOnConflict && len(bi.Keys) == 0 {
+		return errors.New("sqx: bulk upsert keys is empty")
+	}
+	return nil
+}
+
+// Exec inserts the rows by the multi-row insert statements, returns the affected row count.
+// Each row must have the same length as the Columns.
+func (bi *BulkInserter) Exec(ctx context.Context, e ContextExecer, rows [][]any) (int64, error) {
+	if err := bi.validate(); err != nil {
+		return 0, err
+	}
+
+	var total int64
+
+	size := bi.RowsPerStatement()
+	for off := 0; len(rows) > 0; off += size {
+		n := min(size, len(rows))
+
+		args := make([]any, 0, n*len(bi.Columns))
+		for i, row := range rows[:n] {
+			if len(row) != len(bi.Columns) {
+				return total, fmt.Errorf("sqx: bulk insert row #%d has %d values, want %d", off+i, len(row), len(bi.Columns))
+			}
+			args = append(args, row...)
+		}
+
+		r, err := e.ExecContext(ctx, bi.SQL(n), args...)
+		if err != nil {
+			return total, err
+		}
+
+		cnt, err := r.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += cnt
+
+		rows = rows[n:]
+	}
+
+	return total, nil
+}
+
+// Stream begins a transaction, calls fn with a BulkWriter which flushes every n rows,
+// and commits the transaction if fn returns nil, otherwise rollbacks the transaction.
+// Returns the affected row count.
+func (bi *BulkInserter) Stream(ctx context.Context, db BeginTxer, n int, fn func(bw *BulkWriter) error) (cnt int64, err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	bw := bi.Writer(ctx, tx, n)
+	if err = fn(bw); err != nil {
+		return 0, err
+	}
+	if err = bw.Flush(); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return bw.Count(), nil
+}
+
+// Writer returns a BulkWriter which flushes every n rows (RowsPerStatement() if n <= 0).
+func (bi *BulkInserter) Writer(ctx context.Context, e ContextExecer, n int) *BulkWriter {
+	if n <= 0 {
+		n = bi.RowsPerStatement()
+	}
+	return &BulkWriter{ctx: ctx, bi: bi, e: e, size: n}
+}
+
+// BulkWriter buffers the rows and flushes them by the BulkInserter
+type BulkWriter struct {
+	ctx   context.Context
+	bi    *BulkInserter
+	e     ContextExecer
+	size  int
+	rows  [][]any
+	count int64
+}
+
+// Write buffers a copy of the row, and flushes the buffered rows if the buffer is full
+func (bw *BulkWriter) Write(row ...any) error {
+	bw.rows = append(bw.rows, slices.Clone(row))
+	if len(bw.rows) >= bw.size {
+		return bw.Flush()
+	}
+	return nil
+}
+
+// Flush inserts the buffered rows
+func (bw *BulkWriter) Flush() error {
+	if len(bw.rows) == 0 {
+		return nil
+	}
+
+	cnt, err := bw.bi.Exec(bw.ctx, bw.e, bw.rows)
+	bw.count += cnt
+	bw.rows = bw.rows[:0]
+	return err
+}
+
+// Count returns the affected row count of the flushed rows
+func (bw *BulkWriter) Count() int64 {
+	return bw.count
+}
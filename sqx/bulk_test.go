@@ -0,0 +1,187 @@
+package sqx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestBulkInserterSQL(t *testing.T) {
+	cs := []struct {
+		w  string
+		bi *BulkInserter
+	}{
+		{
+			`INSERT INTO "users" ("id", "name") VALUES ($1, $2), ($3, $4)`,
+			&BulkInserter{Binder: BindDollar, Quoter: QuoteDefault, Table: "users", Columns: []string{"id", "name"}},
+		},
+		{
+			`INSERT INTO "users" ("id", "name") VALUES ($1, $2), ($3, $4) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"`,
+			&BulkInserter{Binder: BindDollar, Quoter: QuoteDefault, Table: "users", Columns: []string{"id", "name"}, Upsert: UpsertOnConflict, Keys: []string{"id"}},
+		},
+		{
+			`INSERT INTO "users" ("id", "name") VALUES (?, ?), (?, ?) ON CONFLICT ("id", "name") DO NOTHING`,
+			&BulkInserter{Binder: BindQuestion, Quoter: QuoteDefault, Table: "users", Columns: []string{"id", "name"}, Upsert: UpsertOnConflict, Keys: []string{"id", "name"}},
+		},
+		{
+			"INSERT INTO `users` (`id`, `name`) VALUES (?, ?), (?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)",
+			&BulkInserter{Binder: BindQuestion, Quoter: QuoteBackticks, Table: "users", Columns: []string{"id", "name"}, Upsert: UpsertOnDuplicateKey, Keys: []string{"id"}},
+		},
+		{
+			"INSERT INTO `users` (`id`, `name`) VALUES (?, ?), (?, ?) ON DUPLICATE KEY UPDATE `id` = `id`",
+			&BulkInserter{Binder: BindQuestion, Quoter: QuoteBackticks, Table: "users", Columns: []string{"id", "name"}, Upsert: UpsertOnDuplicateKey, Keys: []string{"id", "name"}},
+		},
+	}
+
+	for i, c := range cs {
+		if a := c.bi.SQL(2); a != c.w {
+			t.Errorf("#%d\ngot : %s\nwant: %s", i, a, c.w)
+		}
+	}
+}
+
+func TestBulkInserterRowsPerStatement(t *testing.T) {
+	cs := []struct {
+		w  int
+		bi *BulkInserter
+	}{
+		{21845, &BulkInserter{Binder: BindDollar, Columns: []string{"a", "b", "c"}}},
+		{700, &BulkInserter{Binder: BindAt, Columns: []string{"a", "b", "c"}}},
+		{100, &BulkInserter{Binder: BindAt, Columns: []string{"a", "b", "c"}, BatchSize: 100}},
+		{3, &BulkInserter{Binder: BindQuestion, Columns: []string{"a", "b", "c"}, MaxParams: 10}},
+		{1, &BulkInserter{Binder: BindQuestion, Columns: []string{"a", "b", "c"}, MaxParams: 2}},
+	}
+
+	for i, c := range cs {
+		if a := c.bi.RowsPerStatement(); a != c.w {
+			t.Errorf("#%d RowsPerStatement() = %d, want %d", i, a, c.w)
+		}
+	}
+}
+
+func openBulkDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "bulk.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)"); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func countRows(t *testing.T, db *sql.DB, query string) (n int) {
+	if err := db.QueryRow(query).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestBulkInserterExec(t *testing.T) {
+	ctx := context.Background()
+	db := openBulkDB(t)
+
+	bi := NewBulkInserter("sqlite3", "users", "id", "name")
+	bi.MaxParams = 5
+
+	var rows [][]any
+	for i := 1; i <= 10; i++ {
+		rows = append(rows, []any{i, "u"})
+	}
+
+	n, err := bi.Exec(ctx, db, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 10 {
+		t.Errorf("Exec() = %d, want %d", n, 10)
+	}
+
+	bi.Upsert = UpsertOnConflict
+	bi.Keys = []string{"id"}
+	if _, err := bi.Exec(ctx, db, [][]any{{1, "x"}, {2, "x"}, {11, "x"}}); err != nil {
+		t.Fatal(err)
+	}
+	if a := countRows(t, db, "SELECT COUNT(*) FROM users WHERE name = 'x'"); a != 3 {
+		t.Errorf("upserted rows = %d, want %d", a, 3)
+	}
+	if a := countRows(t, db, "SELECT COUNT(*) FROM users"); a != 11 {
+		t.Errorf("total rows = %d, want %d", a, 11)
+	}
+
+	if _, err := bi.Exec(ctx, db, [][]any{{1}}); err == nil {
+		t.Error("Exec() with invalid row should return error")
+	}
+
+	// the row number of the error is the index of the rows, not of the statement
+	_, err = bi.Exec(ctx, db, [][]any{{21, "y"}, {22, "y"}, {23, "y"}, {24}})
+	if err == nil || !strings.Contains(err.Error(), "row #3 ") {
+		t.Errorf("Exec() = %v, want row #3 error", err)
+	}
+}
+
+func TestBulkInserterStream(t *testing.T) {
+	ctx := context.Background()
+	db := openBulkDB(t)
+
+	bi := NewBulkInserter("sqlite3", "users", "id", "name")
+
+	n, err := bi.Stream(ctx, db, 3, func(bw *BulkWriter) error {
+		for i := 1; i <= 10; i++ {
+			if err := bw.Write(i, "u"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 10 {
+		t.Errorf("Stream() = %d, want %d", n, 10)
+	}
+
+	// the written row slice can be reused by the caller
+	_, err = bi.Stream(ctx, db, 3, func(bw *BulkWriter) error {
+		row := make([]any, 2)
+		for i := 101; i <= 105; i++ {
+			row[0], row[1] = i, "r"
+			if err := bw.Write(row...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := countRows(t, db, "SELECT COUNT(DISTINCT id) FROM users WHERE name = 'r'"); a != 5 {
+		t.Errorf("distinct reused rows = %d, want %d", a, 5)
+	}
+	if _, err := db.Exec("DELETE FROM users WHERE name = 'r'"); err != nil {
+		t.Fatal(err)
+	}
+
+	errAbort := errors.New("abort")
+	_, err = bi.Stream(ctx, db, 3, func(bw *BulkWriter) error {
+		for i := 11; i <= 20; i++ {
+			if err := bw.Write(i, "u"); err != nil {
+				return err
+			}
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("Stream() = %v, want %v", err, errAbort)
+	}
+	if a := countRows(t, db, "SELECT COUNT(*) FROM users"); a != 10 {
+		t.Errorf("rows after rollback = %d, want %d", a, 10)
+	}
+}
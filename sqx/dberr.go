@@ -0,0 +1,207 @@
+package sqx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrorKind the portable database error kind
+type ErrorKind int
+
+const (
+	ErrorUnknown ErrorKind = iota
+	ErrorUniqueViolation
+	ErrorForeignKeyViolation
+	ErrorNotNullViolation
+	ErrorCheckViolation
+	ErrorDeadlock
+	ErrorSerializationFailure
+	ErrorLockTimeout
+	ErrorConnectionLost
+	ErrorQueryCanceled
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorUniqueViolation:
+		return "unique violation"
+	case ErrorForeignKeyViolation:
+		return "foreign key violation"
+	case ErrorNotNullViolation:
+		return "not null violation"
+	case ErrorCheckViolation:
+		return "check violation"
+	case ErrorDeadlock:
+		return "deadlock"
+	case ErrorSerializationFailure:
+		return "serialization failure"
+	case ErrorLockTimeout:
+		return "lock timeout"
+	case ErrorConnectionLost:
+		return "connection lost"
+	case ErrorQueryCanceled:
+		return "query canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// The sentinel errors of the ErrorKind for errors.Is().
+//
+//	errors.Is(err, sqx.ErrUniqueViolation)
+var (
+	ErrUniqueViolation      = errors.New("sqx: unique violation")
+	ErrForeignKeyViolation  = errors.New("sqx: foreign key violation")
+	ErrNotNullViolation     = errors.New("sqx: not null violation")
+	ErrCheckViolation       = errors.New("sqx: check violation")
+	ErrDeadlock             = errors.New("sqx: deadlock")
+	ErrSerializationFailure = errors.New("sqx: serialization failure")
+	ErrLockTimeout          = errors.New("sqx: lock timeout")
+	ErrConnectionLost       = errors.New("sqx: connection lost")
+	ErrQueryCanceled        = errors.New("sqx: query canceled")
+)
+
+var kindErrors = map[ErrorKind]error{
+	ErrorUniqueViolation:      ErrUniqueViolation,
+	ErrorForeignKeyViolation:  ErrForeignKeyViolation,
+	ErrorNotNullViolation:     ErrNotNullViolation,
+	ErrorCheckViolation:       ErrCheckViolation,
+	ErrorDeadlock:             ErrDeadlock,
+	ErrorSerializationFailure: ErrSerializationFailure,
+	ErrorLockTimeout:          ErrLockTimeout,
+	ErrorConnectionLost:       ErrConnectionLost,
+	ErrorQueryCanceled:        ErrQueryCanceled,
+}
+
+// DBError a classified database error.
+// The Constraint, Table and Column are set if the driver reports them.
+type DBError struct {
+	Kind       ErrorKind
+	Code       string // the driver specific error code
+	Constraint string
+	Table      string
+	Column     string
+	Err        error
+}
+
+func (e *DBError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DBError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if the target is the sentinel error of the kind (e.g. ErrUniqueViolation).
+func (e *DBError) Is(target error) bool {
+	ke, ok := kindErrors[e.Kind]
+	return ok && target == ke
+}
+
+// ErrorClassifier classifies the driver error, returns nil if the error is not recognized.
+type ErrorClassifier func(err error) *DBError
+
+var (
+	classifierMu sync.RWMutex
+	classifiers  []ErrorClassifier
+)
+
+// RegisterErrorClassifier registers a driver error classifier.
+// The ClassifyError of the driver support packages (pgsqlx/pgxv5, pgsqlx/libpq, mysqlx, sqlitex)
+// is registered by this function when the package is imported.
+func RegisterErrorClassifier(ec ErrorClassifier) {
+	classifierMu.Lock()
+	classifiers = append(classifiers, ec)
+	classifierMu.Unlock()
+}
+
+// ClassifyError classifies the error by the registered classifiers and the standard errors.
+// Returns nil if err is nil, returns a DBError with ErrorUnknown kind if the error is not recognized.
+// If err is (or wraps) a *DBError, it is returned.
+func ClassifyError(err error) *DBError {
+	if err == nil {
+		return nil
+	}
+
+	var de *DBError
+	if errors.As(err, &de) {
+		return de
+	}
+
+	classifierMu.RLock()
+	ecs := classifiers
+	classifierMu.RUnlock()
+
+	for _, ec := range ecs {
+		if de := ec(err); de != nil {
+			return de
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return &DBError{Kind: ErrorQueryCanceled, Err: err}
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone), errors.Is(err, io.ErrUnexpectedEOF):
+		return &DBError{Kind: ErrorConnectionLost, Err: err}
+	}
+	return &DBError{Kind: ErrorUnknown, Err: err}
+}
+
+// ErrorKindOf returns the ErrorKind of the error
+func ErrorKindOf(err error) ErrorKind {
+	if de := ClassifyError(err); de != nil {
+		return de.Kind
+	}
+	return ErrorUnknown
+}
+
+func IsUniqueViolation(err error) bool {
+	return ErrorKindOf(err) == ErrorUniqueViolation
+}
+
+func IsForeignKeyViolation(err error) bool {
+	return ErrorKindOf(err) == ErrorForeignKeyViolation
+}
+
+func IsNotNullViolation(err error) bool {
+	return ErrorKindOf(err) == ErrorNotNullViolation
+}
+
+func IsCheckViolation(err error) bool {
+	return ErrorKindOf(err) == ErrorCheckViolation
+}
+
+func IsDeadlock(err error) bool {
+	return ErrorKindOf(err) == ErrorDeadlock
+}
+
+func IsSerializationFailure(err error) bool {
+	return ErrorKindOf(err) == ErrorSerializationFailure
+}
+
+func IsLockTimeout(err error) bool {
+	return ErrorKindOf(err) == ErrorLockTimeout
+}
+
+func IsConnectionLost(err error) bool {
+	return ErrorKindOf(err) == ErrorConnectionLost
+}
+
+func IsQueryCanceled(err error) bool {
+	return ErrorKindOf(err) == ErrorQueryCanceled
+}
+
+// IsRetryable returns true if the transaction failed with a deadlock, serialization failure or lock timeout,
+// and can be retried.
+func IsRetryable(err error) bool {
+	switch ErrorKindOf(err) {
+	case ErrorDeadlock, ErrorSerializationFailure, ErrorLockTimeout:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,60 @@
+package sqx
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	errDriver := errors.New("driver: duplicate")
+
+	RegisterErrorClassifier(func(err error) *DBError {
+		if errors.Is(err, errDriver) {
+			return &DBError{Kind: ErrorUniqueViolation, Constraint: "uq", Err: err}
+		}
+		return nil
+	})
+
+	cs := []struct {
+		err  error
+		kind ErrorKind
+	}{
+		{fmt.Errorf("insert: %w", errDriver), ErrorUniqueViolation},
+		{context.Canceled, ErrorQueryCanceled},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), ErrorQueryCanceled},
+		{driver.ErrBadConn, ErrorConnectionLost},
+		{errors.New("other"), ErrorUnknown},
+	}
+
+	for i, c := range cs {
+		if k := ErrorKindOf(c.err); k != c.kind {
+			t.Errorf("#%d ErrorKindOf(%v) = %v, want %v", i, c.err, k, c.kind)
+		}
+	}
+
+	de := ClassifyError(fmt.Errorf("insert: %w", errDriver))
+	if de.Constraint != "uq" {
+		t.Errorf("Constraint = %q, want %q", de.Constraint, "uq")
+	}
+
+	err := fmt.Errorf("repo: %w", de)
+	if !errors.Is(err, ErrUniqueViolation) || errors.Is(err, ErrDeadlock) {
+		t.Errorf("errors.Is(%v) mismatch", err)
+	}
+	if !errors.Is(err, errDriver) {
+		t.Errorf("errors.Is(%v, errDriver) = false", err)
+	}
+	if ClassifyError(err) != de {
+		t.Error("ClassifyError() should return the wrapped *DBError")
+	}
+
+	if ClassifyError(nil) != nil {
+		t.Error("ClassifyError(nil) should return nil")
+	}
+	if !IsRetryable(&DBError{Kind: ErrorDeadlock, Err: errDriver}) {
+		t.Error("IsRetryable(deadlock) = false")
+	}
+}
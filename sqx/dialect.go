@@ -0,0 +1,39 @@
+package sqx
+
+import (
+	"github.com/askasoft/pango/str"
+)
+
+// Dialect the SQL dialect of the database
+type Dialect int
+
+const (
+	DialectUnknown Dialect = iota
+	DialectPostgres
+	DialectMySQL
+	DialectSQLite
+	DialectSQLServer
+	DialectOracle
+)
+
+// GetDialect returns the dialect for a given database given a drivername.
+// The dialect is detected by the Binder of the driver, or the "mysql" / "sqlite" in the driver name.
+func GetDialect(driverName string) Dialect {
+	switch GetBinder(driverName) {
+	case BindDollar:
+		return DialectPostgres
+	case BindAt:
+		return DialectSQLServer
+	case BindColon:
+		return DialectOracle
+	}
+
+	switch {
+	case str.Contains(driverName, "mysql"):
+		return DialectMySQL
+	case str.Contains(driverName, "sqlite"):
+		return DialectSQLite
+	default:
+		return DialectUnknown
+	}
+}
@@ -0,0 +1,27 @@
+package sqx
+
+import (
+	"testing"
+)
+
+func TestGetDialect(t *testing.T) {
+	cs := []struct {
+		d string
+		w Dialect
+	}{
+		{"postgres", DialectPostgres},
+		{"pgx", DialectPostgres},
+		{"mysql", DialectMySQL},
+		{"nrmysql", DialectMySQL},
+		{"sqlite3", DialectSQLite},
+		{"sqlserver", DialectSQLServer},
+		{"godror", DialectOracle},
+		{"unknown", DialectUnknown},
+	}
+
+	for i, c := range cs {
+		if a := GetDialect(c.d); a != c.w {
+			t.Errorf("#%d GetDialect(%q) = %v, want %v", i, c.d, a, c.w)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package sqx
+
+import (
+	"strings"
+
+	"github.com/askasoft/pango/str"
+)
+
+// Sort a sort column of ORDER BY
+type Sort struct {
+	Col  string
+	Desc bool
+}
+
+// String returns "col" or "-col" (descending)
+func (s Sort) String() string {
+	return str.If(s.Desc, "-", "") + s.Col
+}
+
+// ParseSorts parse the order string to sorts.
+// Example:
+//
+//	ParseSorts("-updated_at,id") // [{updated_at true} {id false}]
+func ParseSorts(order string) []Sort {
+	var sorts []Sort
+	for _, o := range str.FieldsByte(order, ',') {
+		o = str.Strip(o)
+		if o == "" {
+			continue
+		}
+		if str.StartsWithByte(o, '-') {
+			sorts = append(sorts, Sort{Col: o[1:], Desc: true})
+		} else {
+			sorts = append(sorts, Sort{Col: o})
+		}
+	}
+	return sorts
+}
+
+// OrderBy add ORDER BY sorts
+func (b *Builder) OrderBy(sorts ...Sort) *Builder {
+	for _, s := range sorts {
+		b.Order(s.Col, s.Desc)
+	}
+	return b
+}
+
+// supportsRowValues returns true if the dialect supports the row values comparison "(a, b) > (?, ?)".
+// PostgreSQL, MySQL and SQLite support it, SQL Server and Oracle do not.
+func (b *Builder) supportsRowValues() bool {
+	return b.Binder == BindDollar || b.Binder == BindQuestion
+}
+
+// Seek add the keyset (seek) pagination condition which seeks the rows after the last row values by the sorts.
+// If all the sorts have the same direction and the dialect supports the row values comparison,
+// the condition is `("a", "b") > (?, ?)`, otherwise `("a" > ? OR ("a" = ? AND "b" > ?))`.
+// Example:
+//
+//	sorts := sqx.ParseSorts("-updated_at,id")
+//	sqb.Select().From("users").Seek(sorts, last.UpdatedAt, last.ID).OrderBy(sorts...).Limit(20)
+func (b *Builder) Seek(sorts []Sort, values ...any) *Builder {
+	if len(sorts) != len(values) {
+		panic("sqx: Seek() sorts and values count mismatch")
+	}
+	if len(sorts) == 0 {
+		return b
+	}
+
+	if b.supportsRowValues() && sameDirection(sorts) {
+		if len(sorts) == 1 {
+			return b.Where(b.Quote(sorts[0].Col)+str.If(sorts[0].Desc, " < ?", " > ?"), values[0])
+		}
+
+		sb := &strings.Builder{}
+		sb.WriteByte('(')
+		for i, s := range sorts {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(b.Quote(s.Col))
+		}
+		sb.WriteString(str.If(sorts[0].Desc, ") < (", ") > ("))
+		sb.WriteString(str.Join(Questions(len(sorts)), ", "))
+		sb.WriteByte(')')
+		return b.Where(sb.String(), values...)
+	}
+
+	var args []any
+
+	sb := &strings.Builder{}
+	if len(sorts) > 1 {
+		sb.WriteByte('(')
+	}
+	for i, s := range sorts {
+		if i > 0 {
+			sb.WriteString(" OR (")
+			for j := 0; j < i; j++ {
+				sb.WriteString(b.Quote(sorts[j].Col))
+				sb.WriteString(" = ? AND ")
+				args = append(args, values[j])
+			}
+		}
+		sb.WriteString(b.Quote(s.Col))
+		sb.WriteString(str.If(s.Desc, " < ?", " > ?"))
+		args = append(args, values[i])
+		if i > 0 {
+			sb.WriteByte(')')
+		}
+	}
+	if len(sorts) > 1 {
+		sb.WriteByte(')')
+	}
+	return b.Where(sb.String(), args...)
+}
+
+// Keyset add the keyset pagination condition (if values is not empty), ORDER BY sorts and LIMIT.
+// The values are the sort column values of the last row of the previous page.
+func (b *Builder) Keyset(sorts []Sort, limit int, values ...any) *Builder {
+	if len(values) > 0 {
+		b.Seek(sorts, values...)
+	}
+	return b.OrderBy(sorts...).Limit(limit)
+}
+
+func sameDirection(sorts []Sort) bool {
+	for _, s := range sorts[1:] {
+		if s.Desc != sorts[0].Desc {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package sqx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSorts(t *testing.T) {
+	sorts := ParseSorts("-updated_at, id")
+	want := []Sort{{"updated_at", true}, {"id", false}}
+	if !reflect.DeepEqual(sorts, want) {
+		t.Errorf("ParseSorts() = %v, want %v", sorts, want)
+	}
+}
+
+func TestBuilder_Seek(t *testing.T) {
+	cs := []struct {
+		b *Builder
+		s []Sort
+		v []any
+		w string
+		a []any
+	}{
+		{
+			&Builder{Binder: BindDollar, Quoter: QuoteDefault},
+			ParseSorts("a,b"), []any{1, 2},
+			`SELECT * FROM "t" WHERE ("a", "b") > ($1, $2) ORDER BY "a" ASC, "b" ASC LIMIT 10`,
+			[]any{1, 2},
+		},
+		{
+			&Builder{Binder: BindQuestion, Quoter: QuoteBackticks},
+			ParseSorts("-a,-b"), []any{1, 2},
+			"SELECT * FROM `t` WHERE (`a`, `b`) < (?, ?) ORDER BY `a` DESC, `b` DESC LIMIT 10",
+			[]any{1, 2},
+		},
+		{
+			&Builder{Binder: BindDollar, Quoter: QuoteDefault},
+			ParseSorts("-a"), []any{1},
+			`SELECT * FROM "t" WHERE "a" < $1 ORDER BY "a" DESC LIMIT 10`,
+			[]any{1},
+		},
+		{
+			&Builder{Binder: BindDollar, Quoter: QuoteDefault},
+			ParseSorts("-a,b,c"), []any{1, 2, 3},
+			`SELECT * FROM "t" WHERE ("a" < $1 OR ("a" = $2 AND "b" > $3) OR ("a" = $4 AND "b" = $5 AND "c" > $6)) ORDER BY "a" DESC, "b" ASC, "c" ASC LIMIT 10`,
+			[]any{1, 1, 2, 1, 2, 3},
+		},
+		{
+			&Builder{Binder: BindAt, Quoter: QuoteBrackets},
+			ParseSorts("a,b"), []any{1, 2},
+			`SELECT * FROM [t] WHERE ([a] > @p1 OR ([a] = @p2 AND [b] > @p3)) ORDER BY [a] ASC, [b] ASC OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY`,
+			[]any{1, 1, 2},
+		},
+		{
+			&Builder{Binder: BindDollar, Quoter: QuoteDefault},
+			ParseSorts("a,b"), nil,
+			`SELECT * FROM "t" ORDER BY "a" ASC, "b" ASC LIMIT 10`,
+			nil,
+		},
+	}
+
+	for i, c := range cs {
+		sql, args := c.b.Select().From("t").Keyset(c.s, 10, c.v...).Build()
+		if sql != c.w {
+			t.Errorf("#%d\ngot : %s\nwant: %s", i, sql, c.w)
+		}
+		if !reflect.DeepEqual(args, c.a) {
+			t.Errorf("#%d args = %v, want %v", i, args, c.a)
+		}
+	}
+}
+
+func TestBuilder_SeekMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Seek() with mismatched values should panic")
+		}
+	}()
+
+	newBuilder().Seek(ParseSorts("a,b"), 1)
+}
+
+func TestBuilder_LimitOffset(t *testing.T) {
+	cs := []struct {
+		b *Builder
+		w string
+	}{
+		{(&Builder{Binder: BindDollar, Quoter: QuoteDefault}).Offset(20), `SELECT * FROM "t" OFFSET 20`},
+		{(&Builder{Binder: BindQuestion, Quoter: QuoteBackticks}).Offset(20), "SELECT * FROM `t` LIMIT 9223372036854775807 OFFSET 20"},
+		{(&Builder{Binder: BindQuestion, Quoter: QuoteDefault}).Limit(10).Offset(20), `SELECT * FROM "t" LIMIT 10 OFFSET 20`},
+		{(&Builder{Binder: BindAt, Quoter: QuoteBrackets}).Limit(10).Offset(20), `SELECT * FROM [t] ORDER BY (SELECT NULL) OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY`},
+		{(&Builder{Binder: BindAt, Quoter: QuoteBrackets}).Order("id").Offset(20), `SELECT * FROM [t] ORDER BY [id] OFFSET 20 ROWS`},
+		{(&Builder{Binder: BindColon, Quoter: QuoteDefault}).Limit(10).Offset(20), `SELECT * FROM "t" OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY`},
+	}
+
+	for i, c := range cs {
+		if sql := c.b.Select().From("t").SQL(); sql != c.w {
+			t.Errorf("#%d\ngot : %s\nwant: %s", i, c.b.Rebind(sql), c.w)
+		}
+	}
+}
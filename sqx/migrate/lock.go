@@ -0,0 +1,142 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"time"
+
+	"github.com/askasoft/pango/sqx"
+	"github.com/askasoft/pango/sqx/sqlx"
+)
+
+// locker prevents the concurrent migration runners.
+// The timeout 0 means not to wait, and a negative timeout means to wait until the context is done.
+type locker interface {
+	Lock(ctx context.Context, c *sqlx.Conn, timeout time.Duration) error
+	Unlock(ctx context.Context, c *sqlx.Conn) error
+}
+
+func newLocker(driverName string, quote func(string) string, table string, stale time.Duration) locker {
+	switch sqx.GetDialect(driverName) {
+	case sqx.DialectPostgres:
+		h := fnv.New64a()
+		h.Write([]byte(table))
+		return &pgLocker{key: int64(h.Sum64())} //nolint: gosec
+	case sqx.DialectMySQL:
+		return &myLocker{name: "migrate:" + table}
+	default:
+		return &tableLocker{table: quote(table + "_lock"), stale: stale}
+	}
+}
+
+// pgLocker PostgreSQL session advisory lock
+type pgLocker struct {
+	key int64
+}
+
+func (pl *pgLocker) Lock(ctx context.Context, c *sqlx.Conn, timeout time.Duration) error {
+	if timeout == 0 {
+		var ok bool
+		if err := c.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", pl.key).Scan(&ok); err != nil {
+			return fmt.Errorf("%w: %w", ErrLocked, err)
+		}
+		if !ok {
+			return ErrLocked
+		}
+		return nil
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if _, err := c.ExecContext(ctx, "SELECT pg_advisory_lock($1)", pl.key); err != nil {
+		return fmt.Errorf("%w: %w", ErrLocked, err)
+	}
+	return nil
+}
+
+func (pl *pgLocker) Unlock(ctx context.Context, c *sqlx.Conn) error {
+	_, err := c.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", pl.key)
+	return err
+}
+
+// myLocker MySQL named lock
+type myLocker struct {
+	name string
+}
+
+func (ml *myLocker) Lock(ctx context.Context, c *sqlx.Conn, timeout time.Duration) error {
+	// GET_LOCK() waits forever if the timeout is negative
+	secs := -1
+	if timeout >= 0 {
+		secs = int(math.Ceil(timeout.Seconds()))
+	}
+
+	var ok int
+	if err := c.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", ml.name, secs).Scan(&ok); err != nil {
+		return fmt.Errorf("%w: %w", ErrLocked, err)
+	}
+	if ok != 1 {
+		return ErrLocked
+	}
+	return nil
+}
+
+func (ml *myLocker) Unlock(ctx context.Context, c *sqlx.Conn) error {
+	_, err := c.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", ml.name)
+	return err
+}
+
+// tableLocker a lock row in the lock table (SQLite and the other databases).
+// The lock row older than the stale duration is considered abandoned by a crashed runner, and is deleted.
+type tableLocker struct {
+	table string
+	stale time.Duration
+}
+
+func (tl *tableLocker) Lock(ctx context.Context, c *sqlx.Conn, timeout time.Duration) error {
+	sql := "CREATE TABLE IF NOT EXISTS " + tl.table + " (id INTEGER NOT NULL PRIMARY KEY, locked_at TIMESTAMP NOT NULL)"
+	if _, err := c.ExecContext(ctx, sql); err != nil {
+		return err
+	}
+
+	sql = c.Rebind("INSERT INTO " + tl.table + " (id, locked_at) VALUES (1, ?)")
+	expire := c.Rebind("DELETE FROM " + tl.table + " WHERE id = 1 AND locked_at < ?")
+
+	deadline := time.Now().Add(timeout)
+	for {
+		_, err := c.ExecContext(ctx, sql, time.Now().UTC())
+		if err == nil {
+			return nil
+		}
+
+		if tl.stale > 0 {
+			r, er := c.ExecContext(ctx, expire, time.Now().UTC().Add(-tl.stale))
+			if er == nil {
+				if n, _ := r.RowsAffected(); n > 0 {
+					continue
+				}
+			}
+		}
+
+		if timeout >= 0 && time.Now().After(deadline) {
+			return fmt.Errorf("%w: %w", ErrLocked, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ErrLocked, ctx.Err())
+		case <-time.After(time.Millisecond * 100):
+		}
+	}
+}
+
+func (tl *tableLocker) Unlock(ctx context.Context, c *sqlx.Conn) error {
+	_, err := c.ExecContext(ctx, "DELETE FROM "+tl.table+" WHERE id = 1")
+	return err
+}
@@ -0,0 +1,464 @@
+// Package migrate provides a schema migration runner.
+//
+// The migrations are discovered from the versioned SQL files of a fs.FS:
+//
+//	0001_create_users.up.sql
+//	0001_create_users.down.sql
+//	0002_add_users_email.up.sql
+//
+// The applied versions and the checksums of the up scripts are recorded in a history table.
+// The SQL script is splitted to statements by sqx.SqlReader,
+// so a statement that contains ';' in the body (e.g. a stored procedure) is not supported.
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/askasoft/pango/log"
+	"github.com/askasoft/pango/sqx"
+	"github.com/askasoft/pango/sqx/sqlx"
+)
+
+var (
+	ErrDirty  = errors.New("migrate: applied migration was changed")
+	ErrLocked = errors.New("migrate: failed to acquire lock")
+)
+
+// Migration a versioned migration
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string // the up script file path
+	Down     string // the down script file path, empty if not exists
+	Checksum string // the sha256 checksum of the up script
+}
+
+func (m *Migration) String() string {
+	return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
+
+// Record a applied migration record of the history table
+type Record struct {
+	Version   int64     `db:"version"`
+	Name      string    `db:"name"`
+	Checksum  string    `db:"checksum"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+// Status the status of a migration
+type Status struct {
+	*Migration
+	Record  *Record // nil if not applied
+	Changed bool    // the applied migration script was changed
+}
+
+// Migrator the schema migration runner
+type Migrator struct {
+	DB  *sqlx.DB
+	FS  fs.FS
+	Dir string
+
+	// Table the history table name
+	Table string
+
+	// LockTimeout the timeout to acquire the migration lock.
+	// 0 means not to wait, a negative value means to wait until the context is done.
+	LockTimeout time.Duration
+
+	// StaleLockAge the age of the lock row to be considered abandoned by a crashed runner.
+	// It is only used by the lock table (SQLite and the other databases without a named lock),
+	// the PostgreSQL advisory lock and the MySQL named lock are released when the session ends.
+	// 0 means the lock row never expires.
+	StaleLockAge time.Duration
+
+	// DryRun write the SQL statements to the writer instead of executing them.
+	// The database is not changed in the dry run mode: the history table is not created and the lock is not acquired.
+	DryRun io.Writer
+
+	Logger log.Logger
+}
+
+// NewMigrator create a Migrator with the default history table "schema_migrations"
+func NewMigrator(db *sqlx.DB, fsys fs.FS, dir string) *Migrator {
+	return &Migrator{
+		DB:           db,
+		FS:           fsys,
+		Dir:          dir,
+		Table:        "schema_migrations",
+		LockTimeout:  time.Minute,
+		StaleLockAge: time.Hour,
+		Logger:       log.GetLogger("SQL"),
+	}
+}
+
+var reMigrationFile = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migrations discover the migrations from the FS, sorted by version.
+func (mg *Migrator) Migrations() ([]*Migration, error) {
+	des, err := fs.ReadDir(mg.FS, mg.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	mm := make(map[int64]*Migration)
+	for _, de := range des {
+		if de.IsDir() {
+			continue
+		}
+
+		sm := reMigrationFile.FindStringSubmatch(de.Name())
+		if sm == nil {
+			continue
+		}
+
+		ver, err := strconv.ParseInt(sm[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: invalid version of %q: %w", de.Name(), err)
+		}
+
+		m, ok := mm[ver]
+		if !ok {
+			m = &Migration{Version: ver, Name: sm[2]}
+			mm[ver] = m
+		} else if m.Name != sm[2] {
+			return nil, fmt.Errorf("migrate: duplicated version %d: %q, %q", ver, m.Name, sm[2])
+		}
+
+		fp := path.Join(mg.Dir, de.Name())
+		if sm[3] == "up" {
+			m.Up = fp
+		} else {
+			m.Down = fp
+		}
+	}
+
+	ms := make([]*Migration, 0, len(mm))
+	for _, m := range mm {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migrate: missing up script of %s", m)
+		}
+
+		data, err := fs.ReadFile(mg.FS, m.Up)
+		if err != nil {
+			return nil, err
+		}
+		m.Checksum = checksum(data)
+
+		ms = append(ms, m)
+	}
+
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].Version < ms[j].Version
+	})
+	return ms, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Records returns the applied migration records of the history table, sorted by version.
+func (mg *Migrator) Records(ctx context.Context) ([]*Record, error) {
+	return mg.records(ctx, mg.DB)
+}
+
+func (mg *Migrator) records(ctx context.Context, q sqlx.Sqlx) ([]*Record, error) {
+	var rs []*Record
+
+	sql := "SELECT version, name, checksum, applied_at FROM " + q.Quote(mg.Table) + " ORDER BY version"
+	err := q.SelectContext(ctx, &rs, sql)
+	return rs, err
+}
+
+// Status returns the status of all migrations
+func (mg *Migrator) Status(ctx context.Context) ([]*Status, error) {
+	ms, err := mg.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	rs, err := mg.readRecords(ctx, mg.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	return status(ms, rs), nil
+}
+
+// readRecords create the history table (or check the existence of the history table in the dry run mode),
+// and read the applied migration records.
+func (mg *Migrator) readRecords(ctx context.Context, q sqlx.Sqlx) ([]*Record, error) {
+	if mg.DryRun == nil {
+		if err := mg.createTable(ctx, q); err != nil {
+			return nil, err
+		}
+		return mg.records(ctx, q)
+	}
+
+	ok, err := mg.tableExists(ctx, q)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return mg.records(ctx, q)
+}
+
+// tableExists check the existence of the history table
+func (mg *Migrator) tableExists(ctx context.Context, q sqlx.Sqlx) (bool, error) {
+	var sql string
+	switch sqx.GetDialect(mg.DB.DriverName()) {
+	case sqx.DialectSQLite:
+		sql = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	case sqx.DialectPostgres:
+		sql = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?"
+	case sqx.DialectMySQL:
+		sql = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+	default:
+		sql = "SELECT COUNT(*) FROM information_schema.tables WHERE table_name = ?"
+	}
+
+	var cnt int
+	err := q.GetContext(ctx, &cnt, q.Rebind(sql), mg.Table)
+	return cnt > 0, err
+}
+
+func status(ms []*Migration, rs []*Record) []*Status {
+	rm := make(map[int64]*Record, len(rs))
+	for _, r := range rs {
+		rm[r.Version] = r
+	}
+
+	ss := make([]*Status, len(ms))
+	for i, m := range ms {
+		s := &Status{Migration: m, Record: rm[m.Version]}
+		if s.Record != nil {
+			s.Changed = s.Record.Checksum != m.Checksum
+		}
+		ss[i] = s
+	}
+	return ss
+}
+
+// Check check the applied migrations, returns ErrDirty if the script of a applied migration was changed.
+func (mg *Migrator) Check(ctx context.Context) error {
+	ss, err := mg.Status(ctx)
+	if err != nil {
+		return err
+	}
+	return checkDirty(ss)
+}
+
+func checkDirty(ss []*Status) error {
+	var errs []error
+	for _, s := range ss {
+		if s.Changed {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrDirty, s.Migration))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Up apply all pending migrations, returns the applied migrations.
+func (mg *Migrator) Up(ctx context.Context) ([]*Migration, error) {
+	return mg.UpTo(ctx, -1)
+}
+
+// UpTo apply the pending migrations until the version (include), all pending migrations if version < 0.
+// Returns ErrDirty if a applied migration was changed.
+func (mg *Migrator) UpTo(ctx context.Context, version int64) (applied []*Migration, err error) {
+	err = mg.run(ctx, func(c *sqlx.Conn, ss []*Status) error {
+		if err := checkDirty(ss); err != nil {
+			return err
+		}
+
+		for _, s := range ss {
+			if s.Record != nil {
+				continue
+			}
+			if version >= 0 && s.Version > version {
+				break
+			}
+
+			if err := mg.apply(ctx, c, s.Migration, true); err != nil {
+				return err
+			}
+			applied = append(applied, s.Migration)
+		}
+		return nil
+	})
+	return
+}
+
+// Down revert the last n applied migrations, returns the reverted migrations.
+func (mg *Migrator) Down(ctx context.Context, n int) (reverted []*Migration, err error) {
+	err = mg.run(ctx, func(c *sqlx.Conn, ss []*Status) error {
+		for i := len(ss) - 1; i >= 0 && len(reverted) < n; i-- {
+			s := ss[i]
+			if s.Record == nil {
+				continue
+			}
+
+			if s.Down == "" {
+				return fmt.Errorf("migrate: missing down script of %s", s.Migration)
+			}
+
+			if err := mg.apply(ctx, c, s.Migration, false); err != nil {
+				return err
+			}
+			reverted = append(reverted, s.Migration)
+		}
+		return nil
+	})
+	return
+}
+
+// run acquire the lock on a dedicated connection, and call fn with the migration status
+func (mg *Migrator) run(ctx context.Context, fn func(c *sqlx.Conn, ss []*Status) error) (err error) {
+	ms, err := mg.Migrations()
+	if err != nil {
+		return err
+	}
+
+	c, err := mg.DB.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if mg.DryRun != nil {
+		rs, err := mg.readRecords(ctx, c)
+		if err != nil {
+			return err
+		}
+		if rs == nil {
+			fmt.Fprintf(mg.DryRun, "%s;\n\n", mg.createTableSQL())
+		}
+		return fn(c, status(ms, rs))
+	}
+
+	if err = mg.createTable(ctx, c); err != nil {
+		return err
+	}
+
+	lk := newLocker(mg.DB.DriverName(), mg.DB.Quote, mg.Table, mg.StaleLockAge)
+	if err = lk.Lock(ctx, c, mg.LockTimeout); err != nil {
+		return err
+	}
+	defer func() {
+		if er := lk.Unlock(context.WithoutCancel(ctx), c); er != nil && err == nil {
+			err = er
+		}
+	}()
+
+	rs, err := mg.records(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	return fn(c, status(ms, rs))
+}
+
+func (mg *Migrator) createTableSQL() string {
+	return "CREATE TABLE IF NOT EXISTS " + mg.DB.Quote(mg.Table) + ` (
+	version BIGINT NOT NULL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	checksum VARCHAR(64) NOT NULL,
+	applied_at TIMESTAMP NOT NULL
+)`
+}
+
+func (mg *Migrator) createTable(ctx context.Context, e sqx.ContextExecer) error {
+	_, err := e.ExecContext(ctx, mg.createTableSQL())
+	return err
+}
+
+// transactional returns true if the dialect supports transactional DDL
+func (mg *Migrator) transactional() bool {
+	return sqx.GetDialect(mg.DB.DriverName()) != sqx.DialectMySQL
+}
+
+// apply execute the up (or down) script of the migration, and update the history table.
+func (mg *Migrator) apply(ctx context.Context, c *sqlx.Conn, m *Migration, up bool) error {
+	fp, verb := m.Up, "apply"
+	if !up {
+		fp, verb = m.Down, "revert"
+	}
+
+	data, err := fs.ReadFile(mg.FS, fp)
+	if err != nil {
+		return err
+	}
+
+	stmts, err := readStatements(data)
+	if err != nil {
+		return fmt.Errorf("migrate: failed to read %q: %w", fp, err)
+	}
+
+	var sql string
+	var args []any
+	if up {
+		sql = c.Rebind("INSERT INTO " + c.Quote(mg.Table) + " (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)")
+		args = []any{m.Version, m.Name, m.Checksum, time.Now()}
+	} else {
+		sql = c.Rebind("DELETE FROM " + c.Quote(mg.Table) + " WHERE version = ?")
+		args = []any{m.Version}
+	}
+
+	if mg.DryRun != nil {
+		fmt.Fprintf(mg.DryRun, "-- %s %s\n", verb, m)
+		for _, stmt := range stmts {
+			fmt.Fprintf(mg.DryRun, "%s;\n", stmt)
+		}
+		fmt.Fprintf(mg.DryRun, "%s;\n\n", c.Explain(sql, args...))
+		return nil
+	}
+
+	if mg.Logger != nil {
+		mg.Logger.Infof("migrate: %s %s", verb, m)
+	}
+
+	exec := func(e sqx.ContextExecer) error {
+		for _, stmt := range stmts {
+			if _, err := e.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("migrate: failed to %s %s: %w", verb, m, err)
+			}
+		}
+		_, err := e.ExecContext(ctx, sql, args...)
+		return err
+	}
+
+	if mg.transactional() {
+		return c.Transactionx(ctx, nil, func(tx *sqlx.Tx) error {
+			return exec(tx)
+		})
+	}
+	return exec(c)
+}
+
+func readStatements(data []byte) ([]string, error) {
+	var stmts []string
+
+	sr := sqx.NewSqlReader(bytes.NewReader(data))
+	for {
+		stmt, err := sr.ReadSql()
+		if errors.Is(err, io.EOF) {
+			return stmts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, stmt)
+	}
+}
@@ -0,0 +1,252 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/askasoft/pango/sqx/sqlx"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func testMigrationFS() fstest.MapFS {
+	return fstest.MapFS{
+		"db/0001_create_users.up.sql": {Data: []byte(`
+-- users
+CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL);
+INSERT INTO users (name) VALUES ('a;b');
+`)},
+		"db/0001_create_users.down.sql": {Data: []byte(`DROP TABLE users;`)},
+		"db/0002_add_email.up.sql":      {Data: []byte(`ALTER TABLE users ADD COLUMN email TEXT;`)},
+		"db/0002_add_email.down.sql":    {Data: []byte(`ALTER TABLE users DROP COLUMN email;`)},
+		"db/0003_bad.up.sql":            {Data: []byte(`CREATE TABLE posts (id INTEGER PRIMARY KEY); INSERT INTO nothing VALUES (1);`)},
+		"db/readme.txt":                 {Data: []byte(`ignored`)},
+	}
+}
+
+func testOpenDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("sqlite3", filepath.Join(t.TempDir(), "migrate.db3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func testVersions(ms []*Migration) []int64 {
+	var vs []int64
+	for _, m := range ms {
+		vs = append(vs, m.Version)
+	}
+	return vs
+}
+
+func TestMigrations(t *testing.T) {
+	mg := NewMigrator(nil, testMigrationFS(), "db")
+
+	ms, err := mg.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) != 3 || ms[0].String() != "1_create_users" || ms[2].Down != "" || ms[1].Down != "db/0002_add_email.down.sql" {
+		t.Errorf("Migrations() = %v", ms)
+	}
+
+	fsys := testMigrationFS()
+	fsys["db/0004_x.down.sql"] = &fstest.MapFile{}
+	mg.FS = fsys
+	if _, err := mg.Migrations(); err == nil {
+		t.Error("Migrations() should return error for missing up script")
+	}
+}
+
+func TestMigrateUpDown(t *testing.T) {
+	ctx := context.Background()
+	db := testOpenDB(t)
+
+	mg := NewMigrator(db, testMigrationFS(), "db")
+
+	ms, err := mg.UpTo(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vs := testVersions(ms); len(vs) != 2 || vs[0] != 1 || vs[1] != 2 {
+		t.Fatalf("UpTo(2) = %v", vs)
+	}
+
+	var name string
+	if err := db.Get(&name, "SELECT name FROM users"); err != nil || name != "a;b" {
+		t.Errorf("users.name = %q, %v", name, err)
+	}
+
+	// the failed migration is rolled back
+	if _, err := mg.Up(ctx); err == nil {
+		t.Fatal("Up() should return error")
+	}
+	var cnt int
+	if err := db.Get(&cnt, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'posts'"); err != nil || cnt != 0 {
+		t.Errorf("posts table count = %d, %v", cnt, err)
+	}
+
+	ss, err := mg.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 3 || ss[0].Record == nil || ss[1].Record == nil || ss[2].Record != nil {
+		t.Errorf("Status() = %v", ss)
+	}
+
+	// the lock is released
+	if _, err := mg.UpTo(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	ms, err = mg.Down(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vs := testVersions(ms); len(vs) != 1 || vs[0] != 2 {
+		t.Fatalf("Down(1) = %v", vs)
+	}
+
+	rs, err := mg.Records(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 1 || rs[0].Version != 1 || rs[0].AppliedAt.IsZero() {
+		t.Errorf("Records() = %v", rs)
+	}
+}
+
+func TestMigrateDirty(t *testing.T) {
+	ctx := context.Background()
+	db := testOpenDB(t)
+
+	fsys := testMigrationFS()
+	mg := NewMigrator(db, fsys, "db")
+
+	if _, err := mg.UpTo(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys["db/0001_create_users.up.sql"] = &fstest.MapFile{Data: []byte(`CREATE TABLE users (id INTEGER PRIMARY KEY);`)}
+
+	if err := mg.Check(ctx); !errors.Is(err, ErrDirty) {
+		t.Errorf("Check() = %v, want %v", err, ErrDirty)
+	}
+	if _, err := mg.Up(ctx); !errors.Is(err, ErrDirty) {
+		t.Errorf("Up() = %v, want %v", err, ErrDirty)
+	}
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	ctx := context.Background()
+	db := testOpenDB(t)
+
+	sb := &strings.Builder{}
+
+	mg := NewMigrator(db, testMigrationFS(), "db")
+	mg.DryRun = sb
+
+	if _, err := mg.UpTo(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	out := sb.String()
+	for _, s := range []string{
+		"-- apply 1_create_users\n",
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL);\n",
+		`INSERT INTO "schema_migrations" (version, name, checksum, applied_at) VALUES (2, 'add_email', '`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("DryRun does not contains %q\n%s", s, out)
+		}
+	}
+
+	var tables []string
+	if err := db.Select(&tables, "SELECT name FROM sqlite_master WHERE type = 'table'"); err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 0 {
+		t.Errorf("DryRun created tables %v", tables)
+	}
+
+	ss, err := mg.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range ss {
+		if s.Record != nil {
+			t.Errorf("DryRun Status() %d applied", s.Version)
+		}
+	}
+}
+
+func TestMigrateLocked(t *testing.T) {
+	ctx := context.Background()
+	db := testOpenDB(t)
+
+	mg := NewMigrator(db, testMigrationFS(), "db")
+	mg.LockTimeout = 0
+
+	c, err := db.Connx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	lk := newLocker("sqlite3", db.Quote, mg.Table, 0)
+	if err := lk.Lock(ctx, c, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := mg.Up(ctx); !errors.Is(err, ErrLocked) {
+		t.Errorf("Up() = %v, want %v", err, ErrLocked)
+	}
+
+	if err := lk.Unlock(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mg.UpTo(ctx, 1); err != nil {
+		t.Errorf("Up() = %v", err)
+	}
+}
+
+func TestMigrateStaleLock(t *testing.T) {
+	ctx := context.Background()
+	db := testOpenDB(t)
+
+	mg := NewMigrator(db, testMigrationFS(), "db")
+	mg.LockTimeout = 0
+	mg.StaleLockAge = time.Minute
+
+	c, err := db.Connx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	lk := newLocker("sqlite3", db.Quote, mg.Table, 0)
+	if err := lk.Lock(ctx, c, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := mg.Up(ctx); !errors.Is(err, ErrLocked) {
+		t.Errorf("Up() = %v, want %v", err, ErrLocked)
+	}
+
+	// a lock row left by a crashed runner
+	sql := c.Rebind("UPDATE " + db.Quote(mg.Table+"_lock") + " SET locked_at = ?")
+	if _, err := c.Exec(sql, time.Now().UTC().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := mg.UpTo(ctx, 1); err != nil {
+		t.Errorf("Up() = %v", err)
+	}
+}
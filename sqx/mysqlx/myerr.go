@@ -2,18 +2,94 @@ package mysqlx
 
 import (
 	"errors"
+	"regexp"
+	"strconv"
 
+	"github.com/askasoft/pango/sqx"
 	"github.com/go-sql-driver/mysql"
 )
 
 func IsUniqueViolationError(err error) bool {
-	if err != nil {
-		var myErr *mysql.MySQLError
-		if ok := errors.As(err, &myErr); ok {
-			if myErr.Number == 1062 {
-				return true
-			}
+	return errorKindOf(err) == sqx.ErrorUniqueViolation
+}
+
+func IsDeadlockError(err error) bool {
+	return errorKindOf(err) == sqx.ErrorDeadlock
+}
+
+func IsLockWaitTimeoutError(err error) bool {
+	return errorKindOf(err) == sqx.ErrorLockTimeout
+}
+
+// IsRetryableError returns true if the transaction failed with a deadlock or a lock wait timeout,
+// and can be retried.
+func IsRetryableError(err error) bool {
+	k := errorKindOf(err)
+	return k == sqx.ErrorDeadlock || k == sqx.ErrorLockTimeout
+}
+
+// errorKindOf returns the sqx.ErrorKind of the MySQL error, returns sqx.ErrorUnknown if the error is not a MySQL error.
+func errorKindOf(err error) sqx.ErrorKind {
+	if de := ClassifyError(err); de != nil {
+		return de.Kind
+	}
+	return sqx.ErrorUnknown
+}
+
+var (
+	reMyDupKey    = regexp.MustCompile(`for key '(?:([^'.]+)\.)?([^']+)'`)
+	reMyFKey      = regexp.MustCompile("`([^`]+)`, CONSTRAINT `([^`]+)` FOREIGN KEY \\(`([^`]+)`")
+	reMyNotNull   = regexp.MustCompile(`Column '([^']+)' cannot be null`)
+	reMyCheckCons = regexp.MustCompile(`Check constraint '([^']+)'`)
+)
+
+func init() {
+	sqx.RegisterErrorClassifier(ClassifyError)
+}
+
+// ClassifyError classifies the MySQL error to *sqx.DBError, returns nil if the error is not a MySQL error.
+// The constraint, table and column names are parsed from the error message.
+func ClassifyError(err error) *sqx.DBError {
+	if errors.Is(err, mysql.ErrInvalidConn) {
+		return &sqx.DBError{Kind: sqx.ErrorConnectionLost, Err: err}
+	}
+
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return nil
+	}
+
+	de := &sqx.DBError{Code: strconv.Itoa(int(myErr.Number)), Err: err}
+
+	switch myErr.Number {
+	case 1062:
+		de.Kind = sqx.ErrorUniqueViolation
+		if m := reMyDupKey.FindStringSubmatch(myErr.Message); m != nil {
+			de.Table, de.Constraint = m[1], m[2]
+		}
+	case 1216, 1217, 1451, 1452:
+		de.Kind = sqx.ErrorForeignKeyViolation
+		if m := reMyFKey.FindStringSubmatch(myErr.Message); m != nil {
+			de.Table, de.Constraint, de.Column = m[1], m[2], m[3]
+		}
+	case 1048:
+		de.Kind = sqx.ErrorNotNullViolation
+		if m := reMyNotNull.FindStringSubmatch(myErr.Message); m != nil {
+			de.Column = m[1]
+		}
+	case 3819:
+		de.Kind = sqx.ErrorCheckViolation
+		if m := reMyCheckCons.FindStringSubmatch(myErr.Message); m != nil {
+			de.Constraint = m[1]
 		}
+	case 1213:
+		de.Kind = sqx.ErrorDeadlock
+	case 1205, 3572:
+		de.Kind = sqx.ErrorLockTimeout
+	case 1317, 3024:
+		de.Kind = sqx.ErrorQueryCanceled
+	case 1053, 1077, 1078, 1079, 1080:
+		de.Kind = sqx.ErrorConnectionLost
 	}
-	return false
+	return de
 }
@@ -0,0 +1,41 @@
+package mysqlx
+
+import (
+	"testing"
+
+	"github.com/askasoft/pango/sqx"
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestClassifyError(t *testing.T) {
+	cs := []struct {
+		err    *mysql.MySQLError
+		kind   sqx.ErrorKind
+		table  string
+		column string
+		cons   string
+	}{
+		{&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a' for key 'users.uq_name'"}, sqx.ErrorUniqueViolation, "users", "", "uq_name"},
+		{&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a' for key 'PRIMARY'"}, sqx.ErrorUniqueViolation, "", "", "PRIMARY"},
+		{&mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails (`db`.`child`, CONSTRAINT `fk_parent` FOREIGN KEY (`pid`) REFERENCES `parent` (`id`))"}, sqx.ErrorForeignKeyViolation, "child", "pid", "fk_parent"},
+		{&mysql.MySQLError{Number: 1048, Message: "Column 'name' cannot be null"}, sqx.ErrorNotNullViolation, "", "name", ""},
+		{&mysql.MySQLError{Number: 3819, Message: "Check constraint 'chk_age' is violated."}, sqx.ErrorCheckViolation, "", "", "chk_age"},
+		{&mysql.MySQLError{Number: 1213, Message: "Deadlock found"}, sqx.ErrorDeadlock, "", "", ""},
+		{&mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"}, sqx.ErrorLockTimeout, "", "", ""},
+		{&mysql.MySQLError{Number: 1064, Message: "syntax error"}, sqx.ErrorUnknown, "", "", ""},
+	}
+
+	for i, c := range cs {
+		de := sqx.ClassifyError(c.err)
+		if de.Kind != c.kind || de.Table != c.table || de.Column != c.column || de.Constraint != c.cons {
+			t.Errorf("#%d ClassifyError(%v) = %+v", i, c.err, de)
+		}
+	}
+
+	if !IsRetryableError(&mysql.MySQLError{Number: 1213}) {
+		t.Error("IsRetryableError(1213) = false")
+	}
+	if !sqx.IsConnectionLost(mysql.ErrInvalidConn) {
+		t.Error("IsConnectionLost(ErrInvalidConn) = false")
+	}
+}
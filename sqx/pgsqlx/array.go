@@ -3,14 +3,15 @@ package pgsqlx
 import (
 	"bytes"
 	"database/sql/driver"
+	"errors"
 	"fmt"
 	"strconv"
-	"strings"
 
 	"github.com/askasoft/pango/str"
 )
 
 // BoolArray represents a one-dimensional array of the PostgreSQL boolean type.
+// A multi-dimensional array is scanned as the flattened elements in row-major order, use BoolArray2D to keep the two-dimensional shape.
 type BoolArray []bool
 
 func (a BoolArray) Slice() []bool {
@@ -33,7 +34,7 @@ func (a *BoolArray) Scan(src any) error {
 }
 
 func (a *BoolArray) scanBytes(src []byte) error {
-	elems, err := scanLinearArray(src, "BoolArray")
+	elems, err := scanLinearArray(src)
 	if err != nil {
 		return err
 	}
@@ -90,6 +91,7 @@ func (a BoolArray) Value() (driver.Value, error) {
 
 // Float64Array represents a one-dimensional array of the PostgreSQL double
 // precision type.
+// A multi-dimensional array is scanned as the flattened elements in row-major order, use Float64Array2D to keep the two-dimensional shape.
 type Float64Array []float64
 
 func (a Float64Array) Slice() []float64 {
@@ -112,7 +114,7 @@ func (a *Float64Array) Scan(src any) error {
 }
 
 func (a *Float64Array) scanBytes(src []byte) error {
-	elems, err := scanLinearArray(src, "Float64Array")
+	elems, err := scanLinearArray(src)
 	if err != nil {
 		return err
 	}
@@ -156,6 +158,7 @@ func (a Float64Array) Value() (driver.Value, error) {
 
 // Float32Array represents a one-dimensional array of the PostgreSQL double
 // precision type.
+// A multi-dimensional array is scanned as the flattened elements in row-major order, use Float32Array2D to keep the two-dimensional shape.
 type Float32Array []float32
 
 func (a Float32Array) Slice() []float32 {
@@ -178,7 +181,7 @@ func (a *Float32Array) Scan(src any) error {
 }
 
 func (a *Float32Array) scanBytes(src []byte) error {
-	elems, err := scanLinearArray(src, "Float32Array")
+	elems, err := scanLinearArray(src)
 	if err != nil {
 		return err
 	}
@@ -223,6 +226,7 @@ func (a Float32Array) Value() (driver.Value, error) {
 }
 
 // Int64Array represents a one-dimensional array of the PostgreSQL integer types.
+// A multi-dimensional array is scanned as the flattened elements in row-major order, use Int64Array2D to keep the two-dimensional shape.
 type Int64Array []int64
 
 func (a Int64Array) Slice() []int64 {
@@ -245,7 +249,7 @@ func (a *Int64Array) Scan(src any) error {
 }
 
 func (a *Int64Array) scanBytes(src []byte) error {
-	elems, err := scanLinearArray(src, "Int64Array")
+	elems, err := scanLinearArray(src)
 	if err != nil {
 		return err
 	}
@@ -288,6 +292,7 @@ func (a Int64Array) Value() (driver.Value, error) {
 }
 
 // Int32Array represents a one-dimensional array of the PostgreSQL integer types.
+// A multi-dimensional array is scanned as the flattened elements in row-major order, use Int32Array2D to keep the two-dimensional shape.
 type Int32Array []int32
 
 func (a Int32Array) Slice() []int32 {
@@ -310,7 +315,7 @@ func (a *Int32Array) Scan(src any) error {
 }
 
 func (a *Int32Array) scanBytes(src []byte) error {
-	elems, err := scanLinearArray(src, "Int32Array")
+	elems, err := scanLinearArray(src)
 	if err != nil {
 		return err
 	}
@@ -355,6 +360,7 @@ func (a Int32Array) Value() (driver.Value, error) {
 }
 
 // IntArray represents a one-dimensional array of the PostgreSQL integer types.
+// A multi-dimensional array is scanned as the flattened elements in row-major order, use IntArray2D to keep the two-dimensional shape.
 type IntArray []int
 
 func (a IntArray) Slice() []int {
@@ -377,7 +383,7 @@ func (a *IntArray) Scan(src any) error {
 }
 
 func (a *IntArray) scanBytes(src []byte) error {
-	elems, err := scanLinearArray(src, "IntArray")
+	elems, err := scanLinearArray(src)
 	if err != nil {
 		return err
 	}
@@ -422,6 +428,7 @@ func (a IntArray) Value() (driver.Value, error) {
 }
 
 // StringArray represents a one-dimensional array of the PostgreSQL character types.
+// A multi-dimensional array is scanned as the flattened elements in row-major order, use StringArray2D to keep the two-dimensional shape.
 type StringArray []string
 
 func (a StringArray) Slice() []string {
@@ -444,7 +451,7 @@ func (a *StringArray) Scan(src any) error {
 }
 
 func (a *StringArray) scanBytes(src []byte) error {
-	elems, err := scanLinearArray(src, "StringArray")
+	elems, err := scanLinearArray(src)
 	if err != nil {
 		return err
 	}
@@ -513,6 +520,7 @@ var null = []byte("NULL")
 
 func parseArray(src []byte) (dims []int, elems [][]byte, err error) {
 	var depth, i int
+	var cnts []int
 
 	if len(src) < 1 || src[0] != '{' {
 		return nil, nil, fmt.Errorf("pgsqlx: unable to parse array; expected %q at offset %d", '{', 0)
@@ -532,6 +540,7 @@ Open:
 		}
 	}
 	dims = make([]int, i)
+	cnts = make([]int, i)
 
 Element:
 	for i < len(src) {
@@ -541,7 +550,7 @@ Element:
 				break Element
 			}
 			depth++
-			dims[depth-1] = 0
+			cnts[depth-1] = 0
 			i++
 		case '"':
 			var elem = []byte{}
@@ -582,11 +591,16 @@ Element:
 
 	for i < len(src) {
 		if src[i] == ',' && depth > 0 {
-			dims[depth-1]++
+			cnts[depth-1]++
 			i++
 			goto Element
 		} else if src[i] == '}' && depth > 0 {
-			dims[depth-1]++
+			cnts[depth-1]++
+			if dims[depth-1] == 0 {
+				dims[depth-1] = cnts[depth-1]
+			} else if dims[depth-1] != cnts[depth-1] {
+				return nil, nil, errors.New("pgsqlx: multidimensional arrays must have elements with matching dimensions")
+			}
 			depth--
 			i++
 		} else {
@@ -606,23 +620,20 @@ Close:
 	if depth > 0 {
 		err = fmt.Errorf("pgsqlx: unable to parse array; expected %q at offset %d", '}', i)
 	}
-	if err == nil {
+	if err == nil && len(dims) > 0 {
+		n := 1
 		for _, d := range dims {
-			if (len(elems) % d) != 0 {
-				err = fmt.Errorf("pgsqlx: multidimensional arrays must have elements with matching dimensions")
-			}
+			n *= d
+		}
+		if n != len(elems) {
+			err = errors.New("pgsqlx: multidimensional arrays must have elements with matching dimensions")
 		}
 	}
 	return
 }
 
-func scanLinearArray(src []byte, typ string) (elems [][]byte, err error) {
-	dims, elems, err := parseArray(src)
-	if err != nil {
-		return nil, err
-	}
-	if len(dims) > 1 {
-		return nil, fmt.Errorf("pgsqlx: cannot convert ARRAY%s to %s", strings.ReplaceAll(fmt.Sprint(dims), " ", "]["), typ)
-	}
+// scanLinearArray parses the array, the elements of the multi-dimensional array are flattened in row-major order.
+func scanLinearArray(src []byte) ([][]byte, error) {
+	_, elems, err := parseArray(src)
 	return elems, err
 }
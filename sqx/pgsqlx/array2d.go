@@ -0,0 +1,210 @@
+package pgsqlx
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/askasoft/pango/str"
+)
+
+// The *Array2D types scan only the two-dimensional arrays (or the empty array),
+// a one-dimensional array or an array of more than two dimensions is rejected with an error.
+// Use the one-dimensional *Array types to scan an array of more than two dimensions as the flattened elements.
+
+// BoolArray2D represents a two-dimensional array of the PostgreSQL boolean type.
+type BoolArray2D [][]bool
+
+// Scan implements the sql.Scanner interface.
+func (a *BoolArray2D) Scan(src any) (err error) {
+	*a, err = scanArray2D(src, "BoolArray2D", func(v []byte) (bool, error) {
+		if len(v) == 1 {
+			switch v[0] {
+			case 't':
+				return true, nil
+			case 'f':
+				return false, nil
+			}
+		}
+		return false, fmt.Errorf("invalid boolean %q", v)
+	})
+	return
+}
+
+// Value implements the driver.Valuer interface.
+func (a BoolArray2D) Value() (driver.Value, error) {
+	return array2DValue(a, func(r []bool) (driver.Value, error) { return BoolArray(r).Value() })
+}
+
+// Float64Array2D represents a two-dimensional array of the PostgreSQL double precision type.
+type Float64Array2D [][]float64
+
+// Scan implements the sql.Scanner interface.
+func (a *Float64Array2D) Scan(src any) (err error) {
+	*a, err = scanArray2D(src, "Float64Array2D", func(v []byte) (float64, error) {
+		return strconv.ParseFloat(str.UnsafeString(v), 64)
+	})
+	return
+}
+
+// Value implements the driver.Valuer interface.
+func (a Float64Array2D) Value() (driver.Value, error) {
+	return array2DValue(a, func(r []float64) (driver.Value, error) { return Float64Array(r).Value() })
+}
+
+// Float32Array2D represents a two-dimensional array of the PostgreSQL real type.
+type Float32Array2D [][]float32
+
+// Scan implements the sql.Scanner interface.
+func (a *Float32Array2D) Scan(src any) (err error) {
+	*a, err = scanArray2D(src, "Float32Array2D", func(v []byte) (float32, error) {
+		x, err := strconv.ParseFloat(str.UnsafeString(v), 32)
+		return float32(x), err
+	})
+	return
+}
+
+// Value implements the driver.Valuer interface.
+func (a Float32Array2D) Value() (driver.Value, error) {
+	return array2DValue(a, func(r []float32) (driver.Value, error) { return Float32Array(r).Value() })
+}
+
+// Int64Array2D represents a two-dimensional array of the PostgreSQL integer types.
+type Int64Array2D [][]int64
+
+// Scan implements the sql.Scanner interface.
+func (a *Int64Array2D) Scan(src any) (err error) {
+	*a, err = scanArray2D(src, "Int64Array2D", func(v []byte) (int64, error) {
+		return strconv.ParseInt(str.UnsafeString(v), 10, 64)
+	})
+	return
+}
+
+// Value implements the driver.Valuer interface.
+func (a Int64Array2D) Value() (driver.Value, error) {
+	return array2DValue(a, func(r []int64) (driver.Value, error) { return Int64Array(r).Value() })
+}
+
+// Int32Array2D represents a two-dimensional array of the PostgreSQL integer types.
+type Int32Array2D [][]int32
+
+// Scan implements the sql.Scanner interface.
+func (a *Int32Array2D) Scan(src any) (err error) {
+	*a, err = scanArray2D(src, "Int32Array2D", func(v []byte) (int32, error) {
+		x, err := strconv.ParseInt(str.UnsafeString(v), 10, 32)
+		return int32(x), err
+	})
+	return
+}
+
+// Value implements the driver.Valuer interface.
+func (a Int32Array2D) Value() (driver.Value, error) {
+	return array2DValue(a, func(r []int32) (driver.Value, error) { return Int32Array(r).Value() })
+}
+
+// IntArray2D represents a two-dimensional array of the PostgreSQL integer types.
+type IntArray2D [][]int
+
+// Scan implements the sql.Scanner interface.
+func (a *IntArray2D) Scan(src any) (err error) {
+	*a, err = scanArray2D(src, "IntArray2D", func(v []byte) (int, error) {
+		x, err := strconv.ParseInt(str.UnsafeString(v), 10, strconv.IntSize)
+		return int(x), err
+	})
+	return
+}
+
+// Value implements the driver.Valuer interface.
+func (a IntArray2D) Value() (driver.Value, error) {
+	return array2DValue(a, func(r []int) (driver.Value, error) { return IntArray(r).Value() })
+}
+
+// StringArray2D represents a two-dimensional array of the PostgreSQL character types.
+type StringArray2D [][]string
+
+// Scan implements the sql.Scanner interface.
+func (a *StringArray2D) Scan(src any) (err error) {
+	*a, err = scanArray2D(src, "StringArray2D", func(v []byte) (string, error) {
+		return string(v), nil
+	})
+	return
+}
+
+// Value implements the driver.Valuer interface.
+func (a StringArray2D) Value() (driver.Value, error) {
+	return array2DValue(a, func(r []string) (driver.Value, error) { return StringArray(r).Value() })
+}
+
+func scanArray2D[T any](src any, typ string, parse func([]byte) (T, error)) ([][]T, error) {
+	var bs []byte
+
+	switch src := src.(type) {
+	case []byte:
+		bs = src
+	case string:
+		bs = str.UnsafeBytes(src)
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("pgsqlx: cannot convert %T to %s", src, typ)
+	}
+
+	dims, elems, err := parseArray(bs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dims) == 0 {
+		return [][]T{}, nil
+	}
+	if len(dims) != 2 {
+		return nil, fmt.Errorf("pgsqlx: cannot convert ARRAY%s to %s", strings.ReplaceAll(fmt.Sprint(dims), " ", "]["), typ)
+	}
+
+	a := make([][]T, dims[0])
+	for i := range a {
+		a[i] = make([]T, dims[1])
+		for j := range a[i] {
+			v := elems[i*dims[1]+j]
+			if v == nil {
+				return nil, fmt.Errorf("pgsqlx: parsing array element index [%d][%d]: cannot convert nil to %s", i, j, typ)
+			}
+			if a[i][j], err = parse(v); err != nil {
+				return nil, fmt.Errorf("pgsqlx: parsing array element index [%d][%d]: %w", i, j, err)
+			}
+		}
+	}
+	return a, nil
+}
+
+func array2DValue[T any](a [][]T, value func([]T) (driver.Value, error)) (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	if len(a) == 0 || len(a[0]) == 0 {
+		return "{}", nil
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteByte('{')
+	for i, r := range a {
+		if len(r) != len(a[0]) {
+			return nil, errors.New("pgsqlx: multidimensional arrays must have elements with matching dimensions")
+		}
+
+		v, err := value(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(v.(string))
+	}
+	sb.WriteByte('}')
+	return sb.String(), nil
+}
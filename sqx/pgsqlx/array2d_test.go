@@ -0,0 +1,96 @@
+package pgsqlx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseArrayDims(t *testing.T) {
+	cs := []struct {
+		s string
+		d []int
+		e bool
+	}{
+		{"{}", nil, false},
+		{"{1,2,3}", []int{3}, false},
+		{"{{1,2},{3,4}}", []int{2, 2}, false},
+		{"{{{1},{2}},{{3},{4}},{{5},{6}}}", []int{3, 2, 1}, false},
+		{"{{1,2},{3}}", nil, true},
+		{"{{1,2,3},{4},{5,6}}", nil, true},
+	}
+
+	for i, c := range cs {
+		d, _, err := parseArray([]byte(c.s))
+		if c.e {
+			if err == nil {
+				t.Errorf("#%d parseArray(%q) expected error", i, c.s)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d parseArray(%q) = %v", i, c.s, err)
+			continue
+		}
+		if len(d) != len(c.d) || (len(d) > 0 && !reflect.DeepEqual(d, c.d)) {
+			t.Errorf("#%d parseArray(%q) dims = %v, want %v", i, c.s, d, c.d)
+		}
+	}
+}
+
+func TestInt64Array2D(t *testing.T) {
+	var a Int64Array2D
+	if err := a.Scan("{{1,2,3},{4,5,6}}"); err != nil {
+		t.Fatal(err)
+	}
+	if w := (Int64Array2D{{1, 2, 3}, {4, 5, 6}}); !reflect.DeepEqual(a, w) {
+		t.Errorf("Scan() = %v, want %v", a, w)
+	}
+
+	v, err := a.Value()
+	if err != nil || v != "{{1,2,3},{4,5,6}}" {
+		t.Errorf("Value() = %v, %v", v, err)
+	}
+
+	if err := a.Scan("{1,2}"); err == nil {
+		t.Error("Scan(1D) expected error")
+	}
+	if err := a.Scan("{{{1},{2}},{{3},{4}}}"); err == nil {
+		t.Error("Scan(3D) expected error")
+	}
+
+	var la Int64Array
+	if err := la.Scan("{{1,2},{3,4}}"); err != nil {
+		t.Errorf("Int64Array.Scan(2D) error: %v", err)
+	} else if w := (Int64Array{1, 2, 3, 4}); !reflect.DeepEqual(la, w) {
+		t.Errorf("Int64Array.Scan(2D) = %v, want %v", la, w)
+	}
+	if err := la.Scan("{{{1},{2}},{{3},{4}}}"); err != nil {
+		t.Errorf("Int64Array.Scan(3D) error: %v", err)
+	} else if w := (Int64Array{1, 2, 3, 4}); !reflect.DeepEqual(la, w) {
+		t.Errorf("Int64Array.Scan(3D) = %v, want %v", la, w)
+	}
+	if _, err := (Int64Array2D{{1, 2}, {3}}).Value(); err == nil {
+		t.Error("Value(ragged) expected error")
+	}
+}
+
+func TestStringArray2D(t *testing.T) {
+	w := StringArray2D{{"a", `b"c`}, {"d,e", `f\g`}}
+
+	v, err := w.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var a StringArray2D
+	if err := a.Scan(v); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, w) {
+		t.Errorf("Scan(%q) = %q, want %q", v, a, w)
+	}
+
+	if err := a.Scan(`{{"a",NULL}}`); err == nil {
+		t.Error("Scan(NULL) expected error")
+	}
+}
@@ -0,0 +1,143 @@
+package pgsqlx
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/askasoft/pango/str"
+)
+
+// Hstore represents a PostgreSQL hstore value, a NULL value is represented by an invalid sql.NullString.
+type Hstore map[string]sql.NullString
+
+// String returns the PostgreSQL text representation of the hstore, the keys are sorted.
+func (h Hstore) String() string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	sb := &strings.Builder{}
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		writeHstoreQuoted(sb, k)
+		sb.WriteString("=>")
+		if v := h[k]; v.Valid {
+			writeHstoreQuoted(sb, v.String)
+		} else {
+			sb.WriteString("NULL")
+		}
+	}
+	return sb.String()
+}
+
+func writeHstoreQuoted(sb *strings.Builder, s string) {
+	sb.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == '"' || c == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(s[i])
+	}
+	sb.WriteByte('"')
+}
+
+// Parse parses the PostgreSQL text representation of the hstore.
+func (h *Hstore) Parse(s string) error {
+	m := Hstore{}
+
+	for s = str.StripLeft(s); s != ""; s = str.StripLeft(s) {
+		k, rest, ok := readHstoreToken(s)
+		if !ok {
+			return fmt.Errorf("pgsqlx: invalid hstore key at %q", s)
+		}
+
+		rest = str.StripLeft(rest)
+		if !str.StartsWith(rest, "=>") {
+			return fmt.Errorf("pgsqlx: invalid hstore, expected '=>' at %q", rest)
+		}
+		rest = str.StripLeft(rest[2:])
+
+		var v sql.NullString
+		if len(rest) >= 4 && str.EqualFold(rest[:4], "NULL") && (len(rest) == 4 || rest[4] == ',' || rest[4] == ' ') {
+			rest = rest[4:]
+		} else {
+			if v.String, rest, ok = readHstoreToken(rest); !ok {
+				return fmt.Errorf("pgsqlx: invalid hstore value at %q", rest)
+			}
+			v.Valid = true
+		}
+		m[k] = v
+
+		rest = str.StripLeft(rest)
+		if rest != "" {
+			if rest[0] != ',' {
+				return fmt.Errorf("pgsqlx: invalid hstore, expected ',' at %q", rest)
+			}
+			rest = rest[1:]
+		}
+		s = rest
+	}
+
+	*h = m
+	return nil
+}
+
+// readHstoreToken reads a double quoted or unquoted token.
+func readHstoreToken(s string) (token, rest string, ok bool) {
+	if s == "" {
+		return
+	}
+
+	if s[0] != '"' {
+		i := strings.IndexAny(s, "=, ")
+		if i < 0 {
+			i = len(s)
+		}
+		return s[:i], s[i:], i > 0
+	}
+
+	sb := &strings.Builder{}
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			if i++; i < len(s) {
+				sb.WriteByte(s[i])
+			}
+		case '"':
+			return sb.String(), s[i+1:], true
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return
+}
+
+// Scan implements the sql.Scanner interface.
+func (h *Hstore) Scan(src any) error {
+	switch src := src.(type) {
+	case []byte:
+		return h.Parse(string(src))
+	case string:
+		return h.Parse(src)
+	case nil:
+		*h = nil
+		return nil
+	default:
+		return fmt.Errorf("pgsqlx: cannot convert %T to Hstore", src)
+	}
+}
+
+// Value implements the driver.Valuer interface.
+func (h Hstore) Value() (driver.Value, error) {
+	if h == nil {
+		return nil, nil
+	}
+	return h.String(), nil
+}
@@ -0,0 +1,42 @@
+package pgsqlx
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestHstore(t *testing.T) {
+	var h Hstore
+	if err := h.Scan(`"a"=>"1", "b"=>NULL, "c d"=>"x\"y\\z", "e"=>""`); err != nil {
+		t.Fatal(err)
+	}
+
+	w := Hstore{
+		"a":   {String: "1", Valid: true},
+		"b":   {},
+		"c d": {String: `x"y\z`, Valid: true},
+		"e":   {String: "", Valid: true},
+	}
+	if !reflect.DeepEqual(h, w) {
+		t.Errorf("Scan() = %v, want %v", h, w)
+	}
+
+	v, _ := h.Value()
+	if v != `"a"=>"1", "b"=>NULL, "c d"=>"x\"y\\z", "e"=>""` {
+		t.Errorf("Value() = %v", v)
+	}
+
+	if err := h.Scan(""); err != nil || len(h) != 0 || h == nil {
+		t.Errorf("Scan(empty) = %v, %v", h, err)
+	}
+	if err := h.Scan(`a=>1,b=>"NULL"`); err != nil || h["b"] != (sql.NullString{String: "NULL", Valid: true}) {
+		t.Errorf("Scan(unquoted) = %v, %v", h, err)
+	}
+
+	for _, s := range []string{`"a"`, `"a"=>`, `"a"=>"1" "b"=>"2"`, `"a=>"1"`} {
+		if err := h.Scan(s); err == nil {
+			t.Errorf("Scan(%q) expected error", s)
+		}
+	}
+}
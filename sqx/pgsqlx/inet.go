@@ -0,0 +1,64 @@
+package pgsqlx
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"net/netip"
+
+	"github.com/askasoft/pango/str"
+)
+
+// Inet represents a PostgreSQL inet or cidr value.
+// A host address without the netmask (e.g. "192.168.0.1") is a prefix with the full bits (/32 or /128).
+// The zero value (invalid prefix) represents NULL.
+type Inet struct {
+	netip.Prefix
+}
+
+// ParseInet parses a inet or cidr string, e.g. "192.168.0.1", "10.0.0.0/8", "::1/128".
+func ParseInet(s string) (Inet, error) {
+	if str.ContainsByte(s, '/') {
+		p, err := netip.ParsePrefix(s)
+		return Inet{p}, err
+	}
+
+	a, err := netip.ParseAddr(s)
+	if err != nil {
+		return Inet{}, err
+	}
+	return Inet{netip.PrefixFrom(a, a.BitLen())}, nil
+}
+
+// String returns the text representation of the inet, the netmask is omitted for a host address.
+func (n Inet) String() string {
+	if !n.IsValid() {
+		return ""
+	}
+	if n.IsSingleIP() {
+		return n.Addr().String()
+	}
+	return n.Prefix.String()
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *Inet) Scan(src any) (err error) {
+	switch src := src.(type) {
+	case []byte:
+		*n, err = ParseInet(string(src))
+	case string:
+		*n, err = ParseInet(src)
+	case nil:
+		*n = Inet{}
+	default:
+		err = fmt.Errorf("pgsqlx: cannot convert %T to Inet", src)
+	}
+	return
+}
+
+// Value implements the driver.Valuer interface.
+func (n Inet) Value() (driver.Value, error) {
+	if !n.IsValid() {
+		return nil, nil
+	}
+	return n.String(), nil
+}
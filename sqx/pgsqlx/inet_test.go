@@ -0,0 +1,41 @@
+package pgsqlx
+
+import (
+	"testing"
+)
+
+func TestInet(t *testing.T) {
+	cs := []struct {
+		s string
+		w string
+	}{
+		{"192.168.0.1", "192.168.0.1"},
+		{"192.168.0.1/32", "192.168.0.1"},
+		{"192.168.0.1/24", "192.168.0.1/24"},
+		{"10.0.0.0/8", "10.0.0.0/8"},
+		{"::1", "::1"},
+		{"2001:db8::/32", "2001:db8::/32"},
+	}
+
+	for i, c := range cs {
+		var n Inet
+		if err := n.Scan([]byte(c.s)); err != nil {
+			t.Errorf("#%d Scan(%q) = %v", i, c.s, err)
+			continue
+		}
+		if v, _ := n.Value(); v != c.w {
+			t.Errorf("#%d Value() = %v, want %v", i, v, c.w)
+		}
+	}
+
+	var n Inet
+	if err := n.Scan("x.y"); err == nil {
+		t.Error("Scan(invalid) expected error")
+	}
+	if err := n.Scan(nil); err != nil || n.IsValid() {
+		t.Errorf("Scan(nil) = %v, %v", n, err)
+	}
+	if v, _ := n.Value(); v != nil {
+		t.Errorf("Value(NULL) = %v", v)
+	}
+}
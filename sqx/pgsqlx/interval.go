@@ -0,0 +1,177 @@
+package pgsqlx
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/askasoft/pango/str"
+)
+
+// Interval represents a PostgreSQL interval value.
+// The months, days and time part are stored separately as the PostgreSQL does,
+// because a month or a day does not have a fixed duration.
+type Interval struct {
+	Months       int32
+	Days         int32
+	Microseconds int64
+}
+
+// NewInterval returns the Interval of the duration d (in microseconds).
+func NewInterval(d time.Duration) Interval {
+	return Interval{Microseconds: d.Microseconds()}
+}
+
+// Duration returns the duration of the interval, assuming a month is 30 days and a day is 24 hours.
+func (iv Interval) Duration() time.Duration {
+	days := int64(iv.Months)*30 + int64(iv.Days)
+	return time.Duration(days)*24*time.Hour + time.Duration(iv.Microseconds)*time.Microsecond
+}
+
+// String returns the text representation of the interval in the "postgres" IntervalStyle.
+// e.g. "1 year 2 mons 3 days 04:05:06.789"
+func (iv Interval) String() string {
+	var ss []string
+
+	if y := iv.Months / 12; y != 0 {
+		ss = append(ss, strconv.Itoa(int(y))+str.If(y == 1, " year", " years"))
+	}
+	if m := iv.Months % 12; m != 0 {
+		ss = append(ss, strconv.Itoa(int(m))+str.If(m == 1, " mon", " mons"))
+	}
+	if d := iv.Days; d != 0 {
+		ss = append(ss, strconv.Itoa(int(d))+str.If(d == 1, " day", " days"))
+	}
+
+	if us := iv.Microseconds; us != 0 || len(ss) == 0 {
+		sign := ""
+		if us < 0 {
+			sign, us = "-", -us
+		}
+
+		s := fmt.Sprintf("%s%02d:%02d:%02d", sign, us/3600000000, us/60000000%60, us/1000000%60)
+		if f := us % 1000000; f != 0 {
+			s += strings.TrimRight(fmt.Sprintf(".%06d", f), "0")
+		}
+		ss = append(ss, s)
+	}
+
+	return str.Join(ss, " ")
+}
+
+// Parse parses the text representation of the interval in the "postgres" IntervalStyle,
+// or the verbose units like "3 days 4 hours 5 minutes".
+func (iv *Interval) Parse(s string) error {
+	v := Interval{}
+
+	fs := str.Fields(s)
+	for i := 0; i < len(fs); i++ {
+		f := fs[i]
+
+		if str.ContainsByte(f, ':') {
+			us, err := parseIntervalTime(f)
+			if err != nil {
+				return fmt.Errorf("pgsqlx: invalid interval %q: %w", s, err)
+			}
+			v.Microseconds += us
+			continue
+		}
+
+		if i+1 >= len(fs) {
+			return fmt.Errorf("pgsqlx: invalid interval %q: missing unit of %q", s, f)
+		}
+
+		n, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return fmt.Errorf("pgsqlx: invalid interval %q: %w", s, err)
+		}
+
+		i++
+		switch unit := str.ToLower(fs[i]); unit {
+		case "year", "years", "yr", "yrs", "y":
+			v.Months += int32(n * 12)
+		case "mon", "mons", "month", "months":
+			v.Months += int32(n)
+		case "week", "weeks", "w":
+			v.Days += int32(n * 7)
+		case "day", "days", "d":
+			v.Days += int32(n)
+		case "hour", "hours", "hr", "hrs", "h":
+			v.Microseconds += int64(n * 3600000000)
+		case "min", "mins", "minute", "minutes", "m":
+			v.Microseconds += int64(n * 60000000)
+		case "sec", "secs", "second", "seconds", "s":
+			v.Microseconds += int64(n * 1000000)
+		case "millisecond", "milliseconds", "msec", "msecs", "ms":
+			v.Microseconds += int64(n * 1000)
+		case "microsecond", "microseconds", "usec", "usecs", "us":
+			v.Microseconds += int64(n)
+		default:
+			return fmt.Errorf("pgsqlx: invalid interval %q: unknown unit %q", s, unit)
+		}
+	}
+
+	*iv = v
+	return nil
+}
+
+// parseIntervalTime parses the time part "[+-]hh:mm[:ss[.ffffff]]" to microseconds.
+func parseIntervalTime(s string) (int64, error) {
+	neg := false
+	switch s[0] {
+	case '-':
+		neg, s = true, s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	ps := str.FieldsByte(s, ':')
+	if len(ps) < 2 || len(ps) > 3 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+
+	h, err := strconv.ParseInt(ps[0], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	m, err := strconv.ParseInt(ps[1], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	us := (h*60 + m) * 60000000
+	if len(ps) == 3 {
+		sec, err := strconv.ParseFloat(ps[2], 64)
+		if err != nil {
+			return 0, err
+		}
+		us += int64(sec*1000000 + 0.5)
+	}
+
+	if neg {
+		us = -us
+	}
+	return us, nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (iv *Interval) Scan(src any) error {
+	switch src := src.(type) {
+	case []byte:
+		return iv.Parse(string(src))
+	case string:
+		return iv.Parse(src)
+	case nil:
+		*iv = Interval{}
+		return nil
+	default:
+		return fmt.Errorf("pgsqlx: cannot convert %T to Interval", src)
+	}
+}
+
+// Value implements the driver.Valuer interface.
+func (iv Interval) Value() (driver.Value, error) {
+	return iv.String(), nil
+}
@@ -0,0 +1,54 @@
+package pgsqlx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInterval(t *testing.T) {
+	cs := []struct {
+		s string
+		i Interval
+		w string
+	}{
+		{"00:00:00", Interval{}, "00:00:00"},
+		{"1 year 2 mons 3 days 04:05:06.789", Interval{Months: 14, Days: 3, Microseconds: 14706789000}, "1 year 2 mons 3 days 04:05:06.789"},
+		{"-1 days +02:03:00", Interval{Days: -1, Microseconds: 7380000000}, "-1 days 02:03:00"},
+		{"-00:00:01.5", Interval{Microseconds: -1500000}, "-00:00:01.5"},
+		{"2 weeks 1 hour 30 minutes", Interval{Days: 14, Microseconds: 5400000000}, "14 days 01:30:00"},
+		{"1 mon", Interval{Months: 1}, "1 mon"},
+	}
+
+	for i, c := range cs {
+		var iv Interval
+		if err := iv.Scan(c.s); err != nil {
+			t.Errorf("#%d Scan(%q) = %v", i, c.s, err)
+			continue
+		}
+		if iv != c.i {
+			t.Errorf("#%d Scan(%q) = %+v, want %+v", i, c.s, iv, c.i)
+		}
+		if v, _ := iv.Value(); v != c.w {
+			t.Errorf("#%d Value() = %v, want %v", i, v, c.w)
+		}
+	}
+
+	for _, s := range []string{"1", "1 fortnight", "1:2:3:4"} {
+		var iv Interval
+		if err := iv.Scan(s); err == nil {
+			t.Errorf("Scan(%q) expected error", s)
+		}
+	}
+}
+
+func TestIntervalDuration(t *testing.T) {
+	d := 90 * time.Minute
+	if a := NewInterval(d).Duration(); a != d {
+		t.Errorf("Duration() = %v, want %v", a, d)
+	}
+
+	iv := Interval{Months: 1, Days: 1}
+	if a, w := iv.Duration(), 31*24*time.Hour; a != w {
+		t.Errorf("Duration() = %v, want %v", a, w)
+	}
+}
@@ -0,0 +1,31 @@
+package libpq
+
+import (
+	"errors"
+
+	"github.com/askasoft/pango/sqx"
+	"github.com/askasoft/pango/sqx/pgsqlx"
+	"github.com/lib/pq"
+)
+
+func init() {
+	sqx.RegisterErrorClassifier(ClassifyError)
+}
+
+// ClassifyError classifies the lib/pq error to *sqx.DBError, returns nil if the error is not a lib/pq error.
+// The kind of the *pq.Error is mapped from the SQLSTATE code.
+func ClassifyError(err error) *sqx.DBError {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		code := string(pqErr.Code)
+		return &sqx.DBError{
+			Kind:       pgsqlx.SQLStateErrorKind(code),
+			Code:       code,
+			Constraint: pqErr.Constraint,
+			Table:      pqErr.Table,
+			Column:     pqErr.Column,
+			Err:        err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package pgsqlx
+
+import (
+	"github.com/askasoft/pango/sqx"
+	"github.com/askasoft/pango/str"
+)
+
+// SQLStateErrorKind returns the sqx.ErrorKind of the PostgreSQL SQLSTATE error code.
+func SQLStateErrorKind(code string) sqx.ErrorKind {
+	switch code {
+	case "23505":
+		return sqx.ErrorUniqueViolation
+	case "23503":
+		return sqx.ErrorForeignKeyViolation
+	case "23502":
+		return sqx.ErrorNotNullViolation
+	case "23514":
+		return sqx.ErrorCheckViolation
+	case "40P01":
+		return sqx.ErrorDeadlock
+	case "40001":
+		return sqx.ErrorSerializationFailure
+	case "55P03":
+		return sqx.ErrorLockTimeout
+	case "57014":
+		return sqx.ErrorQueryCanceled
+	case "57P01", "57P02", "57P03":
+		return sqx.ErrorConnectionLost
+	}
+
+	// Class 08 - Connection Exception
+	if str.StartsWith(code, "08") {
+		return sqx.ErrorConnectionLost
+	}
+	return sqx.ErrorUnknown
+}
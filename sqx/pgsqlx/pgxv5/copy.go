@@ -0,0 +1,113 @@
+package pgxv5
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Rawer the interface of *sql.Conn and *sqlx.Conn
+type Rawer interface {
+	Raw(f func(driverConn any) error) error
+}
+
+// PgxConn calls f with the underlying *pgx.Conn of the database/sql connection rc.
+func PgxConn(rc Rawer, f func(pc *pgx.Conn) error) error {
+	return rc.Raw(func(dc any) error {
+		sc, ok := dc.(*stdlib.Conn)
+		if !ok {
+			return errors.New("pgxv5: connection is not a pgx stdlib connection")
+		}
+		return f(sc.Conn())
+	})
+}
+
+// Identifier returns the pgx.Identifier of the table name (e.g. "schema.table").
+func Identifier(table string) pgx.Identifier {
+	return pgx.Identifier(strings.Split(table, "."))
+}
+
+// CopyFrom inserts the rows into the table by the PostgreSQL "COPY FROM STDIN" protocol.
+// Returns the copied row count.
+func CopyFrom(ctx context.Context, rc Rawer, table string, columns []string, rows [][]any) (cnt int64, err error) {
+	err = PgxConn(rc, func(pc *pgx.Conn) error {
+		cnt, err = pc.CopyFrom(ctx, Identifier(table), columns, pgx.CopyFromRows(rows))
+		return err
+	})
+	return
+}
+
+// CopyFromFunc inserts the rows returned by next into the table by the PostgreSQL "COPY FROM STDIN" protocol.
+// next returns a nil row to indicate the end of the rows.
+// Returns the copied row count.
+func CopyFromFunc(ctx context.Context, rc Rawer, table string, columns []string, next func() ([]any, error)) (cnt int64, err error) {
+	err = PgxConn(rc, func(pc *pgx.Conn) error {
+		cnt, err = pc.CopyFrom(ctx, Identifier(table), columns, pgx.CopyFromFunc(next))
+		return err
+	})
+	return
+}
+
+// CopyStream begins a transaction, calls fn with a CopyWriter which copies every n rows,
+// and commits the transaction if fn returns nil, otherwise rollbacks the transaction.
+// Returns the copied row count.
+func CopyStream(ctx context.Context, rc Rawer, table string, columns []string, n int, fn func(cw *CopyWriter) error) (cnt int64, err error) {
+	err = PgxConn(rc, func(pc *pgx.Conn) error {
+		return pgx.BeginFunc(ctx, pc, func(tx pgx.Tx) error {
+			cw := &CopyWriter{ctx: ctx, tx: tx, table: Identifier(table), columns: columns, size: max(n, 1)}
+			if err := fn(cw); err != nil {
+				return err
+			}
+			if err := cw.Flush(); err != nil {
+				return err
+			}
+			cnt = cw.count
+			return nil
+		})
+	})
+	if err != nil {
+		cnt = 0
+	}
+	return
+}
+
+// CopyWriter buffers the rows and copies them into the table in a transaction
+type CopyWriter struct {
+	ctx     context.Context
+	tx      pgx.Tx
+	table   pgx.Identifier
+	columns []string
+	size    int
+	rows    [][]any
+	count   int64
+}
+
+// Write buffers a copy of the row, and copies the buffered rows if the buffer is full
+func (cw *CopyWriter) Write(row ...any) error {
+	cw.rows = append(cw.rows, slices.Clone(row))
+	if len(cw.rows) >= cw.size {
+		return cw.Flush()
+	}
+	return nil
+}
+
+// Flush copies the buffered rows
+func (cw *CopyWriter) Flush() error {
+	if len(cw.rows) == 0 {
+		return nil
+	}
+
+	cnt, err := cw.tx.CopyFrom(cw.ctx, cw.table, cw.columns, pgx.CopyFromRows(cw.rows))
+	cw.count += cnt
+	cw.rows = cw.rows[:0]
+	return err
+}
+
+// Count returns the copied row count
+func (cw *CopyWriter) Count() int64 {
+	return cw.count
+}
@@ -3,18 +3,65 @@ package pgxv5
 import (
 	"errors"
 
-	"github.com/jackc/pgerrcode"
+	"github.com/askasoft/pango/sqx"
+	"github.com/askasoft/pango/sqx/pgsqlx"
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
 func IsUniqueViolationError(err error) bool {
-	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			if pgErr.Code == pgerrcode.UniqueViolation {
-				return true
-			}
+	return errorKindOf(err) == sqx.ErrorUniqueViolation
+}
+
+func IsSerializationFailureError(err error) bool {
+	return errorKindOf(err) == sqx.ErrorSerializationFailure
+}
+
+func IsDeadlockDetectedError(err error) bool {
+	return errorKindOf(err) == sqx.ErrorDeadlock
+}
+
+// IsRetryableError returns true if the transaction failed with a serialization failure or a deadlock,
+// and can be retried.
+func IsRetryableError(err error) bool {
+	k := errorKindOf(err)
+	return k == sqx.ErrorSerializationFailure || k == sqx.ErrorDeadlock
+}
+
+// errorKindOf returns the sqx.ErrorKind of the pgx error, returns sqx.ErrorUnknown if the error is not a pgx error.
+func errorKindOf(err error) sqx.ErrorKind {
+	if de := ClassifyError(err); de != nil {
+		return de.Kind
+	}
+	return sqx.ErrorUnknown
+}
+
+func init() {
+	sqx.RegisterErrorClassifier(ClassifyError)
+}
+
+// ClassifyError classifies the pgx error to *sqx.DBError, returns nil if the error is not a pgx error.
+// The kind of the *pgconn.PgError is mapped from the SQLSTATE code, the connect error and the timeout
+// are classified as sqx.ErrorConnectionLost and sqx.ErrorQueryCanceled.
+func ClassifyError(err error) *sqx.DBError {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return &sqx.DBError{
+			Kind:       pgsqlx.SQLStateErrorKind(pgErr.Code),
+			Code:       pgErr.Code,
+			Constraint: pgErr.ConstraintName,
+			Table:      pgErr.TableName,
+			Column:     pgErr.ColumnName,
+			Err:        err,
 		}
 	}
-	return false
+
+	var ceErr *pgconn.ConnectError
+	if errors.As(err, &ceErr) {
+		return &sqx.DBError{Kind: sqx.ErrorConnectionLost, Err: err}
+	}
+
+	if pgconn.Timeout(err) {
+		return &sqx.DBError{Kind: sqx.ErrorQueryCanceled, Err: err}
+	}
+	return nil
 }
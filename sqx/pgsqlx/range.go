@@ -0,0 +1,235 @@
+package pgsqlx
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/askasoft/pango/str"
+)
+
+// RangeElem is the element type constraint of Range.
+type RangeElem interface {
+	int32 | int64 | float64 | time.Time
+}
+
+// Range represents a PostgreSQL range type value (int4range, int8range, numrange, tstzrange).
+//
+//	r := pgsqlx.Int4Range{Lower: 1, Upper: 10, LowerInc: true} // [1,10)
+//
+// The infinity bounds of the timestamp range are scanned as unbounded.
+type Range[T RangeElem] struct {
+	Lower    T
+	Upper    T
+	LowerInc bool // the lower bound is inclusive '['
+	UpperInc bool // the upper bound is inclusive ']'
+	LowerInf bool // the lower bound is unbounded
+	UpperInf bool // the upper bound is unbounded
+	Empty    bool
+}
+
+// Int4Range represents a PostgreSQL int4range.
+type Int4Range = Range[int32]
+
+// Int8Range represents a PostgreSQL int8range.
+type Int8Range = Range[int64]
+
+// NumRange represents a PostgreSQL numrange.
+type NumRange = Range[float64]
+
+// TstzRange represents a PostgreSQL tstzrange or tsrange.
+type TstzRange = Range[time.Time]
+
+// String returns the PostgreSQL text representation of the range.
+func (r Range[T]) String() string {
+	return formatRange(r, formatRangeElem[T])
+}
+
+// Parse parses the PostgreSQL text representation of the range.
+func (r *Range[T]) Parse(s string) error {
+	return parseRange(r, s, parseRangeElem[T])
+}
+
+// Scan implements the sql.Scanner interface.
+func (r *Range[T]) Scan(src any) error {
+	switch src := src.(type) {
+	case []byte:
+		return r.Parse(string(src))
+	case string:
+		return r.Parse(src)
+	case nil:
+		*r = Range[T]{}
+		return nil
+	default:
+		return fmt.Errorf("pgsqlx: cannot convert %T to Range", src)
+	}
+}
+
+// Value implements the driver.Valuer interface.
+func (r Range[T]) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+// DateRange represents a PostgreSQL daterange.
+// The time part of the Lower and Upper is ignored.
+type DateRange struct {
+	Range[time.Time]
+}
+
+// String returns the PostgreSQL text representation of the date range.
+func (r DateRange) String() string {
+	return formatRange(r.Range, func(t time.Time) string {
+		return t.Format(time.DateOnly)
+	})
+}
+
+// Value implements the driver.Valuer interface.
+func (r DateRange) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+func formatRangeElem[T RangeElem](v T) string {
+	switch v := any(v).(type) {
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return `"` + v.Format(time.RFC3339Nano) + `"`
+	default:
+		panic(fmt.Sprintf("pgsqlx: unsupported range element type %T", v))
+	}
+}
+
+var rangeTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07:00:00",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	time.DateOnly,
+}
+
+func parseRangeElem[T RangeElem](s string) (v T, err error) {
+	switch p := any(&v).(type) {
+	case *int32:
+		var n int64
+		n, err = strconv.ParseInt(s, 10, 32)
+		*p = int32(n)
+	case *int64:
+		*p, err = strconv.ParseInt(s, 10, 64)
+	case *float64:
+		*p, err = strconv.ParseFloat(s, 64)
+	case *time.Time:
+		for _, layout := range rangeTimeLayouts {
+			if *p, err = time.Parse(layout, s); err == nil {
+				break
+			}
+		}
+	}
+	return
+}
+
+func formatRange[T RangeElem](r Range[T], format func(T) string) string {
+	if r.Empty {
+		return "empty"
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString(str.If(r.LowerInc && !r.LowerInf, "[", "("))
+	if !r.LowerInf {
+		sb.WriteString(format(r.Lower))
+	}
+	sb.WriteByte(',')
+	if !r.UpperInf {
+		sb.WriteString(format(r.Upper))
+	}
+	sb.WriteString(str.If(r.UpperInc && !r.UpperInf, "]", ")"))
+	return sb.String()
+}
+
+func parseRange[T RangeElem](r *Range[T], s string, parse func(string) (T, error)) error {
+	*r = Range[T]{}
+
+	if str.EqualFold(s, "empty") {
+		r.Empty = true
+		return nil
+	}
+
+	if len(s) < 3 || (s[0] != '[' && s[0] != '(') || (s[len(s)-1] != ']' && s[len(s)-1] != ')') {
+		return fmt.Errorf("pgsqlx: invalid range %q", s)
+	}
+
+	r.LowerInc = s[0] == '['
+	r.UpperInc = s[len(s)-1] == ']'
+
+	lower, upper, err := splitRange(s[1 : len(s)-1])
+	if err != nil {
+		return fmt.Errorf("pgsqlx: invalid range %q: %w", s, err)
+	}
+
+	if r.LowerInf = lower == "" || lower == "-infinity"; r.LowerInf {
+		r.LowerInc = false
+	} else if r.Lower, err = parse(lower); err != nil {
+		return fmt.Errorf("pgsqlx: invalid range %q: %w", s, err)
+	}
+
+	if r.UpperInf = upper == "" || upper == "infinity"; r.UpperInf {
+		r.UpperInc = false
+	} else if r.Upper, err = parse(upper); err != nil {
+		return fmt.Errorf("pgsqlx: invalid range %q: %w", s, err)
+	}
+	return nil
+}
+
+// splitRange splits the range bounds "lower,upper", the bound may be double quoted.
+func splitRange(s string) (lower, upper string, err error) {
+	lower, s, err = readRangeBound(s)
+	if err != nil {
+		return
+	}
+	if s == "" || s[0] != ',' {
+		err = errors.New("missing ','")
+		return
+	}
+	upper, s, err = readRangeBound(s[1:])
+	if err == nil && s != "" {
+		err = fmt.Errorf("unexpected %q", s)
+	}
+	return
+}
+
+func readRangeBound(s string) (bound, rest string, err error) {
+	if s == "" || s[0] != '"' {
+		i := strings.IndexByte(s, ',')
+		if i < 0 {
+			return s, "", nil
+		}
+		return s[:i], s[i:], nil
+	}
+
+	sb := &strings.Builder{}
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			if i++; i < len(s) {
+				sb.WriteByte(s[i])
+			}
+		case '"':
+			if i+1 < len(s) && s[i+1] == '"' {
+				sb.WriteByte('"')
+				i++
+				continue
+			}
+			return sb.String(), s[i+1:], nil
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", "", errors.New("unterminated quoted bound")
+}
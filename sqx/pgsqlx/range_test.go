@@ -0,0 +1,75 @@
+package pgsqlx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInt4Range(t *testing.T) {
+	cs := []struct {
+		s string
+		r Int4Range
+		w string
+	}{
+		{"[1,10)", Int4Range{Lower: 1, Upper: 10, LowerInc: true}, "[1,10)"},
+		{"(1,10]", Int4Range{Lower: 1, Upper: 10, UpperInc: true}, "(1,10]"},
+		{"[5,)", Int4Range{Lower: 5, LowerInc: true, UpperInf: true}, "[5,)"},
+		{"(,)", Int4Range{LowerInf: true, UpperInf: true}, "(,)"},
+		{"empty", Int4Range{Empty: true}, "empty"},
+	}
+
+	for i, c := range cs {
+		var r Int4Range
+		if err := r.Scan([]byte(c.s)); err != nil {
+			t.Errorf("#%d Scan(%q) = %v", i, c.s, err)
+			continue
+		}
+		if r != c.r {
+			t.Errorf("#%d Scan(%q) = %+v, want %+v", i, c.s, r, c.r)
+		}
+		if v, _ := r.Value(); v != c.w {
+			t.Errorf("#%d Value() = %v, want %v", i, v, c.w)
+		}
+	}
+
+	for _, s := range []string{"", "[1,2", "[1;2)", "[a,2)"} {
+		var r Int4Range
+		if err := r.Scan(s); err == nil {
+			t.Errorf("Scan(%q) expected error", s)
+		}
+	}
+}
+
+func TestTstzRange(t *testing.T) {
+	var r TstzRange
+	if err := r.Scan(`["2024-01-02 03:04:05.123+09","2024-02-01 00:00:00+00")`); err != nil {
+		t.Fatal(err)
+	}
+
+	l := time.Date(2024, 1, 2, 3, 4, 5, 123000000, time.FixedZone("", 9*3600))
+	u := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	if !r.Lower.Equal(l) || !r.Upper.Equal(u) || !r.LowerInc || r.UpperInc {
+		t.Errorf("Scan() = %+v", r)
+	}
+
+	if v, _ := r.Value(); v != `["2024-01-02T03:04:05.123+09:00","2024-02-01T00:00:00Z")` {
+		t.Errorf("Value() = %v", v)
+	}
+
+	if err := r.Scan(`[-infinity,infinity)`); err != nil || !r.LowerInf || !r.UpperInf {
+		t.Errorf("Scan(infinity) = %+v, %v", r, err)
+	}
+}
+
+func TestDateRange(t *testing.T) {
+	var r DateRange
+	if err := r.Scan("[2024-01-01,2024-02-01)"); err != nil {
+		t.Fatal(err)
+	}
+	if r.Lower != time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) || r.Upper != time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("Scan() = %+v", r)
+	}
+	if v, _ := r.Value(); v != "[2024-01-01,2024-02-01)" {
+		t.Errorf("Value() = %v", v)
+	}
+}
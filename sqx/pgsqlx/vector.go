@@ -80,3 +80,192 @@ func (v Vector) Value() (driver.Value, error) {
 	}
 	return v.String(), nil
 }
+
+// HalfVector is a wrapper for []float32 to implement sql.Scanner and driver.Valuer for the pgvector halfvec type.
+type HalfVector []float32
+
+// Slice returns the []float32 slice.
+func (v HalfVector) Slice() []float32 {
+	return v
+}
+
+// String returns a string representation of the vector.
+func (v HalfVector) String() string {
+	if n := len(v); n > 0 {
+		b := make([]byte, 1, 1+2*n)
+		b[0] = '['
+
+		b = strconv.AppendFloat(b, float64(v[0]), 'f', -1, 32)
+		for i := 1; i < n; i++ {
+			b = append(b, ',')
+			b = strconv.AppendFloat(b, float64(v[i]), 'f', -1, 32)
+		}
+
+		return str.UnsafeString(append(b, ']'))
+	}
+
+	return "[]"
+}
+
+// Parse parses a string representation of a vector.
+func (v *HalfVector) Parse(s string) error {
+	if !str.StartsWithByte(s, '[') || !str.EndsWithByte(s, ']') {
+		return nil
+	}
+
+	s = s[1 : len(s)-1]
+	if s == "" {
+		*v = HalfVector{}
+		return nil
+	}
+
+	ss := strings.Split(s, ",")
+
+	a := make([]float32, len(ss))
+	for i, s := range ss {
+		n, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return err
+		}
+		a[i] = float32(n)
+	}
+
+	*v = a
+	return nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (v *HalfVector) Scan(src any) (err error) {
+	switch src := src.(type) {
+	case []byte:
+		return v.Parse(str.UnsafeString(src))
+	case string:
+		return v.Parse(src)
+	case nil:
+		*v = nil
+		return nil
+	default:
+		return fmt.Errorf("pgsqlx: cannot convert %T to HalfVector", src)
+	}
+}
+
+// Value implements the driver.Valuer interface.
+func (v HalfVector) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return v.String(), nil
+}
+
+// SparseVector represents the pgvector sparsevec type.
+// The Indices are zero-based and in ascending order, the text representation is one-based: "{1:1.5,3:2}/5".
+type SparseVector struct {
+	Dim     int
+	Indices []int
+	Values  []float32
+}
+
+// NewSparseVector creates a SparseVector from the dense vector, the zero elements are omitted.
+func NewSparseVector(vs []float32) SparseVector {
+	sv := SparseVector{Dim: len(vs)}
+	for i, v := range vs {
+		if v != 0 {
+			sv.Indices = append(sv.Indices, i)
+			sv.Values = append(sv.Values, v)
+		}
+	}
+	return sv
+}
+
+// Slice returns the dense []float32 slice.
+func (sv SparseVector) Slice() []float32 {
+	vs := make([]float32, sv.Dim)
+	for i, x := range sv.Indices {
+		vs[x] = sv.Values[i]
+	}
+	return vs
+}
+
+// String returns a string representation of the sparse vector.
+func (sv SparseVector) String() string {
+	b := make([]byte, 1, 2+8*len(sv.Indices))
+	b[0] = '{'
+
+	for i, x := range sv.Indices {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = strconv.AppendInt(b, int64(x+1), 10)
+		b = append(b, ':')
+		b = strconv.AppendFloat(b, float64(sv.Values[i]), 'f', -1, 32)
+	}
+
+	b = append(b, '}', '/')
+	b = strconv.AppendInt(b, int64(sv.Dim), 10)
+	return str.UnsafeString(b)
+}
+
+// Parse parses a string representation of a sparse vector.
+func (sv *SparseVector) Parse(s string) error {
+	es, ds, ok := str.CutByte(s, '/')
+	if !ok || !str.StartsWithByte(es, '{') || !str.EndsWithByte(es, '}') {
+		return fmt.Errorf("pgsqlx: invalid sparsevec %q", s)
+	}
+
+	dim, err := strconv.Atoi(ds)
+	if err != nil {
+		return fmt.Errorf("pgsqlx: invalid sparsevec %q: %w", s, err)
+	}
+
+	v := SparseVector{Dim: dim}
+	if es = es[1 : len(es)-1]; es != "" {
+		for _, e := range strings.Split(es, ",") {
+			is, fs, ok := str.CutByte(e, ':')
+			if !ok {
+				return fmt.Errorf("pgsqlx: invalid sparsevec %q", s)
+			}
+
+			x, err := strconv.Atoi(is)
+			if err != nil {
+				return fmt.Errorf("pgsqlx: invalid sparsevec %q: %w", s, err)
+			}
+			if x < 1 || x > dim {
+				return fmt.Errorf("pgsqlx: invalid sparsevec %q: index %d out of range", s, x)
+			}
+
+			f, err := strconv.ParseFloat(fs, 32)
+			if err != nil {
+				return fmt.Errorf("pgsqlx: invalid sparsevec %q: %w", s, err)
+			}
+
+			v.Indices = append(v.Indices, x-1)
+			v.Values = append(v.Values, float32(f))
+		}
+	}
+
+	*sv = v
+	return nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (sv *SparseVector) Scan(src any) (err error) {
+	switch src := src.(type) {
+	case []byte:
+		return sv.Parse(string(src))
+	case string:
+		return sv.Parse(src)
+	case nil:
+		*sv = SparseVector{}
+		return nil
+	default:
+		return fmt.Errorf("pgsqlx: cannot convert %T to SparseVector", src)
+	}
+}
+
+// Value implements the driver.Valuer interface.
+func (sv SparseVector) Value() (driver.Value, error) {
+	if sv.Dim == 0 {
+		return nil, nil
+	}
+	return sv.String(), nil
+}
@@ -0,0 +1,50 @@
+package pgsqlx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHalfVector(t *testing.T) {
+	var v HalfVector
+	if err := v.Scan("[1,2.5,-3]"); err != nil {
+		t.Fatal(err)
+	}
+	if w := (HalfVector{1, 2.5, -3}); !reflect.DeepEqual(v, w) {
+		t.Errorf("Scan() = %v, want %v", v, w)
+	}
+	if s, _ := v.Value(); s != "[1,2.5,-3]" {
+		t.Errorf("Value() = %v", s)
+	}
+}
+
+func TestSparseVector(t *testing.T) {
+	var sv SparseVector
+	if err := sv.Scan("{1:1.5,3:2}/5"); err != nil {
+		t.Fatal(err)
+	}
+
+	w := SparseVector{Dim: 5, Indices: []int{0, 2}, Values: []float32{1.5, 2}}
+	if !reflect.DeepEqual(sv, w) {
+		t.Errorf("Scan() = %+v, want %+v", sv, w)
+	}
+	if a := sv.Slice(); !reflect.DeepEqual(a, []float32{1.5, 0, 2, 0, 0}) {
+		t.Errorf("Slice() = %v", a)
+	}
+	if a := NewSparseVector(sv.Slice()); !reflect.DeepEqual(a, w) {
+		t.Errorf("NewSparseVector() = %+v, want %+v", a, w)
+	}
+	if s, _ := sv.Value(); s != "{1:1.5,3:2}/5" {
+		t.Errorf("Value() = %v", s)
+	}
+
+	if err := sv.Scan("{}/3"); err != nil || sv.Dim != 3 || len(sv.Indices) != 0 {
+		t.Errorf("Scan(zero) = %+v, %v", sv, err)
+	}
+
+	for _, s := range []string{"{1:1}", "{0:1}/3", "{4:1}/3", "{1}/3", "[1,2]"} {
+		if err := sv.Scan(s); err == nil {
+			t.Errorf("Scan(%q) expected error", s)
+		}
+	}
+}
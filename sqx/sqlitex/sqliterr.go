@@ -0,0 +1,60 @@
+package sqlitex
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/askasoft/pango/sqx"
+	"github.com/askasoft/pango/str"
+	"github.com/mattn/go-sqlite3"
+)
+
+func init() {
+	sqx.RegisterErrorClassifier(ClassifyError)
+}
+
+// ClassifyError classifies the go-sqlite3 error to *sqx.DBError, returns nil if the error is not a sqlite3 error.
+// The table and column names are parsed from the error message (e.g. "UNIQUE constraint failed: users.name").
+func ClassifyError(err error) *sqx.DBError {
+	var sqErr sqlite3.Error
+	if !errors.As(err, &sqErr) {
+		return nil
+	}
+
+	de := &sqx.DBError{Code: strconv.Itoa(int(sqErr.ExtendedCode)), Err: err}
+
+	switch sqErr.Code {
+	case sqlite3.ErrConstraint:
+		switch sqErr.ExtendedCode {
+		case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+			de.Kind = sqx.ErrorUniqueViolation
+			de.Table, de.Column = parseColumn(err.Error())
+		case sqlite3.ErrConstraintForeignKey:
+			de.Kind = sqx.ErrorForeignKeyViolation
+		case sqlite3.ErrConstraintNotNull:
+			de.Kind = sqx.ErrorNotNullViolation
+			de.Table, de.Column = parseColumn(err.Error())
+		case sqlite3.ErrConstraintCheck:
+			de.Kind = sqx.ErrorCheckViolation
+			_, de.Constraint, _ = str.CutByte(err.Error(), ':')
+			de.Constraint = str.Strip(de.Constraint)
+		}
+	case sqlite3.ErrBusy, sqlite3.ErrLocked:
+		de.Kind = sqx.ErrorLockTimeout
+	case sqlite3.ErrInterrupt:
+		de.Kind = sqx.ErrorQueryCanceled
+	}
+	return de
+}
+
+// parseColumn parses the first "table.column" after ':' of the message
+func parseColumn(msg string) (table, column string) {
+	_, cols, ok := str.CutByte(msg, ':')
+	if !ok {
+		return
+	}
+
+	col, _, _ := str.CutByte(cols, ',')
+	table, column, _ = str.CutByte(str.Strip(col), '.')
+	return
+}
@@ -0,0 +1,58 @@
+package sqlitex
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/askasoft/pango/sqx"
+)
+
+func TestClassifyError(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "err.db")+"?_foreign_keys=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, s := range []string{
+		"CREATE TABLE parent (id INTEGER PRIMARY KEY)",
+		"CREATE TABLE child (id INTEGER PRIMARY KEY, name TEXT NOT NULL UNIQUE, age INTEGER CONSTRAINT chk_age CHECK (age > 0), pid INTEGER REFERENCES parent(id))",
+		"INSERT INTO child (id, name, age) VALUES (1, 'a', 1)",
+	} {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cs := []struct {
+		sql    string
+		kind   sqx.ErrorKind
+		table  string
+		column string
+		cons   string
+	}{
+		{"INSERT INTO child (id, name, age) VALUES (2, 'a', 1)", sqx.ErrorUniqueViolation, "child", "name", ""},
+		{"INSERT INTO child (id, name, age) VALUES (1, 'b', 1)", sqx.ErrorUniqueViolation, "child", "id", ""},
+		{"INSERT INTO child (id, name, age) VALUES (2, NULL, 1)", sqx.ErrorNotNullViolation, "child", "name", ""},
+		{"INSERT INTO child (id, name, age) VALUES (2, 'b', 0)", sqx.ErrorCheckViolation, "", "", "chk_age"},
+		{"INSERT INTO child (id, name, age, pid) VALUES (2, 'b', 1, 9)", sqx.ErrorForeignKeyViolation, "", "", ""},
+	}
+
+	for i, c := range cs {
+		_, err := db.Exec(c.sql)
+		de := sqx.ClassifyError(err)
+		if de == nil {
+			t.Fatalf("#%d %s: no error", i, c.sql)
+		}
+		if de.Kind != c.kind || de.Table != c.table || de.Column != c.column || de.Constraint != c.cons {
+			t.Errorf("#%d ClassifyError(%v) = %+v", i, err, de)
+		}
+	}
+
+	_, err = db.Exec("INSERT INTO child (id, name, age) VALUES (3, 'a', 1)")
+	if !errors.Is(sqx.ClassifyError(err), sqx.ErrUniqueViolation) || !sqx.IsUniqueViolation(err) {
+		t.Errorf("IsUniqueViolation(%v) = false", err)
+	}
+}
@@ -325,6 +325,25 @@ func (b *Builder) Limit(limit int) *Builder {
 	return b
 }
 
+// OrderBy add ORDER BY sorts
+func (b *Builder) OrderBy(sorts ...sqx.Sort) *Builder {
+	b.sqb.OrderBy(sorts...)
+	return b
+}
+
+// Seek add the keyset (seek) pagination condition which seeks the rows after the last row values by the sorts.
+// See sqx.Builder.Seek() for details.
+func (b *Builder) Seek(sorts []sqx.Sort, values ...any) *Builder {
+	b.sqb.Seek(sorts, values...)
+	return b
+}
+
+// Keyset add the keyset pagination condition (if values is not empty), ORDER BY sorts and LIMIT.
+func (b *Builder) Keyset(sorts []sqx.Sort, limit int, values ...any) *Builder {
+	b.sqb.Keyset(sorts, limit, values...)
+	return b
+}
+
 // StructSelect add columns for Struct.
 // Example:
 //
@@ -338,7 +357,7 @@ func (b *Builder) Limit(limit int) *Builder {
 func (b *Builder) StructSelect(a any, omits ...string) *Builder {
 	sm := b.mpr.TypeMap(reflect.TypeOf(a))
 	for _, fi := range sm.Index {
-		if isIgnoredField(fi, omits...) {
+		if IsIgnoredField(fi, omits...) {
 			continue
 		}
 		b.sqb.Select(fi.Name)
@@ -359,7 +378,7 @@ func (b *Builder) StructSelect(a any, omits ...string) *Builder {
 func (b *Builder) StructPrefixSelect(a any, prefix string, omits ...string) *Builder {
 	sm := b.mpr.TypeMap(reflect.TypeOf(a))
 	for _, fi := range sm.Index {
-		if isIgnoredField(fi, omits...) {
+		if IsIgnoredField(fi, omits...) {
 			continue
 		}
 		b.sqb.Select(prefix + fi.Name)
@@ -381,7 +400,7 @@ func (b *Builder) StructPrefixSelect(a any, prefix string, omits ...string) *Bui
 func (b *Builder) StructNames(a any, omits ...string) *Builder {
 	sm := b.mpr.TypeMap(reflect.TypeOf(a))
 	for _, fi := range sm.Index {
-		if isIgnoredField(fi, omits...) {
+		if IsIgnoredField(fi, omits...) {
 			continue
 		}
 		b.sqb.Name(fi.Name)
@@ -0,0 +1,337 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/askasoft/pango/sqx"
+	"github.com/askasoft/pango/str"
+)
+
+// Balance the replica load balancing strategy
+type Balance int
+
+const (
+	BalanceRoundRobin Balance = iota // round-robin
+	BalanceLeastConn                 // least in-use connections
+)
+
+type primaryKey struct{}
+
+// WithPrimary returns a context which forces the read statements of the Cluster to use the primary,
+// for read-after-write consistency.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// IsPrimary returns true if the context forces to use the primary
+func IsPrimary(ctx context.Context) bool {
+	v, _ := ctx.Value(primaryKey{}).(bool)
+	return v
+}
+
+type replica struct {
+	db      *DB
+	healthy atomic.Bool
+}
+
+// Cluster is a primary *DB with read replicas.
+// The write statements, prepared statements and transactions are executed on the primary (the embedded *DB),
+// the read only SELECT statements (Query, Select, Get...) are executed on a healthy replica.
+// If no replica is healthy or the context is created by WithPrimary(), the primary is used.
+type Cluster struct {
+	*DB
+
+	// Balance the replica load balancing strategy
+	Balance Balance
+
+	// ProbeTimeout the timeout of the health probe of a replica
+	ProbeTimeout time.Duration
+
+	// Lag the replica lag hook, returns the replication lag of the replica.
+	// A replica is unhealthy if the lag exceeds MaxLag.
+	Lag    func(ctx context.Context, db *DB) (time.Duration, error)
+	MaxLag time.Duration
+
+	// OnProbe the probe result hook, err is nil if the replica is healthy.
+	OnProbe func(db *DB, err error)
+
+	replicas []*replica
+	next     atomic.Uint64
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewCluster returns a Cluster with the primary and the replicas.
+// All replicas are assumed to be healthy until probed.
+func NewCluster(primary *DB, replicas ...*DB) *Cluster {
+	c := &Cluster{DB: primary, ProbeTimeout: 5 * time.Second}
+	for _, db := range replicas {
+		r := &replica{db: db}
+		r.healthy.Store(true)
+		c.replicas = append(c.replicas, r)
+	}
+	return c
+}
+
+// Primary returns the primary *DB
+func (c *Cluster) Primary() *DB {
+	return c.DB
+}
+
+// Replicas returns all the replica *DBs
+func (c *Cluster) Replicas() []*DB {
+	dbs := make([]*DB, len(c.replicas))
+	for i, r := range c.replicas {
+		dbs[i] = r.db
+	}
+	return dbs
+}
+
+// Reader returns the *DB for the read statements of the context.
+// Returns the primary if no replica is healthy, the context is created by WithPrimary()
+// or the context carries a transaction.
+func (c *Cluster) Reader(ctx context.Context) *DB {
+	if IsPrimary(ctx) {
+		return c.DB
+	}
+	if _, ok := TxFromContext(ctx); ok {
+		return c.DB
+	}
+
+	switch c.Balance {
+	case BalanceLeastConn:
+		var sel *DB
+		var least int
+		for _, r := range c.replicas {
+			if r.healthy.Load() {
+				if n := r.db.Stats().InUse; sel == nil || n < least {
+					sel, least = r.db, n
+				}
+			}
+		}
+		if sel != nil {
+			return sel
+		}
+	default:
+		n := uint64(len(c.replicas))
+		if n > 0 {
+			i := c.next.Add(1)
+			for j := uint64(0); j < n; j++ {
+				if r := c.replicas[(i+j)%n]; r.healthy.Load() {
+					return r.db
+				}
+			}
+		}
+	}
+	return c.DB
+}
+
+// querier is the common query interface of *DB and *Tx.
+type querier interface {
+	Sqlx
+	sqx.RowQueryer
+	sqx.ContextRowQueryer
+}
+
+// reLockingRead matches the locking clause of a SELECT statement.
+var reLockingRead = regexp.MustCompile(`(?i)\bFOR\s+(UPDATE|SHARE)\b|\bLOCK\s+IN\s+SHARE\s+MODE\b`)
+
+// route returns the transaction if the context carries a transaction,
+// returns the primary if the query is not a read only SELECT statement, otherwise returns Reader(ctx).
+func (c *Cluster) route(ctx context.Context, query string) querier {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+
+	q := str.StripLeft(query)
+	if !str.StartsWithFold(q, "SELECT") || reLockingRead.MatchString(q) {
+		return c.DB
+	}
+	return c.Reader(ctx)
+}
+
+// Probe checks the health of the replicas by PingContext() and the Lag hook.
+func (c *Cluster) Probe(ctx context.Context) {
+	for _, r := range c.replicas {
+		err := c.probe(ctx, r.db)
+		r.healthy.Store(err == nil)
+		if c.OnProbe != nil {
+			c.OnProbe(r.db, err)
+		}
+	}
+}
+
+func (c *Cluster) probe(ctx context.Context, db *DB) error {
+	if c.ProbeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.ProbeTimeout)
+		defer cancel()
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		return err
+	}
+
+	if c.Lag != nil && c.MaxLag > 0 {
+		lag, err := c.Lag(ctx, db)
+		if err != nil {
+			return err
+		}
+		if lag > c.MaxLag {
+			return fmt.Errorf("sqlx: replica lag %s exceeds %s", lag, c.MaxLag)
+		}
+	}
+	return nil
+}
+
+// StartProbe starts a goroutine to probe the replicas every interval until StopProbe() or Close() is called.
+func (c *Cluster) StartProbe(interval time.Duration) {
+	c.StopProbe()
+
+	stop := make(chan struct{})
+	c.stop = stop
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-t.C:
+				c.Probe(context.Background())
+			}
+		}
+	}()
+}
+
+// StopProbe stops the probe goroutine
+func (c *Cluster) StopProbe() {
+	if c.stop != nil {
+		close(c.stop)
+		c.wg.Wait()
+		c.stop = nil
+	}
+}
+
+// Close stops the probe goroutine, closes the primary and all replicas.
+func (c *Cluster) Close() error {
+	c.StopProbe()
+
+	errs := []error{c.DB.Close()}
+	for _, r := range c.replicas {
+		errs = append(errs, r.db.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Query executes a query on a replica.
+func (c *Cluster) Query(query string, args ...any) (*sql.Rows, error) {
+	return c.route(context.Background(), query).Query(query, args...)
+}
+
+// QueryContext executes a query on a replica.
+func (c *Cluster) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return c.route(ctx, query).QueryContext(ctx, query, args...)
+}
+
+// QueryRow executes a query that is expected to return at most one row on a replica.
+func (c *Cluster) QueryRow(query string, args ...any) *sql.Row {
+	return c.route(context.Background(), query).QueryRow(query, args...)
+}
+
+// QueryRowContext executes a query that is expected to return at most one row on a replica.
+func (c *Cluster) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return c.route(ctx, query).QueryRowContext(ctx, query, args...)
+}
+
+// Queryx queries a replica and returns an *sqlx.Rows.
+func (c *Cluster) Queryx(query string, args ...any) (*Rows, error) {
+	return c.route(context.Background(), query).Queryx(query, args...)
+}
+
+// QueryxContext queries a replica and returns an *sqlx.Rows.
+func (c *Cluster) QueryxContext(ctx context.Context, query string, args ...any) (*Rows, error) {
+	return c.route(ctx, query).QueryxContext(ctx, query, args...)
+}
+
+// QueryRowx queries a replica and returns an *sqlx.Row.
+func (c *Cluster) QueryRowx(query string, args ...any) *Row {
+	return c.route(context.Background(), query).QueryRowx(query, args...)
+}
+
+// QueryRowxContext queries a replica and returns an *sqlx.Row.
+func (c *Cluster) QueryRowxContext(ctx context.Context, query string, args ...any) *Row {
+	return c.route(ctx, query).QueryRowxContext(ctx, query, args...)
+}
+
+// NamedQuery using a replica.
+func (c *Cluster) NamedQuery(query string, arg any) (*Rows, error) {
+	return c.route(context.Background(), query).NamedQuery(query, arg)
+}
+
+// NamedQueryContext using a replica.
+func (c *Cluster) NamedQueryContext(ctx context.Context, query string, arg any) (*Rows, error) {
+	return c.route(ctx, query).NamedQueryContext(ctx, query, arg)
+}
+
+// NamedQueryRow using a replica.
+func (c *Cluster) NamedQueryRow(query string, arg any) *Row {
+	return c.route(context.Background(), query).NamedQueryRow(query, arg)
+}
+
+// NamedQueryRowContext using a replica.
+func (c *Cluster) NamedQueryRowContext(ctx context.Context, query string, arg any) *Row {
+	return c.route(ctx, query).NamedQueryRowContext(ctx, query, arg)
+}
+
+// Select using a replica.
+func (c *Cluster) Select(dest any, query string, args ...any) error {
+	return c.route(context.Background(), query).Select(dest, query, args...)
+}
+
+// NamedSelect using a replica.
+func (c *Cluster) NamedSelect(dest any, query string, arg any) error {
+	return c.route(context.Background(), query).NamedSelect(dest, query, arg)
+}
+
+// SelectContext using a replica.
+func (c *Cluster) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	return c.route(ctx, query).SelectContext(ctx, dest, query, args...)
+}
+
+// NamedSelectContext using a replica.
+func (c *Cluster) NamedSelectContext(ctx context.Context, dest any, query string, arg any) error {
+	return c.route(ctx, query).NamedSelectContext(ctx, dest, query, arg)
+}
+
+// Get using a replica.
+func (c *Cluster) Get(dest any, query string, args ...any) error {
+	return c.route(context.Background(), query).Get(dest, query, args...)
+}
+
+// NamedGet using a replica.
+func (c *Cluster) NamedGet(dest any, query string, arg any) error {
+	return c.route(context.Background(), query).NamedGet(dest, query, arg)
+}
+
+// GetContext using a replica.
+func (c *Cluster) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	return c.route(ctx, query).GetContext(ctx, dest, query, args...)
+}
+
+// NamedGetContext using a replica.
+func (c *Cluster) NamedGetContext(ctx context.Context, dest any, query string, arg any) error {
+	return c.route(ctx, query).NamedGetContext(ctx, dest, query, arg)
+}
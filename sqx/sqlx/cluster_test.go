@@ -0,0 +1,185 @@
+package sqlx
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openClusterDB(t *testing.T, name string) *DB {
+	db, err := Open("sqlite3", filepath.Join(t.TempDir(), name+".db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec("CREATE TABLE node (name TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO node (name) VALUES (?)", name); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestClusterRouting(t *testing.T) {
+	ctx := context.Background()
+
+	c := NewCluster(openClusterDB(t, "primary"), openClusterDB(t, "replica1"), openClusterDB(t, "replica2"))
+	defer c.Close()
+
+	seen := map[string]int{}
+	for i := 0; i < 4; i++ {
+		var name string
+		if err := c.GetContext(ctx, &name, "SELECT name FROM node"); err != nil {
+			t.Fatal(err)
+		}
+		seen[name]++
+	}
+	if seen["replica1"] != 2 || seen["replica2"] != 2 {
+		t.Errorf("round-robin reads = %v", seen)
+	}
+
+	var name string
+	if err := c.GetContext(WithPrimary(ctx), &name, "SELECT name FROM node"); err != nil {
+		t.Fatal(err)
+	}
+	if name != "primary" {
+		t.Errorf("WithPrimary() read = %q, want %q", name, "primary")
+	}
+
+	if _, err := c.ExecContext(ctx, "INSERT INTO node (name) VALUES (?)", "written"); err != nil {
+		t.Fatal(err)
+	}
+	var cnt int
+	if err := c.Primary().Get(&cnt, "SELECT COUNT(*) FROM node WHERE name = 'written'"); err != nil {
+		t.Fatal(err)
+	}
+	if cnt != 1 {
+		t.Errorf("write to primary count = %d, want 1", cnt)
+	}
+
+	err := c.Transactionx(ctx, nil, func(tx *Tx) error {
+		return tx.GetContext(ctx, &name, "SELECT name FROM node LIMIT 1")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "primary" {
+		t.Errorf("transaction read = %q, want %q", name, "primary")
+	}
+
+	c.Balance = BalanceLeastConn
+	if err := c.Get(&name, "SELECT name FROM node"); err != nil {
+		t.Fatal(err)
+	}
+	if name == "primary" {
+		t.Errorf("least-conn read = %q, want replica", name)
+	}
+}
+
+func TestClusterProbe(t *testing.T) {
+	ctx := context.Background()
+
+	primary, r1, r2 := openClusterDB(t, "primary"), openClusterDB(t, "replica1"), openClusterDB(t, "replica2")
+	c := NewCluster(primary, r1, r2)
+	defer c.Close()
+
+	c.MaxLag = time.Second
+	c.Lag = func(ctx context.Context, db *DB) (time.Duration, error) {
+		if db == r2 {
+			return time.Minute, nil
+		}
+		return 0, nil
+	}
+
+	var probed []error
+	c.OnProbe = func(db *DB, err error) {
+		probed = append(probed, err)
+	}
+
+	c.Probe(ctx)
+	if len(probed) != 2 || probed[0] != nil || probed[1] == nil {
+		t.Fatalf("probe results = %v", probed)
+	}
+
+	for i := 0; i < 3; i++ {
+		if db := c.Reader(ctx); db != r1 {
+			t.Errorf("#%d Reader() is not replica1", i)
+		}
+	}
+
+	r1.Close()
+	c.Probe(ctx)
+	if db := c.Reader(ctx); db != primary {
+		t.Error("Reader() should fallback to primary when no replica is healthy")
+	}
+
+	c.StartProbe(time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	c.StopProbe()
+}
+
+var _ Sqlx = (*Cluster)(nil)
+
+func TestClusterRouteLockingRead(t *testing.T) {
+	ctx := context.Background()
+
+	c := NewCluster(openClusterDB(t, "primary"), openClusterDB(t, "replica1"))
+	defer c.Close()
+
+	cs := []struct {
+		q string
+		w bool
+	}{
+		{"SELECT * FROM node", false},
+		{"  select for_update, share_mode FROM node", false},
+		{"SELECT * FROM node FOR UPDATE", true},
+		{"SELECT * FROM node\nFOR\tUPDATE", true},
+		{"select * from node for share", true},
+		{"SELECT * FROM node FOR UPDATE NOWAIT", true},
+		{"SELECT * FROM node LOCK IN SHARE MODE", true},
+		{"SELECT * FROM node\nlock  in  share\tmode", true},
+		{"UPDATE node SET name = 'x'", true},
+	}
+
+	for i, cc := range cs {
+		primary := c.route(ctx, cc.q) == c.Primary()
+		if primary != cc.w {
+			t.Errorf("#%d route(%q) primary = %v, want %v", i, cc.q, primary, cc.w)
+		}
+	}
+}
+
+func TestClusterRouteTx(t *testing.T) {
+	ctx := context.Background()
+
+	c := NewCluster(openClusterDB(t, "primary"), openClusterDB(t, "replica1"))
+	defer c.Close()
+
+	tx, err := c.BeginTxx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO node (name) VALUES (?)", "uncommitted"); err != nil {
+		t.Fatal(err)
+	}
+
+	tctx := NewTxContext(ctx, tx)
+	if db := c.Reader(tctx); db != c.Primary() {
+		t.Error("Reader() should return the primary in a transaction")
+	}
+	if q := c.route(tctx, "SELECT name FROM node"); q != tx {
+		t.Error("route() should return the transaction of the context")
+	}
+
+	var cnt int
+	if err := c.GetContext(tctx, &cnt, "SELECT COUNT(*) FROM node WHERE name = 'uncommitted'"); err != nil {
+		t.Fatal(err)
+	}
+	if cnt != 1 {
+		t.Errorf("transaction read count = %d, want 1", cnt)
+	}
+}
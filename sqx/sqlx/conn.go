@@ -3,6 +3,8 @@ package sqlx
 import (
 	"context"
 	"database/sql"
+
+	"github.com/askasoft/pango/ret"
 )
 
 // Conn is a wrapper around sql.Conn with extra functionality
@@ -186,6 +188,18 @@ func (c *Conn) Transactionx(ctx context.Context, opts *sql.TxOptions, fc func(tx
 	return Transactionx(ctx, c, opts, fc)
 }
 
+// TransactionContext start a transaction as a block, or a nested transaction if ctx carries a transaction.
+// See sqlx.TransactionContext() for details.
+func (c *Conn) TransactionContext(ctx context.Context, opts *sql.TxOptions, fc func(ctx context.Context, tx *Tx) error) error {
+	return TransactionContext(ctx, c, opts, fc)
+}
+
+// TransactionRetry start a transaction as a block, and retries the transaction by the retryer.
+// See sqlx.TransactionRetry() for details.
+func (c *Conn) TransactionRetry(ctx context.Context, opts *sql.TxOptions, r *ret.Retryer, fc func(ctx context.Context, tx *Tx) error) error {
+	return TransactionRetry(ctx, c, opts, r, fc)
+}
+
 // Queryx queries the database and returns an *sqlx.Rows.
 // Any placeholder parameters are replaced with supplied args.
 func (c *Conn) Queryx(query string, args ...any) (*Rows, error) {
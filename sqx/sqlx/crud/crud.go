@@ -0,0 +1,371 @@
+// Package crud provides the struct driven CRUD helpers for sqlx.
+//
+//	type User struct {
+//		ID        int64     `db:"id,pk,auto"`
+//		Name      string    `db:"name"`
+//		Note      string    `db:"note,omitempty"`
+//		CreatedAt time.Time `db:"created_at,readonly"`
+//	}
+//
+//	u := &User{Name: "bob"}
+//	err := crud.Insert(ctx, db, u) // u.ID is set
+//
+// The table name is the snake case of the struct type name, or the result of TableName() if the struct implements Tabler.
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/askasoft/pango/ref"
+	"github.com/askasoft/pango/sqx"
+	"github.com/askasoft/pango/sqx/sqlx"
+	"github.com/askasoft/pango/str"
+)
+
+// Tabler is the interface implemented by the struct that specify the table name for the CRUD helpers.
+type Tabler interface {
+	TableName() string
+}
+
+// TableName returns the table name of the struct a.
+// Returns a.TableName() if a implements Tabler, otherwise the snake case of the struct type name.
+func TableName(a any) string {
+	if t, ok := a.(Tabler); ok {
+		return t.TableName()
+	}
+	return str.SnakeCase(ref.DerefType(reflect.TypeOf(a)).Name())
+}
+
+// crudField a mapped column of the struct for the CRUD helpers.
+// Supported tag options:
+//
+//	pk        primary key
+//	auto      auto generated value (auto increment or default), omitted on insert if zero
+//	omitempty omitted on insert/update if zero
+//	readonly  omitted on insert/update
+type crudField struct {
+	name      string
+	value     reflect.Value
+	pk        bool
+	auto      bool
+	omitempty bool
+	readonly  bool
+}
+
+func (cf *crudField) isZero() bool {
+	return cf.value.IsZero()
+}
+
+type crudStruct struct {
+	table  string
+	fields []*crudField
+}
+
+func (cs *crudStruct) pks() (fs []*crudField) {
+	for _, f := range cs.fields {
+		if f.pk {
+			fs = append(fs, f)
+		}
+	}
+	return
+}
+
+func (cs *crudStruct) auto() *crudField {
+	for _, f := range cs.fields {
+		if f.auto {
+			return f
+		}
+	}
+	return nil
+}
+
+func newCrudStruct(mpr *ref.Mapper, a any) (*crudStruct, error) {
+	rv := reflect.ValueOf(a)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil, fmt.Errorf("crud: expected a non-nil pointer to struct, but got %T", a)
+	}
+
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("crud: expected a pointer to struct, but got %T", a)
+	}
+
+	cs := &crudStruct{table: TableName(a)}
+
+	sm := mpr.TypeMap(rv.Type())
+	for _, fi := range sm.Index {
+		if sqlx.IsIgnoredField(fi) {
+			continue
+		}
+
+		_, pk := fi.Options["pk"]
+		_, auto := fi.Options["auto"]
+		_, omitempty := fi.Options["omitempty"]
+		_, readonly := fi.Options["readonly"]
+
+		cs.fields = append(cs.fields, &crudField{
+			name:      fi.Name,
+			value:     ref.FieldByIndexes(rv, fi.Index),
+			pk:        pk,
+			auto:      auto,
+			omitempty: omitempty,
+			readonly:  readonly,
+		})
+	}
+
+	return cs, nil
+}
+
+// Sqlx the interface for the CRUD helpers (sqlx.DB, sqlx.Tx, sqlx.Conn)
+type Sqlx interface {
+	sqlx.Sqlx
+
+	DriverName() string
+	Mapper() *ref.Mapper
+}
+
+func crudStructOf(x Sqlx, a any) (*crudStruct, error) {
+	return newCrudStruct(x.Mapper(), a)
+}
+
+var ErrNoPrimaryKey = errors.New("crud: no primary key field")
+
+func wherePks(b *sqlx.Builder, cs *crudStruct) error {
+	pks := cs.pks()
+	if len(pks) == 0 {
+		return ErrNoPrimaryKey
+	}
+
+	for _, f := range pks {
+		b.Eq(f.name, f.value.Interface())
+	}
+	return nil
+}
+
+func (cs *crudStruct) insertFields(auto bool) (fs []*crudField) {
+	for _, f := range cs.fields {
+		if f.readonly || (f.omitempty && f.isZero()) {
+			continue
+		}
+		if f.auto && (!auto || f.isZero()) {
+			continue
+		}
+		fs = append(fs, f)
+	}
+	return
+}
+
+func (cs *crudStruct) updateFields() (fs []*crudField) {
+	for _, f := range cs.fields {
+		if f.pk || f.auto || f.readonly || (f.omitempty && f.isZero()) {
+			continue
+		}
+		fs = append(fs, f)
+	}
+	return
+}
+
+// Insert inserts the struct row (a pointer to struct) into the table.
+// The "auto" field (e.g. `db:"id,pk,auto"`) is omitted if it is zero,
+// and is set to the generated value by "RETURNING" (PostgreSQL) or LastInsertId() (MySQL, SQLite).
+func Insert(ctx context.Context, x Sqlx, row any) error {
+	cs, err := crudStructOf(x, row)
+	if err != nil {
+		return err
+	}
+
+	b := x.Builder()
+	b.Insert(cs.table)
+	for _, f := range cs.insertFields(true) {
+		b.Setc(f.name, f.value.Interface())
+	}
+
+	af := cs.auto()
+	if af == nil || !af.isZero() {
+		sql, args := b.Build()
+		_, err := x.UpdateContext(ctx, sql, args...)
+		return err
+	}
+
+	if x.SupportLastInsertID() {
+		sql, args := b.Build()
+		r, err := x.ExecContext(ctx, sql, args...)
+		if err != nil {
+			return err
+		}
+
+		id, err := r.LastInsertId()
+		if err != nil {
+			return err
+		}
+		return setAutoValue(af, id)
+	}
+
+	b.Returns(af.name)
+	sql, args := b.Build()
+	return x.QueryRowxContext(ctx, sql, args...).Scan(af.value.Addr().Interface())
+}
+
+func setAutoValue(f *crudField, id int64) error {
+	switch f.value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f.value.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f.value.SetUint(uint64(id)) //nolint: gosec
+	default:
+		return fmt.Errorf("crud: unsupported auto field type %v of %q", f.value.Type(), f.name)
+	}
+	return nil
+}
+
+// Update updates the columns of the struct row (a pointer to struct) by the primary key.
+// The "pk", "auto", "readonly" fields and the zero "omitempty" fields are not updated.
+// Returns the affected row count.
+func Update(ctx context.Context, x Sqlx, row any) (int64, error) {
+	cs, err := crudStructOf(x, row)
+	if err != nil {
+		return 0, err
+	}
+
+	fs := cs.updateFields()
+	if len(fs) == 0 {
+		return 0, fmt.Errorf("crud: no column to update of %q", cs.table)
+	}
+
+	b := x.Builder()
+	b.Update(cs.table)
+	for _, f := range fs {
+		b.Setc(f.name, f.value.Interface())
+	}
+	if err := wherePks(b, cs); err != nil {
+		return 0, err
+	}
+
+	sql, args := b.Build()
+	return x.UpdateContext(ctx, sql, args...)
+}
+
+// Upsert inserts the struct row (a pointer to struct), or updates the columns if the primary key is conflicted.
+// It uses "ON CONFLICT ... DO UPDATE" (PostgreSQL, SQLite) or "ON DUPLICATE KEY UPDATE" (MySQL),
+// returns a error for SQL Server and Oracle.
+// If the "auto" primary key is zero, Upsert is the same as Insert.
+func Upsert(ctx context.Context, x Sqlx, row any) error {
+	dialect := sqx.GetDialect(x.DriverName())
+	if dialect == sqx.DialectSQLServer || dialect == sqx.DialectOracle {
+		return fmt.Errorf("crud: upsert is not supported by %q", x.DriverName())
+	}
+
+	cs, err := crudStructOf(x, row)
+	if err != nil {
+		return err
+	}
+
+	pks := cs.pks()
+	if len(pks) == 0 {
+		return ErrNoPrimaryKey
+	}
+
+	if af := cs.auto(); af != nil && af.pk && af.isZero() {
+		return Insert(ctx, x, row)
+	}
+
+	b := x.Builder()
+	b.Insert(cs.table)
+	for _, f := range cs.insertFields(true) {
+		b.Setc(f.name, f.value.Interface())
+	}
+
+	sb := &strings.Builder{}
+	sql, args := b.Build()
+	sb.WriteString(sql)
+
+	mysql := dialect == sqx.DialectMySQL
+
+	if mysql {
+		sb.WriteString(" ON DUPLICATE KEY UPDATE ")
+	} else {
+		sb.WriteString(" ON CONFLICT (")
+		for i, f := range pks {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(b.Quote(f.name))
+		}
+		sb.WriteString(")")
+	}
+
+	fs := cs.updateFields()
+	if len(fs) == 0 {
+		if mysql {
+			// no-op update
+			sb.WriteString(b.Quote(pks[0].name))
+			sb.WriteString(" = ")
+			sb.WriteString(b.Quote(pks[0].name))
+		} else {
+			sb.WriteString(" DO NOTHING")
+		}
+	} else {
+		if !mysql {
+			sb.WriteString(" DO UPDATE SET ")
+		}
+		for i, f := range fs {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+
+			col := b.Quote(f.name)
+			sb.WriteString(col)
+			if mysql {
+				sb.WriteString(" = VALUES(" + col + ")")
+			} else {
+				sb.WriteString(" = EXCLUDED." + col)
+			}
+		}
+	}
+
+	_, err = x.UpdateContext(ctx, sb.String(), args...)
+	return err
+}
+
+// Delete deletes the struct row (a pointer to struct) by the primary key.
+// Returns the affected row count.
+func Delete(ctx context.Context, x Sqlx, row any) (int64, error) {
+	cs, err := crudStructOf(x, row)
+	if err != nil {
+		return 0, err
+	}
+
+	b := x.Builder()
+	b.Delete(cs.table)
+	if err := wherePks(b, cs); err != nil {
+		return 0, err
+	}
+
+	sql, args := b.Build()
+	return x.UpdateContext(ctx, sql, args...)
+}
+
+// Get selects the struct row (a pointer to struct) by the primary key which is set in the row.
+// Returns ErrNoRows if not found.
+func Get(ctx context.Context, x Sqlx, row any) error {
+	cs, err := crudStructOf(x, row)
+	if err != nil {
+		return err
+	}
+
+	b := x.Builder()
+	for _, f := range cs.fields {
+		b.Select(f.name)
+	}
+	b.From(cs.table)
+	if err := wherePks(b, cs); err != nil {
+		return err
+	}
+
+	sql, args := b.Build()
+	return x.QueryRowxContext(ctx, sql, args...).StructScan(row)
+}
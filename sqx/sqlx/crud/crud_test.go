@@ -0,0 +1,188 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/askasoft/pango/sqx/sqlx"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type testBase struct {
+	CreatedAt time.Time `db:"created_at,readonly"`
+}
+
+type testUser struct {
+	testBase
+
+	ID    int64  `db:"id,pk,auto"`
+	Name  string `db:"name"`
+	Email string `db:"email,omitempty"`
+	Age   int    `db:"age"`
+}
+
+type testSetting struct {
+	Key   string `db:"key,pk"`
+	Value string `db:"value"`
+}
+
+func (testSetting) TableName() string {
+	return "settings"
+}
+
+func testOpenDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("sqlite3", filepath.Join(t.TempDir(), "crud.db3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+CREATE TABLE test_user (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	email TEXT NOT NULL DEFAULT 'none',
+	age INTEGER NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE settings (
+	key TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestTableName(t *testing.T) {
+	if a := TableName(&testUser{}); a != "test_user" {
+		t.Errorf("TableName(testUser) = %q", a)
+	}
+	if a := TableName(testSetting{}); a != "settings" {
+		t.Errorf("TableName(testSetting) = %q", a)
+	}
+}
+
+func TestCRUD(t *testing.T) {
+	ctx := context.Background()
+	db := testOpenDB(t)
+
+	u1 := &testUser{Name: "a", Age: 10}
+	if err := Insert(ctx, db, u1); err != nil {
+		t.Fatal(err)
+	}
+	u2 := &testUser{Name: "b", Email: "b@x.com", Age: 20}
+	if err := Insert(ctx, db, u2); err != nil {
+		t.Fatal(err)
+	}
+	if u1.ID != 1 || u2.ID != 2 {
+		t.Fatalf("Insert() ID = %d, %d", u1.ID, u2.ID)
+	}
+
+	g := &testUser{ID: 1}
+	if err := Get(ctx, db, g); err != nil {
+		t.Fatal(err)
+	}
+	if g.Name != "a" || g.Email != "none" || g.Age != 10 || g.CreatedAt.IsZero() {
+		t.Errorf("Get() = %+v", g)
+	}
+
+	g.Name, g.Age, g.Email = "aa", 11, ""
+	if n, err := Update(ctx, db, g); err != nil || n != 1 {
+		t.Fatalf("Update() = %d, %v", n, err)
+	}
+
+	g = &testUser{ID: 1}
+	if err := Get(ctx, db, g); err != nil {
+		t.Fatal(err)
+	}
+	if g.Name != "aa" || g.Email != "none" || g.Age != 11 {
+		t.Errorf("Get() after Update = %+v", g)
+	}
+
+	// Upsert with existing primary key
+	if err := Upsert(ctx, db, &testUser{ID: 2, Name: "bb", Age: 21}); err != nil {
+		t.Fatal(err)
+	}
+	g = &testUser{ID: 2}
+	if err := Get(ctx, db, g); err != nil {
+		t.Fatal(err)
+	}
+	if g.Name != "bb" || g.Email != "b@x.com" || g.Age != 21 {
+		t.Errorf("Get() after Upsert = %+v", g)
+	}
+
+	// Upsert with zero auto primary key
+	u3 := &testUser{Name: "c", Age: 30}
+	if err := Upsert(ctx, db, u3); err != nil {
+		t.Fatal(err)
+	}
+	if u3.ID != 3 {
+		t.Errorf("Upsert() ID = %d", u3.ID)
+	}
+
+	if n, err := Delete(ctx, db, &testUser{ID: 2}); err != nil || n != 1 {
+		t.Fatalf("Delete() = %d, %v", n, err)
+	}
+	if err := Get(ctx, db, &testUser{ID: 2}); !errors.Is(err, sqlx.ErrNoRows) {
+		t.Errorf("Get() after Delete = %v", err)
+	}
+}
+
+func TestCRUDTx(t *testing.T) {
+	ctx := context.Background()
+	db := testOpenDB(t)
+
+	err := db.Transactionx(ctx, nil, func(tx *sqlx.Tx) error {
+		if err := Insert(ctx, tx, &testSetting{Key: "k", Value: "v1"}); err != nil {
+			return err
+		}
+		return Upsert(ctx, tx, &testSetting{Key: "k", Value: "v2"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &testSetting{Key: "k"}
+	if err := Get(ctx, db, s); err != nil || s.Value != "v2" {
+		t.Errorf("Get() = %+v, %v", s, err)
+	}
+}
+
+func TestCRUDErrors(t *testing.T) {
+	ctx := context.Background()
+	db := testOpenDB(t)
+
+	type noPK struct {
+		Name string `db:"name"`
+	}
+
+	if _, err := Delete(ctx, db, &noPK{}); !errors.Is(err, ErrNoPrimaryKey) {
+		t.Errorf("Delete(noPK) = %v", err)
+	}
+	if err := Insert(ctx, db, testUser{}); err == nil {
+		t.Error("Insert(struct) should return error")
+	}
+
+	for _, dn := range []string{"sqlserver", "godror"} {
+		if err := Upsert(ctx, testDriverDB{db, dn}, &testSetting{Key: "k", Value: "v"}); err == nil {
+			t.Errorf("Upsert(%s) should return error", dn)
+		}
+	}
+}
+
+// testDriverDB overrides the driver name of the DB
+type testDriverDB struct {
+	*sqlx.DB
+	name string
+}
+
+func (tdb testDriverDB) DriverName() string {
+	return tdb.name
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/askasoft/pango/ref"
+	"github.com/askasoft/pango/ret"
 	"github.com/askasoft/pango/sqx"
 )
 
@@ -190,6 +191,14 @@ func (db *DB) MapperFunc(mf func(string) string) {
 	db.mapper = ref.NewMapperFunc("db", mf)
 }
 
+// SetContextTrace sets the context trace function for this db.
+// The Tx, Conn and Stmt created from this db after the call will inherit it.
+// It is not synchronized with the statements, so it must be called before the db is used.
+func (db *DB) SetContextTrace(ct ContextTrace) {
+	db.tracer.Bind = db.binder
+	db.tracer.ContextTrace = ct
+}
+
 // Unsafe returns a version of DB which will silently succeed to scan when
 // columns in the SQL result have no fields in the destination struct.
 // sqlx.Stmt and sqlx.Tx which are created from this DB will inherit its
@@ -471,6 +480,18 @@ func (db *DB) Transactionx(ctx context.Context, opts *sql.TxOptions, fc func(tx
 	return Transactionx(ctx, db, opts, fc)
 }
 
+// TransactionContext start a transaction as a block, or a nested transaction if ctx carries a transaction.
+// See sqlx.TransactionContext() for details.
+func (db *DB) TransactionContext(ctx context.Context, opts *sql.TxOptions, fc func(ctx context.Context, tx *Tx) error) error {
+	return TransactionContext(ctx, db, opts, fc)
+}
+
+// TransactionRetry start a transaction as a block, and retries the transaction by the retryer.
+// See sqlx.TransactionRetry() for details.
+func (db *DB) TransactionRetry(ctx context.Context, opts *sql.TxOptions, r *ret.Retryer, fc func(ctx context.Context, tx *Tx) error) error {
+	return TransactionRetry(ctx, db, opts, r, fc)
+}
+
 // MustBeginx starts a transaction, and panics on error.  Returns an *sqlx.Tx instead
 // of an *sql.Tx.
 func (db *DB) MustBeginx() *Tx {
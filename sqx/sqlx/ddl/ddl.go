@@ -0,0 +1,408 @@
+// Package ddl generates the dialect-aware DDL statements from the `db` tagged structs.
+//
+//	type User struct {
+//		ID        int64      `db:"id,pk,auto"`
+//		GroupID   int64      `db:"group_id,fk=groups.id"`
+//		Name      string     `db:"name,size=100,unique"`
+//		Email     *string    `db:"email,size=200,index=idx_user_email"`
+//		Status    string     `db:"status,size=1,default='A'"`
+//		Meta      string     `db:"meta,type=jsonb,null"`
+//		CreatedAt time.Time  `db:"created_at,index"`
+//	}
+//
+//	g := ddl.NewGenerator("postgres")
+//	sqls, err := g.CreateTable(&User{})
+//
+// Supported tag options (besides the crud options pk, auto):
+//
+//	type=T      column type, overrides the type inferred from the Go type
+//	size=N      string/bytes size, e.g. VARCHAR(N)
+//	null        nullable (pointer and sql.Null* types are nullable by default)
+//	notnull     not nullable
+//	default=V   default value (SQL literal)
+//	index[=N]   index, the columns with the same index name make a composite index
+//	unique[=N]  unique index, the columns with the same index name make a composite unique index
+//	fk=T.C      foreign key references table T column C
+//
+// The table name is the snake case of the struct type name, or the result of TableName() if the struct implements crud.Tabler.
+package ddl
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/askasoft/pango/num"
+	"github.com/askasoft/pango/ref"
+	"github.com/askasoft/pango/sqx"
+	"github.com/askasoft/pango/sqx/sqlx"
+	"github.com/askasoft/pango/sqx/sqlx/crud"
+	"github.com/askasoft/pango/str"
+)
+
+// Column a column definition
+type Column struct {
+	Name     string
+	Type     string
+	Nullable bool
+	Default  string
+	PK       bool
+	Auto     bool
+	FK       string // referenced "table.column"
+}
+
+// Index a index definition
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+// Table a table definition
+type Table struct {
+	Name    string
+	Columns []*Column
+	Indexes []*Index
+}
+
+// Column returns the column by name
+func (t *Table) Column(name string) *Column {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (t *Table) pks() (pks []string) {
+	for _, c := range t.Columns {
+		if c.PK {
+			pks = append(pks, c.Name)
+		}
+	}
+	return
+}
+
+// Generator generates the DDL statements of the driver dialect
+type Generator struct {
+	Quoter sqx.Quoter
+	Mapper *ref.Mapper
+
+	// DropColumns drops the columns which are not in the struct in Diff()
+	DropColumns bool
+
+	dialect sqx.Dialect
+}
+
+// NewGenerator create a Generator for the driver
+func NewGenerator(driverName string) *Generator {
+	return &Generator{
+		Quoter:  sqx.GetQuoter(driverName),
+		Mapper:  sqlx.NameMapper,
+		dialect: sqx.GetDialect(driverName),
+	}
+}
+
+// Table returns the table definition of the struct a
+func (g *Generator) Table(a any) (*Table, error) {
+	rt := ref.DerefType(reflect.TypeOf(a))
+	if rt.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("ddl: expected a struct, but got %T", a)
+	}
+
+	t := &Table{Name: crud.TableName(a)}
+
+	sm := g.Mapper.TypeMap(rt)
+	for _, fi := range sm.Index {
+		if sqlx.IsIgnoredField(fi) {
+			continue
+		}
+
+		c, err := g.column(fi)
+		if err != nil {
+			return nil, fmt.Errorf("ddl: %s.%s: %w", rt.Name(), fi.Path, err)
+		}
+		t.Columns = append(t.Columns, c)
+
+		for _, u := range []bool{false, true} {
+			key := str.If(u, "unique", "index")
+			if name, ok := fi.Options[key]; ok {
+				if name == "" {
+					name = str.If(u, "uq_", "idx_") + t.Name + "_" + c.Name
+				}
+				addIndex(t, name, c.Name, u)
+			}
+		}
+	}
+
+	if len(t.Columns) == 0 {
+		return nil, fmt.Errorf("ddl: no column in %T", a)
+	}
+	return t, nil
+}
+
+func addIndex(t *Table, name, column string, unique bool) {
+	for _, ix := range t.Indexes {
+		if ix.Name == name {
+			ix.Columns = append(ix.Columns, column)
+			return
+		}
+	}
+	t.Indexes = append(t.Indexes, &Index{Name: name, Columns: []string{column}, Unique: unique})
+}
+
+func (g *Generator) column(fi *ref.FieldInfo) (*Column, error) {
+	_, pk := fi.Options["pk"]
+	_, auto := fi.Options["auto"]
+	_, null := fi.Options["null"]
+	_, notnull := fi.Options["notnull"]
+
+	c := &Column{
+		Name:    fi.Name,
+		Default: fi.Options["default"],
+		PK:      pk,
+		Auto:    auto,
+		FK:      fi.Options["fk"],
+	}
+
+	ft := fi.Field.Type
+	if ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+		c.Nullable = true
+	}
+	if nt, ok := nullTypes[ft]; ok {
+		ft = nt
+		c.Nullable = true
+	}
+	if null {
+		c.Nullable = true
+	}
+	if notnull || pk {
+		c.Nullable = false
+	}
+
+	c.Type = fi.Options["type"]
+	if c.Type == "" {
+		c.Type = g.sqlType(ft, num.Atoi(fi.Options["size"]), auto)
+		if c.Type == "" {
+			return nil, fmt.Errorf("unsupported type %s, specify the column type by the 'type' option", fi.Field.Type)
+		}
+	}
+	return c, nil
+}
+
+var nullTypes = map[reflect.Type]reflect.Type{
+	reflect.TypeFor[sql.NullString]():  reflect.TypeFor[string](),
+	reflect.TypeFor[sql.NullBool]():    reflect.TypeFor[bool](),
+	reflect.TypeFor[sql.NullByte]():    reflect.TypeFor[byte](),
+	reflect.TypeFor[sql.NullInt16]():   reflect.TypeFor[int16](),
+	reflect.TypeFor[sql.NullInt32]():   reflect.TypeFor[int32](),
+	reflect.TypeFor[sql.NullInt64]():   reflect.TypeFor[int64](),
+	reflect.TypeFor[sql.NullFloat64](): reflect.TypeFor[float64](),
+	reflect.TypeFor[sql.NullTime]():    reflect.TypeFor[time.Time](),
+}
+
+func (g *Generator) sqlType(t reflect.Type, size int, auto bool) string {
+	if t == ref.TypeTime {
+		switch g.dialect {
+		case sqx.DialectPostgres:
+			return "TIMESTAMPTZ"
+		case sqx.DialectSQLServer:
+			return "DATETIME2"
+		default:
+			return "DATETIME"
+		}
+	}
+	if t == ref.TypeBytes {
+		switch g.dialect {
+		case sqx.DialectPostgres:
+			return "BYTEA"
+		case sqx.DialectMySQL:
+			return str.If(size > 0, "VARBINARY("+num.Itoa(size)+")", "LONGBLOB")
+		case sqx.DialectSQLServer:
+			return "VARBINARY(" + str.If(size > 0, num.Itoa(size), "MAX") + ")"
+		default:
+			return "BLOB"
+		}
+	}
+
+	if auto && g.dialect == sqx.DialectSQLite {
+		// INTEGER PRIMARY KEY is the alias of ROWID
+		return "INTEGER"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		switch g.dialect {
+		case sqx.DialectSQLServer:
+			return "BIT"
+		default:
+			return "BOOLEAN"
+		}
+	case reflect.Int8, reflect.Uint8, reflect.Int16:
+		return "SMALLINT"
+	case reflect.Uint16, reflect.Int32:
+		return "INTEGER"
+	case reflect.Int, reflect.Uint, reflect.Uint32, reflect.Int64, reflect.Uint64:
+		return "BIGINT"
+	case reflect.Float32:
+		return "REAL"
+	case reflect.Float64:
+		switch g.dialect {
+		case sqx.DialectMySQL:
+			return "DOUBLE"
+		case sqx.DialectSQLServer:
+			return "FLOAT"
+		default:
+			return "DOUBLE PRECISION"
+		}
+	case reflect.String:
+		switch g.dialect {
+		case sqx.DialectSQLServer:
+			return "NVARCHAR(" + str.If(size > 0, num.Itoa(size), "MAX") + ")"
+		default:
+			if size > 0 {
+				return "VARCHAR(" + num.Itoa(size) + ")"
+			}
+			return "TEXT"
+		}
+	default:
+		return ""
+	}
+}
+
+// columnDef returns the column definition of CREATE TABLE / ADD COLUMN
+func (g *Generator) columnDef(t *Table, c *Column) string {
+	sb := &strings.Builder{}
+
+	sb.WriteString(g.Quoter.Quote(c.Name))
+	sb.WriteByte(' ')
+	sb.WriteString(c.Type)
+
+	if c.Auto {
+		switch g.dialect {
+		case sqx.DialectPostgres:
+			sb.WriteString(" GENERATED BY DEFAULT AS IDENTITY")
+		case sqx.DialectMySQL:
+			sb.WriteString(" AUTO_INCREMENT")
+		case sqx.DialectSQLServer:
+			sb.WriteString(" IDENTITY(1,1)")
+		case sqx.DialectSQLite:
+			if g.inlinePK(t) {
+				sb.WriteString(" PRIMARY KEY AUTOINCREMENT")
+			}
+		}
+	}
+
+	if !c.Nullable {
+		sb.WriteString(" NOT NULL")
+	}
+	if c.Default != "" {
+		sb.WriteString(" DEFAULT ")
+		sb.WriteString(c.Default)
+	}
+	return sb.String()
+}
+
+// inlinePK returns true if the primary key is a single auto column of SQLite (INTEGER PRIMARY KEY AUTOINCREMENT)
+func (g *Generator) inlinePK(t *Table) bool {
+	if g.dialect != sqx.DialectSQLite {
+		return false
+	}
+
+	pks := t.pks()
+	if len(pks) != 1 {
+		return false
+	}
+	c := t.Column(pks[0])
+	return c.Auto
+}
+
+func (g *Generator) quotes(ss []string) string {
+	return str.Join(g.Quoter.Quotes(ss...), ", ")
+}
+
+func (g *Generator) foreignKey(t *Table, c *Column) (string, error) {
+	rt, rc, ok := str.LastCutByte(c.FK, '.')
+	if !ok || rt == "" || rc == "" {
+		return "", fmt.Errorf("ddl: invalid fk option %q of %s.%s, expected 'table.column'", c.FK, t.Name, c.Name)
+	}
+
+	return fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		g.Quoter.Quote("fk_"+t.Name+"_"+c.Name), g.Quoter.Quote(c.Name), g.Quoter.Quote(rt), g.Quoter.Quote(rc)), nil
+}
+
+// CreateTable returns the CREATE TABLE and CREATE INDEX statements of the struct a
+func (g *Generator) CreateTable(a any) ([]string, error) {
+	t, err := g.Table(a)
+	if err != nil {
+		return nil, err
+	}
+	return g.CreateTableSQL(t)
+}
+
+// CreateTableSQL returns the CREATE TABLE and CREATE INDEX statements of the table t
+func (g *Generator) CreateTableSQL(t *Table) ([]string, error) {
+	var defs []string
+
+	for _, c := range t.Columns {
+		defs = append(defs, g.columnDef(t, c))
+	}
+
+	if pks := t.pks(); len(pks) > 0 && !g.inlinePK(t) {
+		defs = append(defs, "PRIMARY KEY ("+g.quotes(pks)+")")
+	}
+
+	for _, c := range t.Columns {
+		if c.FK != "" {
+			fk, err := g.foreignKey(t, c)
+			if err != nil {
+				return nil, err
+			}
+			defs = append(defs, fk)
+		}
+	}
+
+	sqls := []string{"CREATE TABLE " + g.Quoter.Quote(t.Name) + " (\n\t" + str.Join(defs, ",\n\t") + "\n)"}
+	for _, ix := range t.Indexes {
+		sqls = append(sqls, g.CreateIndexSQL(t, ix))
+	}
+	return sqls, nil
+}
+
+// CreateIndexSQL returns the CREATE INDEX statement of the index ix
+func (g *Generator) CreateIndexSQL(t *Table, ix *Index) string {
+	return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)",
+		str.If(ix.Unique, "UNIQUE ", ""), g.Quoter.Quote(ix.Name), g.Quoter.Quote(t.Name), g.quotes(ix.Columns))
+}
+
+// AddColumnSQL returns the ALTER TABLE ADD COLUMN statement of the column c
+func (g *Generator) AddColumnSQL(t *Table, c *Column) string {
+	add := str.If(g.dialect == sqx.DialectSQLServer, " ADD ", " ADD COLUMN ")
+	return "ALTER TABLE " + g.Quoter.Quote(t.Name) + add + g.columnDef(t, c)
+}
+
+// DropColumnSQL returns the ALTER TABLE DROP COLUMN statement of the column
+func (g *Generator) DropColumnSQL(t *Table, column string) string {
+	return "ALTER TABLE " + g.Quoter.Quote(t.Name) + " DROP COLUMN " + g.Quoter.Quote(column)
+}
+
+// AlterNullSQL returns the ALTER TABLE statement to change the nullability of the column c.
+// Returns "" if the dialect does not support it (SQLite).
+func (g *Generator) AlterNullSQL(t *Table, c *Column) string {
+	tn, cn := g.Quoter.Quote(t.Name), g.Quoter.Quote(c.Name)
+
+	switch g.dialect {
+	case sqx.DialectPostgres:
+		return "ALTER TABLE " + tn + " ALTER COLUMN " + cn + str.If(c.Nullable, " DROP NOT NULL", " SET NOT NULL")
+	case sqx.DialectMySQL:
+		return "ALTER TABLE " + tn + " MODIFY COLUMN " + g.columnDef(t, c)
+	case sqx.DialectSQLServer:
+		return "ALTER TABLE " + tn + " ALTER COLUMN " + cn + " " + c.Type + str.If(c.Nullable, " NULL", " NOT NULL")
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,226 @@
+package ddl
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/askasoft/pango/sqx/sqlx"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type testBase struct {
+	CreatedAt time.Time `db:"created_at,index"`
+}
+
+type testUser struct {
+	testBase
+
+	ID      int64          `db:"id,pk,auto"`
+	GroupID int64          `db:"group_id,fk=groups.id"`
+	Name    string         `db:"name,size=100,unique"`
+	Email   *string        `db:"email,size=200,index=idx_user_email"`
+	Note    sql.NullString `db:"note"`
+	Status  string         `db:"status,size=1,default='A'"`
+	Meta    string         `db:"meta,type=jsonb,null"`
+	Skip    string         `db:"-"`
+}
+
+func (testUser) TableName() string {
+	return "users"
+}
+
+type testTag struct {
+	Tag   string `db:"tag,pk,size=20"`
+	Lang  string `db:"lang,pk,size=2"`
+	Label string `db:"label,unique=uq_tag_label"`
+	Order int    `db:"order,unique=uq_tag_label"`
+}
+
+func TestCreateTable(t *testing.T) {
+	cs := []struct {
+		d string
+		a any
+		w []string
+	}{
+		{
+			"postgres", &testUser{},
+			[]string{
+				`CREATE TABLE "users" (
+	"id" BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+	"group_id" BIGINT NOT NULL,
+	"name" VARCHAR(100) NOT NULL,
+	"email" VARCHAR(200),
+	"note" TEXT,
+	"status" VARCHAR(1) NOT NULL DEFAULT 'A',
+	"meta" jsonb,
+	"created_at" TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_users_group_id" FOREIGN KEY ("group_id") REFERENCES "groups" ("id")
+)`,
+				`CREATE UNIQUE INDEX "uq_users_name" ON "users" ("name")`,
+				`CREATE INDEX "idx_user_email" ON "users" ("email")`,
+				`CREATE INDEX "idx_users_created_at" ON "users" ("created_at")`,
+			},
+		},
+		{
+			"mysql", &testTag{},
+			[]string{
+				"CREATE TABLE `test_tag` (\n\t`tag` VARCHAR(20) NOT NULL,\n\t`lang` VARCHAR(2) NOT NULL,\n\t`label` TEXT NOT NULL,\n\t`order` BIGINT NOT NULL,\n\tPRIMARY KEY (`tag`, `lang`)\n)",
+				"CREATE UNIQUE INDEX `uq_tag_label` ON `test_tag` (`label`, `order`)",
+			},
+		},
+		{
+			"sqlserver", &testTag{},
+			[]string{
+				"CREATE TABLE [test_tag] (\n\t[tag] NVARCHAR(20) NOT NULL,\n\t[lang] NVARCHAR(2) NOT NULL,\n\t[label] NVARCHAR(MAX) NOT NULL,\n\t[order] BIGINT NOT NULL,\n\tPRIMARY KEY ([tag], [lang])\n)",
+				"CREATE UNIQUE INDEX [uq_tag_label] ON [test_tag] ([label], [order])",
+			},
+		},
+	}
+
+	for i, c := range cs {
+		a, err := NewGenerator(c.d).CreateTable(c.a)
+		if err != nil {
+			t.Fatalf("#%d %s: %v", i, c.d, err)
+		}
+		if !reflect.DeepEqual(a, c.w) {
+			t.Errorf("#%d %s:\n got: %q\nwant: %q", i, c.d, a, c.w)
+		}
+	}
+}
+
+func TestCreateTableError(t *testing.T) {
+	type testBad struct {
+		ID  int64          `db:"id"`
+		Map map[string]int `db:"map"`
+	}
+
+	if _, err := NewGenerator("postgres").CreateTable(&testBad{}); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+
+	type testBadFK struct {
+		ID int64 `db:"id,fk=groups"`
+	}
+	if _, err := NewGenerator("postgres").CreateTable(&testBadFK{}); err == nil {
+		t.Error("expected error for invalid fk option")
+	}
+}
+
+type testItem struct {
+	ID   int64  `db:"id,pk,auto"`
+	Name string `db:"name,size=50"`
+}
+
+type testItemV2 struct {
+	ID    int64   `db:"id,pk,auto"`
+	Name  string  `db:"name,size=50,index"`
+	Price float64 `db:"price,default=0"`
+	Note  *string `db:"note"`
+	Code  string  `db:"code,size=10"`
+}
+
+func (testItemV2) TableName() string {
+	return "test_item"
+}
+
+func TestDiffSQLite(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sqlx.Connect("sqlite3", filepath.Join(t.TempDir(), "ddl.db3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	g := NewGenerator("sqlite3")
+
+	sqls, err := g.Diff(ctx, db, &testItem{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := []string{"CREATE TABLE \"test_item\" (\n\t\"id\" INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,\n\t\"name\" VARCHAR(50) NOT NULL\n)"}
+	if !reflect.DeepEqual(sqls, w) {
+		t.Fatalf("Diff(create):\n got: %q\nwant: %q", sqls, w)
+	}
+	for _, s := range sqls {
+		if _, err := db.ExecContext(ctx, s); err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+	}
+
+	sqls, err = g.Diff(ctx, db, &testItem{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sqls) != 0 {
+		t.Fatalf("Diff(same) = %q, want []", sqls)
+	}
+
+	sqls, err = g.Diff(ctx, db, &testItemV2{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = []string{
+		`ALTER TABLE "test_item" ADD COLUMN "price" DOUBLE PRECISION NOT NULL DEFAULT 0`,
+		`ALTER TABLE "test_item" ADD COLUMN "note" TEXT`,
+		`ALTER TABLE "test_item" ADD COLUMN "code" VARCHAR(10)`,
+		`CREATE INDEX "idx_test_item_name" ON "test_item" ("name")`,
+	}
+	if !reflect.DeepEqual(sqls, w) {
+		t.Fatalf("Diff(alter):\n got: %q\nwant: %q", sqls, w)
+	}
+	for _, s := range sqls {
+		if _, err := db.ExecContext(ctx, s); err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+	}
+
+	sqls, err = g.Diff(ctx, db, &testItemV2{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sqls) != 0 {
+		t.Fatalf("Diff(altered) = %q, want []", sqls)
+	}
+
+	g.DropColumns = true
+	sqls, err = g.Diff(ctx, db, &testItem{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = []string{
+		`ALTER TABLE "test_item" DROP COLUMN "code"`,
+		`ALTER TABLE "test_item" DROP COLUMN "note"`,
+		`ALTER TABLE "test_item" DROP COLUMN "price"`,
+	}
+	if !reflect.DeepEqual(sqls, w) {
+		t.Fatalf("Diff(drop):\n got: %q\nwant: %q", sqls, w)
+	}
+}
+
+func TestAlterNullSQL(t *testing.T) {
+	tb := &Table{Name: "t"}
+	c := &Column{Name: "c", Type: "VARCHAR(10)", Nullable: true}
+
+	cs := []struct {
+		d string
+		w string
+	}{
+		{"postgres", `ALTER TABLE "t" ALTER COLUMN "c" DROP NOT NULL`},
+		{"mysql", "ALTER TABLE `t` MODIFY COLUMN `c` VARCHAR(10)"},
+		{"sqlserver", "ALTER TABLE [t] ALTER COLUMN [c] VARCHAR(10) NULL"},
+		{"sqlite3", ""},
+	}
+
+	for i, c2 := range cs {
+		if a := NewGenerator(c2.d).AlterNullSQL(tb, c); a != c2.w {
+			t.Errorf("#%d %s: got %q, want %q", i, c2.d, a, c2.w)
+		}
+	}
+}
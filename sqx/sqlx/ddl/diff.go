@@ -0,0 +1,146 @@
+package ddl
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/askasoft/pango/sqx"
+	"github.com/askasoft/pango/sqx/sqlx"
+	"github.com/askasoft/pango/str"
+)
+
+// dbTable the existing table schema (lower case names)
+type dbTable struct {
+	columns map[string]bool // name -> nullable
+	indexes map[string]bool
+}
+
+func (g *Generator) schemaSQL() (columns, indexes string) {
+	switch g.dialect {
+	case sqx.DialectSQLite:
+		return `SELECT name, CASE WHEN "notnull" = 0 THEN 1 ELSE 0 END FROM pragma_table_info(?)`,
+			`SELECT name FROM pragma_index_list(?)`
+	case sqx.DialectPostgres:
+		return `SELECT column_name, CASE WHEN is_nullable = 'YES' THEN 1 ELSE 0 END FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ?`,
+			`SELECT indexname FROM pg_indexes WHERE schemaname = current_schema() AND tablename = ?`
+	case sqx.DialectMySQL:
+		return `SELECT column_name, CASE WHEN is_nullable = 'YES' THEN 1 ELSE 0 END FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ?`,
+			`SELECT DISTINCT index_name FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ?`
+	case sqx.DialectSQLServer:
+		return `SELECT column_name, CASE WHEN is_nullable = 'YES' THEN 1 ELSE 0 END FROM information_schema.columns WHERE table_schema = SCHEMA_NAME() AND table_name = ?`,
+			`SELECT name FROM sys.indexes WHERE object_id = OBJECT_ID(?) AND name IS NOT NULL`
+	default:
+		return `SELECT column_name, CASE WHEN is_nullable = 'YES' THEN 1 ELSE 0 END FROM information_schema.columns WHERE table_name = ?`, ""
+	}
+}
+
+func (g *Generator) readTable(ctx context.Context, x sqlx.Sqlx, table string) (*dbTable, error) {
+	csql, isql := g.schemaSQL()
+
+	dt := &dbTable{columns: map[string]bool{}, indexes: map[string]bool{}}
+
+	rows, err := x.QueryContext(ctx, x.Rebind(csql), table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			name     string
+			nullable int
+		)
+		if err := rows.Scan(&name, &nullable); err != nil {
+			return nil, err
+		}
+		dt.columns[str.ToLower(name)] = nullable != 0
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if isql == "" || len(dt.columns) == 0 {
+		return dt, nil
+	}
+
+	var names []string
+	if err := x.SelectContext(ctx, &names, x.Rebind(isql), table); err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		dt.indexes[str.ToLower(name)] = true
+	}
+	return dt, nil
+}
+
+// Diff compares the struct a with the existing table in the database,
+// and returns the statements to migrate the table:
+//
+//   - CREATE TABLE and CREATE INDEX if the table does not exist
+//   - ALTER TABLE ADD COLUMN for the missing columns, a NOT NULL column without the default value
+//     is added as nullable because the existing rows have no value, the next Diff changes it to NOT NULL
+//     after the existing rows are filled (except SQLite)
+//   - ALTER TABLE to change the nullability of the columns (except SQLite)
+//   - ALTER TABLE DROP COLUMN for the columns not in the struct if DropColumns is true
+//   - CREATE INDEX for the missing indexes (compared by name)
+//
+// The column type, default value and foreign key changes are not detected.
+// The existing schema is read from the information_schema (pg_indexes, information_schema.statistics, sys.indexes for indexes),
+// or the PRAGMA table_info / index_list for SQLite.
+func (g *Generator) Diff(ctx context.Context, x sqlx.Sqlx, a any) ([]string, error) {
+	t, err := g.Table(a)
+	if err != nil {
+		return nil, err
+	}
+
+	dt, err := g.readTable(ctx, x, t.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dt.columns) == 0 {
+		return g.CreateTableSQL(t)
+	}
+
+	var sqls []string
+
+	names := make(map[string]bool, len(t.Columns))
+	for _, c := range t.Columns {
+		name := str.ToLower(c.Name)
+		names[name] = true
+
+		nullable, ok := dt.columns[name]
+		if !ok {
+			if !c.Nullable && c.Default == "" && !c.PK {
+				nc := *c
+				nc.Nullable = true
+				c = &nc
+			}
+			sqls = append(sqls, g.AddColumnSQL(t, c))
+			continue
+		}
+
+		if !c.PK && nullable != c.Nullable {
+			if sql := g.AlterNullSQL(t, c); sql != "" {
+				sqls = append(sqls, sql)
+			}
+		}
+	}
+
+	if g.DropColumns {
+		for _, name := range slices.Sorted(maps.Keys(dt.columns)) {
+			if !names[name] {
+				sqls = append(sqls, g.DropColumnSQL(t, name))
+			}
+		}
+	}
+
+	for _, ix := range t.Indexes {
+		if !dt.indexes[str.ToLower(ix.Name)] {
+			sqls = append(sqls, g.CreateIndexSQL(t, ix))
+		}
+	}
+
+	return sqls, nil
+}
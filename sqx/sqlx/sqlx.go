@@ -55,6 +55,10 @@ var NameMapper = ref.NewMapperFunc("db", str.SnakeCase)
 
 type Trace func(bind Binder, start time.Time, sql string, args []any, rows int64, err error)
 
+// ContextTrace the trace function with the context of the statement.
+// The context is context.Background() for the non-context methods (e.g. Exec, Query, Commit).
+type ContextTrace func(ctx context.Context, bind Binder, start time.Time, sql string, args []any, rows int64, err error)
+
 type Supporter interface {
 	SupportLastInsertID() bool
 }
@@ -322,7 +326,7 @@ func (ext *ext) Builder() *Builder {
 func (ext *ext) StructFields(a any, omits ...string) (fields []string) {
 	sm := ext.mapper.TypeMap(reflect.TypeOf(a))
 	for _, fi := range sm.Index {
-		if isIgnoredField(fi, omits...) {
+		if IsIgnoredField(fi, omits...) {
 			continue
 		}
 		fields = append(fields, fi.Name)
@@ -330,7 +334,9 @@ func (ext *ext) StructFields(a any, omits ...string) (fields []string) {
 	return
 }
 
-func isIgnoredField(fi *ref.FieldInfo, omits ...string) bool {
+// IsIgnoredField returns true if the field is a embedded struct, a child of a non-embedded struct field,
+// or the field name is in the omits.
+func IsIgnoredField(fi *ref.FieldInfo, omits ...string) bool {
 	if fi.Embedded || asg.Contains(omits, fi.Name) {
 		return true
 	}
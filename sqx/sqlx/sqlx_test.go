@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
@@ -49,8 +50,21 @@ var sldb *DB
 var pgdb *DB
 var mysqldb *DB
 
-func init() {
-	ConnectAll()
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "sqlx")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	ConnectAll(dir)
+	code := m.Run()
+
+	if sldb != nil {
+		sldb.Close()
+	}
+	os.RemoveAll(dir)
+	os.Exit(code)
 }
 
 func testTrace(bind Binder, start time.Time, sql string, args []any, rows int64, err error) {
@@ -77,14 +91,14 @@ func testTrace(bind Binder, start time.Time, sql string, args []any, rows int64,
 // CREATE DATABASE pango WITH OWNER=pango ENCODING='UTF-8';
 // GRANT ALL ON DATABASE pango TO pango;
 // ```
-func ConnectAll() {
+func ConnectAll(dir string) {
 	var err error
 
 	pgdsn := os.Getenv("SQLX_POSTGRES_DSN")
 	mydsn := os.Getenv("SQLX_MYSQL_DSN")
 	sqdsn := os.Getenv("SQLX_SQLITE_DSN")
 	if sqdsn == "" {
-		sqdsn = "sqlx.db3"
+		sqdsn = filepath.Join(dir, "sqlx.db3")
 	}
 
 	TestPostgres = pgdsn != ""
@@ -10,15 +10,29 @@ import (
 )
 
 type tracer struct {
-	Bind  Binder
-	Trace Trace
+	Bind         Binder
+	Trace        Trace
+	ContextTrace ContextTrace
+}
+
+func (t *tracer) enabled() bool {
+	return t.Trace != nil || t.ContextTrace != nil
+}
+
+func (t *tracer) trace(ctx context.Context, start time.Time, sql string, args []any, rows int64, err error) {
+	if t.Trace != nil {
+		t.Trace(t.Bind, start, sql, args, rows, err)
+	}
+	if t.ContextTrace != nil {
+		t.ContextTrace(ctx, t.Bind, start, sql, args, rows, err)
+	}
 }
 
 func (t *tracer) TracePing(pr sqx.Pinger) error {
 	start := time.Now()
 	err := pr.Ping()
-	if t.Trace != nil {
-		t.Trace(t.Bind, start, "Ping()", nil, -1, err)
+	if t.enabled() {
+		t.trace(context.Background(), start, "Ping()", nil, -1, err)
 	}
 	return err
 }
@@ -26,8 +40,8 @@ func (t *tracer) TracePing(pr sqx.Pinger) error {
 func (t *tracer) TracePingContext(ctx context.Context, pr sqx.ContextPinger) error {
 	start := time.Now()
 	err := pr.PingContext(ctx)
-	if t.Trace != nil {
-		t.Trace(t.Bind, start, "PingContext()", nil, -1, err)
+	if t.enabled() {
+		t.trace(ctx, start, "PingContext()", nil, -1, err)
 	}
 	return err
 }
@@ -35,8 +49,8 @@ func (t *tracer) TracePingContext(ctx context.Context, pr sqx.ContextPinger) err
 func (t *tracer) TraceQuery(qr sqx.Queryer, query string, args ...any) (*sql.Rows, error) {
 	start := time.Now()
 	rows, err := qr.Query(query, args...)
-	if t.Trace != nil {
-		t.Trace(t.Bind, start, query, args, -1, err)
+	if t.enabled() {
+		t.trace(context.Background(), start, query, args, -1, err)
 	}
 	return rows, err
 }
@@ -44,8 +58,8 @@ func (t *tracer) TraceQuery(qr sqx.Queryer, query string, args ...any) (*sql.Row
 func (t *tracer) TraceQueryRow(rqr sqx.RowQueryer, query string, args ...any) *sql.Row {
 	start := time.Now()
 	row := rqr.QueryRow(query, args...)
-	if t.Trace != nil {
-		t.Trace(t.Bind, start, query, args, -1, row.Err())
+	if t.enabled() {
+		t.trace(context.Background(), start, query, args, -1, row.Err())
 	}
 	return row
 }
@@ -53,8 +67,8 @@ func (t *tracer) TraceQueryRow(rqr sqx.RowQueryer, query string, args ...any) *s
 func (t *tracer) TraceStmtQuery(sqr sqx.StmtQueryer, query string, args ...any) (*sql.Rows, error) {
 	start := time.Now()
 	rows, err := sqr.Query(args...)
-	if t.Trace != nil {
-		t.Trace(t.Bind, start, query, args, -1, err)
+	if t.enabled() {
+		t.trace(context.Background(), start, query, args, -1, err)
 	}
 	return rows, err
 }
@@ -62,8 +76,8 @@ func (t *tracer) TraceStmtQuery(sqr sqx.StmtQueryer, query string, args ...any)
 func (t *tracer) TraceQueryContext(ctx context.Context, cqr sqx.ContextQueryer, query string, args ...any) (*sql.Rows, error) {
 	start := time.Now()
 	rows, err := cqr.QueryContext(ctx, query, args...)
-	if t.Trace != nil {
-		t.Trace(t.Bind, start, query, args, -1, err)
+	if t.enabled() {
+		t.trace(ctx, start, query, args, -1, err)
 	}
 	return rows, err
 }
@@ -71,8 +85,8 @@ func (t *tracer) TraceQueryContext(ctx context.Context, cqr sqx.ContextQueryer,
 func (t *tracer) TraceQueryRowContext(ctx context.Context, crqr sqx.ContextRowQueryer, query string, args ...any) *sql.Row {
 	start := time.Now()
 	row := crqr.QueryRowContext(ctx, query, args...)
-	if t.Trace != nil {
-		t.Trace(t.Bind, start, query, args, -1, row.Err())
+	if t.enabled() {
+		t.trace(ctx, start, query, args, -1, row.Err())
 	}
 	return row
 }
@@ -80,8 +94,8 @@ func (t *tracer) TraceQueryRowContext(ctx context.Context, crqr sqx.ContextRowQu
 func (t *tracer) TraceStmtQueryContext(ctx context.Context, csqr sqx.ContextStmtQueryer, query string, args ...any) (*sql.Rows, error) {
 	start := time.Now()
 	rows, err := csqr.QueryContext(ctx, args...)
-	if t.Trace != nil {
-		t.Trace(t.Bind, start, query, args, -1, err)
+	if t.enabled() {
+		t.trace(ctx, start, query, args, -1, err)
 	}
 	return rows, err
 }
@@ -97,9 +111,9 @@ func (t *tracer) getRowsAffected(sqr sql.Result) int64 {
 func (t *tracer) TraceExec(er sqx.Execer, query string, args ...any) (sql.Result, error) {
 	start := time.Now()
 	sqr, err := er.Exec(query, args...)
-	if t.Trace != nil {
+	if t.enabled() {
 		cnt := t.getRowsAffected(sqr)
-		t.Trace(t.Bind, start, query, args, cnt, err)
+		t.trace(context.Background(), start, query, args, cnt, err)
 	}
 	return sqr, err
 }
@@ -107,9 +121,9 @@ func (t *tracer) TraceExec(er sqx.Execer, query string, args ...any) (sql.Result
 func (t *tracer) TraceStmtExec(ser sqx.StmtExecer, query string, args ...any) (sql.Result, error) {
 	start := time.Now()
 	sqr, err := ser.Exec(args...)
-	if t.Trace != nil {
+	if t.enabled() {
 		cnt := t.getRowsAffected(sqr)
-		t.Trace(t.Bind, start, query, args, cnt, err)
+		t.trace(context.Background(), start, query, args, cnt, err)
 	}
 	return sqr, err
 }
@@ -117,9 +131,9 @@ func (t *tracer) TraceStmtExec(ser sqx.StmtExecer, query string, args ...any) (s
 func (t *tracer) TraceExecContext(ctx context.Context, cer sqx.ContextExecer, query string, args ...any) (sql.Result, error) {
 	start := time.Now()
 	sqr, err := cer.ExecContext(ctx, query, args...)
-	if t.Trace != nil {
+	if t.enabled() {
 		cnt := t.getRowsAffected(sqr)
-		t.Trace(t.Bind, start, query, args, cnt, err)
+		t.trace(ctx, start, query, args, cnt, err)
 	}
 	return sqr, err
 }
@@ -127,9 +141,9 @@ func (t *tracer) TraceExecContext(ctx context.Context, cer sqx.ContextExecer, qu
 func (t *tracer) TraceStmtExecContext(ctx context.Context, scer sqx.ContextStmtExecer, query string, args ...any) (sql.Result, error) {
 	start := time.Now()
 	sqr, err := scer.ExecContext(ctx, args...)
-	if t.Trace != nil {
+	if t.enabled() {
 		cnt := t.getRowsAffected(sqr)
-		t.Trace(t.Bind, start, query, args, cnt, err)
+		t.trace(ctx, start, query, args, cnt, err)
 	}
 	return sqr, err
 }
@@ -137,8 +151,8 @@ func (t *tracer) TraceStmtExecContext(ctx context.Context, scer sqx.ContextStmtE
 func (t *tracer) TracePrepare(pr sqx.Preparer, query string) (*sql.Stmt, error) {
 	start := time.Now()
 	stmt, err := pr.Prepare(query)
-	if t.Trace != nil {
-		t.Trace(t.Bind, start, "Prepare: "+query, nil, -1, err)
+	if t.enabled() {
+		t.trace(context.Background(), start, "Prepare: "+query, nil, -1, err)
 	}
 	return stmt, err
 }
@@ -146,8 +160,8 @@ func (t *tracer) TracePrepare(pr sqx.Preparer, query string) (*sql.Stmt, error)
 func (t *tracer) TracePrepareContext(ctx context.Context, cpr sqx.ContextPreparer, query string) (*sql.Stmt, error) {
 	start := time.Now()
 	stmt, err := cpr.PrepareContext(ctx, query)
-	if t.Trace != nil {
-		t.Trace(t.Bind, start, "PrepareContext: "+query, nil, -1, err)
+	if t.enabled() {
+		t.trace(ctx, start, "PrepareContext: "+query, nil, -1, err)
 	}
 	return stmt, err
 }
@@ -155,8 +169,8 @@ func (t *tracer) TracePrepareContext(ctx context.Context, cpr sqx.ContextPrepare
 func (t *tracer) TraceBegin(btr sqx.Beginer) (*sql.Tx, error) {
 	start := time.Now()
 	tx, err := btr.Begin()
-	if t.Trace != nil {
-		t.Trace(t.Bind, start, "Begin()", nil, -1, err)
+	if t.enabled() {
+		t.trace(context.Background(), start, "Begin()", nil, -1, err)
 	}
 	return tx, err
 }
@@ -164,11 +178,11 @@ func (t *tracer) TraceBegin(btr sqx.Beginer) (*sql.Tx, error) {
 func (t *tracer) TraceBeginTx(ctx context.Context, btr sqx.BeginTxer, opts *sql.TxOptions) (*sql.Tx, error) {
 	start := time.Now()
 	tx, err := btr.BeginTx(ctx, opts)
-	if t.Trace != nil {
+	if t.enabled() {
 		if opts == nil {
-			t.Trace(t.Bind, start, "BeginTx(nil)", nil, -1, err)
+			t.trace(ctx, start, "BeginTx(nil)", nil, -1, err)
 		} else {
-			t.Trace(t.Bind, start, fmt.Sprintf("BeginTx(%v, %v)", opts.Isolation, opts.ReadOnly), nil, -1, err)
+			t.trace(ctx, start, fmt.Sprintf("BeginTx(%v, %v)", opts.Isolation, opts.ReadOnly), nil, -1, err)
 		}
 	}
 	return tx, err
@@ -177,8 +191,8 @@ func (t *tracer) TraceBeginTx(ctx context.Context, btr sqx.BeginTxer, opts *sql.
 func (t *tracer) TraceCommit(cr sqx.Txer) error {
 	start := time.Now()
 	err := cr.Commit()
-	if t.Trace != nil {
-		t.Trace(t.Bind, start, "Commit()", nil, -1, err)
+	if t.enabled() {
+		t.trace(context.Background(), start, "Commit()", nil, -1, err)
 	}
 	return err
 }
@@ -186,8 +200,8 @@ func (t *tracer) TraceCommit(cr sqx.Txer) error {
 func (t *tracer) TraceRollback(rr sqx.Txer) error {
 	start := time.Now()
 	err := rr.Rollback()
-	if t.Trace != nil {
-		t.Trace(t.Bind, start, "Rollback()", nil, -1, err)
+	if t.enabled() {
+		t.trace(context.Background(), start, "Rollback()", nil, -1, err)
 	}
 	return err
 }
@@ -0,0 +1,140 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/askasoft/pango/ret"
+)
+
+type txKey struct{}
+
+type txCtx struct {
+	tx    *Tx
+	depth int
+}
+
+// NewTxContext returns a context which carries the transaction tx.
+func NewTxContext(ctx context.Context, tx *Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, &txCtx{tx: tx})
+}
+
+// TxFromContext returns the active transaction carried by the context.
+func TxFromContext(ctx context.Context) (*Tx, bool) {
+	if tc, ok := ctx.Value(txKey{}).(*txCtx); ok {
+		return tc.tx, true
+	}
+	return nil, false
+}
+
+// FromContext returns the active transaction carried by the context, or db if not found.
+// Repository functions can use it to join an outer transaction.
+func FromContext(ctx context.Context, db Sqlx) Sqlx {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return db
+}
+
+// Savepoint creates a savepoint with the name in the transaction.
+func (tx *Tx) Savepoint(ctx context.Context, name string) error {
+	sql := "SAVEPOINT " + name
+	if tx.binder == BindAt {
+		sql = "SAVE TRANSACTION " + name
+	}
+	_, err := tx.ExecContext(ctx, sql)
+	return err
+}
+
+// RollbackTo rollbacks the transaction to the savepoint with the name.
+func (tx *Tx) RollbackTo(ctx context.Context, name string) error {
+	sql := "ROLLBACK TO SAVEPOINT " + name
+	if tx.binder == BindAt {
+		sql = "ROLLBACK TRANSACTION " + name
+	}
+	_, err := tx.ExecContext(ctx, sql)
+	return err
+}
+
+// Release releases the savepoint with the name.
+// SQL Server does not support to release a savepoint, so it does nothing.
+func (tx *Tx) Release(ctx context.Context, name string) error {
+	if tx.binder == BindAt {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// TransactionContext start a transaction as a block, return error will rollback, otherwise to commit.
+// The context passed to fc carries the transaction (see TxFromContext).
+// If ctx already carries a transaction, fc is executed in a nested transaction of the outer transaction
+// by a SAVEPOINT, the error of fc only rollbacks to the savepoint, and opts is ignored.
+func TransactionContext(ctx context.Context, db BeginTxxer, opts *sql.TxOptions, fc func(ctx context.Context, tx *Tx) error) error {
+	if tc, ok := ctx.Value(txKey{}).(*txCtx); ok {
+		return nestedTransaction(ctx, tc, fc)
+	}
+
+	return Transactionx(ctx, db, opts, func(tx *Tx) error {
+		return fc(context.WithValue(ctx, txKey{}, &txCtx{tx: tx}), tx)
+	})
+}
+
+func nestedTransaction(ctx context.Context, tc *txCtx, fc func(ctx context.Context, tx *Tx) error) (err error) {
+	ntc := &txCtx{tx: tc.tx, depth: tc.depth + 1}
+	name := "sqlx_sp_" + strconv.Itoa(ntc.depth)
+
+	tx := tc.tx
+	if err = tx.Savepoint(ctx, name); err != nil {
+		return
+	}
+
+	var done bool
+	defer func() {
+		// Make sure to rollback when panic
+		if err != nil || !done {
+			_ = tx.RollbackTo(ctx, name)
+			_ = tx.Release(ctx, name)
+		}
+	}()
+
+	if err = fc(context.WithValue(ctx, txKey{}, ntc), tx); err == nil {
+		err = tx.Release(ctx, name)
+	}
+
+	done = true
+	return
+}
+
+// TransactionRetry is the same as TransactionContext, but retries the whole transaction by the retryer
+// if the transaction fails with a retryable error (e.g. serialization failure, deadlock, lock timeout).
+// If ctx already carries a transaction, fc is executed in a nested transaction without retry,
+// because the retryable error aborts the outer transaction.
+// Use sqx.IsRetryable to detect the retryable error, the driver error classifier must be registered
+// by importing the driver support package (e.g. pgsqlx/pgxv5, mysqlx).
+//
+//	r := &ret.Retryer{MaxRetries: 3, ShouldRetry: sqlx.RetryOn(sqx.IsRetryable, 50*time.Millisecond)}
+//	err := sqlx.TransactionRetry(ctx, db, nil, r, func(ctx context.Context, tx *sqlx.Tx) error { ... })
+func TransactionRetry(ctx context.Context, db BeginTxxer, opts *sql.TxOptions, r *ret.Retryer, fc func(ctx context.Context, tx *Tx) error) error {
+	if _, ok := TxFromContext(ctx); ok || r == nil {
+		return TransactionContext(ctx, db, opts, fc)
+	}
+
+	return r.Do(ctx, func() error {
+		return TransactionContext(ctx, db, opts, fc)
+	})
+}
+
+// RetryOn returns a ret.Retryer.ShouldRetry function which returns
+// a random duration in [backoff, 2*backoff) if isRetryable(err) returns true, otherwise returns 0.
+func RetryOn(isRetryable func(error) bool, backoff time.Duration) func(error) time.Duration {
+	return func(err error) time.Duration {
+		if isRetryable(err) {
+			return backoff + rand.N(max(backoff, 1))
+		}
+		return 0
+	}
+}
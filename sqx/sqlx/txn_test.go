@@ -0,0 +1,150 @@
+package sqlx
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/askasoft/pango/ret"
+)
+
+func openTxnDB(t *testing.T) *DB {
+	db, err := Open("sqlite3", filepath.Join(t.TempDir(), "txn.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec("CREATE TABLE item (name TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func txnInsert(ctx context.Context, db *DB, name string) error {
+	_, err := FromContext(ctx, db).ExecContext(ctx, "INSERT INTO item (name) VALUES (?)", name)
+	return err
+}
+
+func txnNames(t *testing.T, db *DB) (names []string) {
+	if err := db.Select(&names, "SELECT name FROM item ORDER BY name"); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestTransactionContextNested(t *testing.T) {
+	ctx := context.Background()
+	db := openTxnDB(t)
+
+	errInner := errors.New("inner")
+
+	err := db.TransactionContext(ctx, nil, func(ctx context.Context, tx *Tx) error {
+		if atx, ok := TxFromContext(ctx); !ok || atx != tx {
+			t.Error("TxFromContext() should return the active transaction")
+		}
+
+		if err := txnInsert(ctx, db, "a"); err != nil {
+			return err
+		}
+
+		// nested commit
+		err := db.TransactionContext(ctx, nil, func(ctx context.Context, ntx *Tx) error {
+			if ntx != tx {
+				t.Error("nested transaction should join the outer transaction")
+			}
+			return txnInsert(ctx, db, "b")
+		})
+		if err != nil {
+			return err
+		}
+
+		// nested rollback
+		err = db.TransactionContext(ctx, nil, func(ctx context.Context, _ *Tx) error {
+			if err := txnInsert(ctx, db, "c"); err != nil {
+				return err
+			}
+
+			// deeper nested commit, rolled back with the parent
+			_ = db.TransactionContext(ctx, nil, func(ctx context.Context, _ *Tx) error {
+				return txnInsert(ctx, db, "d")
+			})
+			return errInner
+		})
+		if !errors.Is(err, errInner) {
+			t.Errorf("nested error = %v, want %v", err, errInner)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if names := txnNames(t, db); len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("names = %v, want [a b]", names)
+	}
+
+	if _, ok := TxFromContext(ctx); ok {
+		t.Error("TxFromContext() should return false outside the transaction")
+	}
+}
+
+func TestTransactionContextRollback(t *testing.T) {
+	ctx := context.Background()
+	db := openTxnDB(t)
+
+	errOuter := errors.New("outer")
+	err := db.TransactionContext(ctx, nil, func(ctx context.Context, tx *Tx) error {
+		_ = txnInsert(ctx, db, "a")
+		return errOuter
+	})
+	if !errors.Is(err, errOuter) {
+		t.Fatalf("err = %v, want %v", err, errOuter)
+	}
+	if names := txnNames(t, db); len(names) != 0 {
+		t.Errorf("names = %v, want []", names)
+	}
+}
+
+func TestTransactionRetry(t *testing.T) {
+	ctx := context.Background()
+	db := openTxnDB(t)
+
+	errRetry := errors.New("retry")
+	r := &ret.Retryer{
+		MaxRetries:  3,
+		ShouldRetry: RetryOn(func(err error) bool { return errors.Is(err, errRetry) }, time.Millisecond),
+	}
+
+	n := 0
+	err := db.TransactionRetry(ctx, nil, r, func(ctx context.Context, tx *Tx) error {
+		n++
+		if err := txnInsert(ctx, db, "a"); err != nil {
+			return err
+		}
+		if n < 3 {
+			return errRetry
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("attempts = %d, want 3", n)
+	}
+	if names := txnNames(t, db); len(names) != 1 {
+		t.Errorf("names = %v, want [a]", names)
+	}
+
+	n = 0
+	err = db.TransactionRetry(ctx, nil, r, func(ctx context.Context, tx *Tx) error {
+		n++
+		return errors.New("fatal")
+	})
+	if err == nil || n != 1 {
+		t.Errorf("non retryable error: err = %v, attempts = %d", err, n)
+	}
+}
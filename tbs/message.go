@@ -0,0 +1,460 @@
+package tbs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/askasoft/pango/str"
+)
+
+// MessageFormat a parsed ICU MessageFormat style message.
+// Supported syntax:
+//
+//	{name}                                    argument
+//	{name, number}                            number argument
+//	{name, plural, [offset:n] =0 {..} one {..} other {..}}
+//	{name, select, male {..} female {..} other {..}}
+//	#                                         the plural number inside a plural sub message
+//	''                                        a single apostrophe
+//	'{..}'                                    quoted literal text
+//
+// The argument name can be a index of the args (e.g. {0}), or a key of the args.
+// The args can be a map[string]any, a map[string]string or key/value pairs.
+type MessageFormat struct {
+	nodes msgNodes
+}
+
+// ParseMessageFormat parses the ICU MessageFormat style message
+func ParseMessageFormat(s string) (*MessageFormat, error) {
+	mp := &msgParser{src: s}
+
+	nodes, err := mp.parseMessage(false)
+	if err != nil {
+		return nil, err
+	}
+	if mp.pos < len(s) {
+		return nil, mp.errorf("unexpected '}'")
+	}
+
+	return &MessageFormat{nodes: nodes}, nil
+}
+
+// FormatMessage parses the ICU MessageFormat style message and formats it with the args.
+func FormatMessage(locale, msg string, args ...any) (string, error) {
+	mf, err := ParseMessageFormat(msg)
+	if err != nil {
+		return "", err
+	}
+	return mf.Format(locale, args...), nil
+}
+
+// Format formats the message with the locale and args.
+// A missing argument is written as "{name}".
+func (mf *MessageFormat) Format(locale string, args ...any) string {
+	sb := &strings.Builder{}
+	mc := &msgContext{locale: locale, args: args}
+	mf.nodes.write(sb, mc)
+	return sb.String()
+}
+
+func (mf *MessageFormat) hasArgument() bool {
+	for _, n := range mf.nodes {
+		if _, ok := n.(msgText); !ok {
+			return true
+		}
+	}
+	return false
+}
+
+//----------------------------------------------------
+// context
+
+type msgContext struct {
+	locale string
+	args   []any
+	number string // the current plural number for '#'
+}
+
+func (mc *msgContext) arg(name string) (any, bool) {
+	if str.IsNumber(name) {
+		i, _ := strconv.Atoi(name)
+		if i < len(mc.args) {
+			return mc.args[i], true
+		}
+		return nil, false
+	}
+
+	if len(mc.args) == 1 {
+		switch m := mc.args[0].(type) {
+		case map[string]any:
+			v, ok := m[name]
+			return v, ok
+		case map[string]string:
+			v, ok := m[name]
+			return v, ok
+		}
+	}
+
+	for i := 0; i+1 < len(mc.args); i += 2 {
+		if k, ok := mc.args[i].(string); ok && k == name {
+			return mc.args[i+1], true
+		}
+	}
+	return nil, false
+}
+
+//----------------------------------------------------
+// nodes
+
+type msgNode interface {
+	write(sb *strings.Builder, mc *msgContext)
+}
+
+type msgNodes []msgNode
+
+func (ns msgNodes) write(sb *strings.Builder, mc *msgContext) {
+	for _, n := range ns {
+		n.write(sb, mc)
+	}
+}
+
+type msgText string
+
+func (mt msgText) write(sb *strings.Builder, mc *msgContext) {
+	sb.WriteString(string(mt))
+}
+
+type msgHash struct{}
+
+func (mh msgHash) write(sb *strings.Builder, mc *msgContext) {
+	if mc.number == "" {
+		sb.WriteByte('#')
+		return
+	}
+	sb.WriteString(mc.number)
+}
+
+type msgArg struct {
+	name string
+}
+
+func (ma *msgArg) write(sb *strings.Builder, mc *msgContext) {
+	v, ok := mc.arg(ma.name)
+	if !ok {
+		sb.WriteByte('{')
+		sb.WriteString(ma.name)
+		sb.WriteByte('}')
+		return
+	}
+	fmt.Fprint(sb, v)
+}
+
+type msgSelect struct {
+	name    string
+	options map[string]msgNodes
+}
+
+func (ms *msgSelect) write(sb *strings.Builder, mc *msgContext) {
+	v, ok := mc.arg(ms.name)
+	if !ok {
+		ms.options[PluralOther].write(sb, mc)
+		return
+	}
+
+	if ns, ok := ms.options[fmt.Sprint(v)]; ok {
+		ns.write(sb, mc)
+		return
+	}
+	ms.options[PluralOther].write(sb, mc)
+}
+
+type msgPlural struct {
+	name    string
+	offset  float64
+	options map[string]msgNodes
+}
+
+func (mp *msgPlural) write(sb *strings.Builder, mc *msgContext) {
+	v, ok := mc.arg(mp.name)
+	if !ok {
+		mp.options[PluralOther].write(sb, mc)
+		return
+	}
+
+	ns, s := mp.options[PluralOther], fmt.Sprint(v)
+	if ps, ok := pluralNumber(v); ok {
+		if n, err := strconv.ParseFloat(ps, 64); err == nil {
+			if mp.offset != 0 {
+				ps = strconv.FormatFloat(n-mp.offset, 'f', -1, 64)
+			}
+			s = ps
+
+			// explicit value match uses the value before the offset
+			if ens, ok := mp.options["="+strconv.FormatFloat(n, 'f', -1, 64)]; ok {
+				ns = ens
+			} else if po, err := parsePluralOperands(ps); err == nil {
+				if cns, ok := mp.options[GetPluralRule(mc.locale)(po)]; ok {
+					ns = cns
+				}
+			}
+		}
+	}
+
+	number := mc.number
+	mc.number = s
+	ns.write(sb, mc)
+	mc.number = number
+}
+
+//----------------------------------------------------
+// parser
+
+type msgParser struct {
+	src string
+	pos int
+}
+
+func (mp *msgParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("tbs: invalid message %q at %d: %s", mp.src, mp.pos, fmt.Sprintf(format, args...))
+}
+
+func (mp *msgParser) eof() bool {
+	return mp.pos >= len(mp.src)
+}
+
+func (mp *msgParser) skipSpaces() {
+	for !mp.eof() && isSpace(mp.src[mp.pos]) {
+		mp.pos++
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+func (mp *msgParser) readWord() string {
+	p := mp.pos
+	for !mp.eof() {
+		c := mp.src[mp.pos]
+		if c == '{' || c == '}' || c == ',' || isSpace(c) {
+			break
+		}
+		mp.pos++
+	}
+	return mp.src[p:mp.pos]
+}
+
+// parseMessage parses the message text until the unmatched '}' or the end.
+// The '#' is treated as the plural number if plural is true.
+func (mp *msgParser) parseMessage(plural bool) (msgNodes, error) {
+	var nodes msgNodes
+
+	sb := &strings.Builder{}
+	flush := func() {
+		if sb.Len() > 0 {
+			nodes = append(nodes, msgText(sb.String()))
+			sb.Reset()
+		}
+	}
+
+	for !mp.eof() {
+		c := mp.src[mp.pos]
+		switch c {
+		case '\'':
+			mp.parseQuote(sb, plural)
+		case '{':
+			flush()
+			node, err := mp.parseArgument(plural)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, node)
+		case '}':
+			flush()
+			return nodes, nil
+		case '#':
+			if plural {
+				flush()
+				nodes = append(nodes, msgHash{})
+			} else {
+				sb.WriteByte(c)
+			}
+			mp.pos++
+		default:
+			sb.WriteByte(c)
+			mp.pos++
+		}
+	}
+
+	flush()
+	return nodes, nil
+}
+
+// parseQuote parses the apostrophe quoted text.
+// A doubled apostrophe is a single apostrophe, and a apostrophe followed by a special character starts a quoted literal text.
+func (mp *msgParser) parseQuote(sb *strings.Builder, plural bool) {
+	mp.pos++
+	if mp.eof() {
+		sb.WriteByte('\'')
+		return
+	}
+
+	c := mp.src[mp.pos]
+	if c == '\'' {
+		sb.WriteByte('\'')
+		mp.pos++
+		return
+	}
+
+	if c != '{' && c != '}' && !(plural && c == '#') {
+		sb.WriteByte('\'')
+		return
+	}
+
+	for !mp.eof() {
+		c = mp.src[mp.pos]
+		mp.pos++
+		if c == '\'' {
+			if mp.eof() || mp.src[mp.pos] != '\'' {
+				return
+			}
+			mp.pos++
+		}
+		sb.WriteByte(c)
+	}
+}
+
+// parseArgument parses the argument.
+// The '#' in the select options is treated as the plural number of the outer plural if plural is true.
+func (mp *msgParser) parseArgument(plural bool) (msgNode, error) {
+	mp.pos++ // '{'
+
+	mp.skipSpaces()
+	name := mp.readWord()
+	if name == "" {
+		return nil, mp.errorf("missing argument name")
+	}
+	if !str.IsLetterNumber(strings.ReplaceAll(name, "_", "")) {
+		return nil, mp.errorf("invalid argument name %q", name)
+	}
+
+	mp.skipSpaces()
+	if mp.eof() {
+		return nil, mp.errorf("unclosed argument %q", name)
+	}
+
+	switch mp.src[mp.pos] {
+	case '}':
+		mp.pos++
+		return &msgArg{name: name}, nil
+	case ',':
+		mp.pos++
+	default:
+		return nil, mp.errorf("invalid argument %q", name)
+	}
+
+	mp.skipSpaces()
+	typ := mp.readWord()
+	mp.skipSpaces()
+	if mp.eof() {
+		return nil, mp.errorf("unclosed argument %q", name)
+	}
+
+	switch typ {
+	case "number":
+		if mp.src[mp.pos] != '}' {
+			return nil, mp.errorf("unsupported number style of argument %q", name)
+		}
+		mp.pos++
+		return &msgArg{name: name}, nil
+	case "plural":
+		if mp.src[mp.pos] != ',' {
+			return nil, mp.errorf("missing plural options of argument %q", name)
+		}
+		mp.pos++
+
+		node := &msgPlural{name: name}
+		mp.skipSpaces()
+		if strings.HasPrefix(mp.src[mp.pos:], "offset:") {
+			mp.pos += len("offset:")
+			mp.skipSpaces()
+			w := mp.readWord()
+			n, err := strconv.ParseFloat(w, 64)
+			if err != nil {
+				return nil, mp.errorf("invalid plural offset %q of argument %q", w, name)
+			}
+			node.offset = n
+		}
+
+		options, err := mp.parseOptions(name, true, true)
+		if err != nil {
+			return nil, err
+		}
+		node.options = options
+		return node, nil
+	case "select":
+		if mp.src[mp.pos] != ',' {
+			return nil, mp.errorf("missing select options of argument %q", name)
+		}
+		mp.pos++
+
+		options, err := mp.parseOptions(name, false, plural)
+		if err != nil {
+			return nil, err
+		}
+		return &msgSelect{name: name, options: options}, nil
+	default:
+		return nil, mp.errorf("unsupported argument type %q of argument %q", typ, name)
+	}
+}
+
+func (mp *msgParser) parseOptions(name string, plural, hash bool) (map[string]msgNodes, error) {
+	options := make(map[string]msgNodes)
+
+	for {
+		mp.skipSpaces()
+		if mp.eof() {
+			return nil, mp.errorf("unclosed argument %q", name)
+		}
+
+		if mp.src[mp.pos] == '}' {
+			mp.pos++
+			break
+		}
+
+		key := mp.readWord()
+		if key == "" {
+			return nil, mp.errorf("missing option key of argument %q", name)
+		}
+		if plural && key[0] == '=' {
+			n, err := strconv.ParseFloat(key[1:], 64)
+			if err != nil {
+				return nil, mp.errorf("invalid plural option %q of argument %q", key, name)
+			}
+			key = "=" + strconv.FormatFloat(n, 'f', -1, 64)
+		}
+
+		mp.skipSpaces()
+		if mp.eof() || mp.src[mp.pos] != '{' {
+			return nil, mp.errorf("missing option message %q of argument %q", key, name)
+		}
+		mp.pos++
+
+		nodes, err := mp.parseMessage(hash)
+		if err != nil {
+			return nil, err
+		}
+		if mp.eof() {
+			return nil, mp.errorf("unclosed option message %q of argument %q", key, name)
+		}
+		mp.pos++ // '}'
+
+		options[key] = nodes
+	}
+
+	if _, ok := options[PluralOther]; !ok {
+		return nil, mp.errorf("missing 'other' option of argument %q", name)
+	}
+	return options, nil
+}
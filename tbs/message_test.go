@@ -0,0 +1,65 @@
+package tbs
+
+import (
+	"testing"
+)
+
+func TestFormatMessageSyntax(t *testing.T) {
+	cs := []struct {
+		locale string
+		msg    string
+		args   []any
+		want   string
+	}{
+		{"en", "hello {0}", []any{"world"}, "hello world"},
+		{"en", "hello {name}", []any{"name", "world"}, "hello world"},
+		{"en", "hello {name}", []any{map[string]string{"name": "world"}}, "hello world"},
+		{"en", "hello {name}", nil, "hello {name}"},
+		{"en", "{n, number} items", []any{"n", 3}, "3 items"},
+		{"en", "It''s '{name}' {name}", []any{"name", "me"}, "It's {name} me"},
+		{"en", "{n, plural, one {# item} other {# items}}", []any{"n", 1}, "1 item"},
+		{"en", "{n, plural, one {# item} other {'#' items}}", []any{"n", 2}, "# items"},
+		{"en", "{n,plural,=0{none}=1{one}other{#}}", []any{"n", 0}, "none"},
+		{"en", "{n, plural, offset:1 =0 {nobody} =1 {{who}} one {{who} and # other} other {{who} and # others}}", []any{"n", 1, "who", "Bob"}, "Bob"},
+		{"en", "{n, plural, offset:1 =0 {nobody} =1 {{who}} one {{who} and # other} other {{who} and # others}}", []any{"n", 2, "who", "Bob"}, "Bob and 1 other"},
+		{"en", "{n, plural, offset:1 =0 {nobody} =1 {{who}} one {{who} and # other} other {{who} and # others}}", []any{"n", 5, "who", "Bob"}, "Bob and 4 others"},
+		{"en", "{g, select, male {He} female {She} other {They}} liked it", []any{"g", "female"}, "She liked it"},
+		{"en", "{n, plural, other {{g, select, male {his #} other {their #}}}}", []any{"n", 3, "g", "male"}, "his 3"},
+		{"en", "# {n, plural, other {#}}", []any{"n", 3}, "# 3"},
+		{"en", "{n, plural, one {# item} other {# items}}", []any{"n", "x"}, "x items"},
+	}
+
+	for i, c := range cs {
+		a, err := FormatMessage(c.locale, c.msg, c.args...)
+		if err != nil {
+			t.Errorf("#%d FormatMessage(%q, %q) error: %v", i, c.locale, c.msg, err)
+			continue
+		}
+		if a != c.want {
+			t.Errorf("#%d FormatMessage(%q, %q, %v) = %q, want %q", i, c.locale, c.msg, c.args, a, c.want)
+		}
+	}
+}
+
+func TestParseMessageFormatError(t *testing.T) {
+	cs := []string{
+		"{",
+		"}",
+		"{}",
+		"{%s}",
+		"{n",
+		"{n, date}",
+		"{n, number, percent}",
+		"{n, plural, one {a}}",
+		"{n, plural, other {a}",
+		"{n, plural, other a}",
+		"{n, plural, =x {a} other {b}}",
+		"{n, select}",
+	}
+
+	for i, c := range cs {
+		if _, err := ParseMessageFormat(c); err == nil {
+			t.Errorf("#%d ParseMessageFormat(%q) should return error", i, c)
+		}
+	}
+}
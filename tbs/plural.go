@@ -0,0 +1,360 @@
+package tbs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/askasoft/pango/str"
+)
+
+// CLDR plural categories
+const (
+	PluralZero  = "zero"
+	PluralOne   = "one"
+	PluralTwo   = "two"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+// PluralOperands the CLDR plural operands of a number.
+// See https://unicode.org/reports/tr35/tr35-numbers.html#Operands
+type PluralOperands struct {
+	N float64 // absolute value of the source number
+	I int64   // integer digits of n
+	V int     // number of visible fraction digits in n, with trailing zeros
+	W int     // number of visible fraction digits in n, without trailing zeros
+	F int64   // visible fraction digits in n, with trailing zeros
+	T int64   // visible fraction digits in n, without trailing zeros
+}
+
+// NewPluralOperands returns the plural operands of the number n.
+// n can be a integer, a float or a numeric string like "1.50".
+func NewPluralOperands(n any) (*PluralOperands, error) {
+	s, ok := pluralNumber(n)
+	if !ok {
+		return nil, fmt.Errorf("tbs: invalid plural number %v (%T)", n, n)
+	}
+	return parsePluralOperands(s)
+}
+
+// pluralNumber returns the decimal string of the number n
+func pluralNumber(n any) (string, bool) {
+	switch v := n.(type) {
+	case int:
+		return strconv.FormatInt(int64(v), 10), true
+	case int8:
+		return strconv.FormatInt(int64(v), 10), true
+	case int16:
+		return strconv.FormatInt(int64(v), 10), true
+	case int32:
+		return strconv.FormatInt(int64(v), 10), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case string:
+		return strings.TrimSpace(v), true
+	case fmt.Stringer:
+		return strings.TrimSpace(v.String()), true
+	default:
+		return "", false
+	}
+}
+
+func parsePluralOperands(s string) (*PluralOperands, error) {
+	s = strings.TrimPrefix(s, "-")
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("tbs: invalid plural number %q", s)
+	}
+
+	po := &PluralOperands{N: n}
+
+	ip, fp, _ := strings.Cut(s, ".")
+	if ip != "" {
+		if po.I, err = strconv.ParseInt(ip, 10, 64); err != nil {
+			return nil, fmt.Errorf("tbs: invalid plural number %q", s)
+		}
+	}
+
+	if fp != "" {
+		if !str.IsNumber(fp) {
+			return nil, fmt.Errorf("tbs: invalid plural number %q", s)
+		}
+
+		po.V = len(fp)
+		po.F, _ = strconv.ParseInt(fp, 10, 64)
+
+		tp := strings.TrimRight(fp, "0")
+		po.W = len(tp)
+		po.T, _ = strconv.ParseInt(tp, 10, 64)
+	}
+
+	return po, nil
+}
+
+// PluralRule returns the plural category of the plural operands
+type PluralRule func(po *PluralOperands) string
+
+// PluralRules the CLDR cardinal plural rules of the major languages.
+// The key is the lower case language code, or the lower case locale (e.g. "pt-pt") if the rule of the region differs.
+var PluralRules = map[string]PluralRule{
+	"ar":    pluralRuleArabic,
+	"cs":    pluralRuleCzech,
+	"da":    pluralRuleDanish,
+	"de":    pluralRuleEnglish,
+	"el":    pluralRuleOneN,
+	"en":    pluralRuleEnglish,
+	"es":    pluralRuleSpanish,
+	"fi":    pluralRuleEnglish,
+	"fr":    pluralRuleFrench,
+	"he":    pluralRuleHebrew,
+	"hi":    pluralRuleHindi,
+	"hu":    pluralRuleOneN,
+	"id":    pluralRuleOther,
+	"it":    pluralRuleItalian,
+	"ja":    pluralRuleOther,
+	"ko":    pluralRuleOther,
+	"ms":    pluralRuleOther,
+	"nl":    pluralRuleEnglish,
+	"no":    pluralRuleOneN,
+	"pl":    pluralRulePolish,
+	"pt":    pluralRulePortuguese,
+	"pt-pt": pluralRulePortuguesePortugal,
+	"ro":    pluralRuleRomanian,
+	"ru":    pluralRuleRussian,
+	"sk":    pluralRuleCzech,
+	"sv":    pluralRuleEnglish,
+	"th":    pluralRuleOther,
+	"tr":    pluralRuleOneN,
+	"uk":    pluralRuleRussian,
+	"vi":    pluralRuleOther,
+	"zh":    pluralRuleOther,
+}
+
+// GetPluralRule returns the plural rule of the locale.
+// If the language of the locale is not found, the rule which always returns "other" is returned.
+func GetPluralRule(locale string) PluralRule {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	if pr, ok := PluralRules[locale]; ok {
+		return pr
+	}
+
+	lang := str.SubstrBeforeByte(locale, '-')
+	if pr, ok := PluralRules[lang]; ok {
+		return pr
+	}
+	return pluralRuleOther
+}
+
+// PluralCategory returns the CLDR plural category ("zero", "one", "two", "few", "many", "other")
+// of the number n for the locale.
+func PluralCategory(locale string, n any) (string, error) {
+	po, err := NewPluralOperands(n)
+	if err != nil {
+		return "", err
+	}
+	return GetPluralRule(locale)(po), nil
+}
+
+func inRange(n, min, max int64) bool {
+	return n >= min && n <= max
+}
+
+func isMillions(po *PluralOperands) bool {
+	return po.I != 0 && po.I%1000000 == 0 && po.V == 0
+}
+
+// other
+func pluralRuleOther(po *PluralOperands) string {
+	return PluralOther
+}
+
+// one: n = 1
+func pluralRuleOneN(po *PluralOperands) string {
+	if po.N == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+// one: i = 1 and v = 0
+func pluralRuleEnglish(po *PluralOperands) string {
+	if po.I == 1 && po.V == 0 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+// one: n = 1 or t != 0 and i = 0,1
+func pluralRuleDanish(po *PluralOperands) string {
+	if po.N == 1 || (po.T != 0 && (po.I == 0 || po.I == 1)) {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+// one: i = 0 or n = 1
+func pluralRuleHindi(po *PluralOperands) string {
+	if po.I == 0 || po.N == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+// one: n = 1; many: e = 0 and i != 0 and i % 1000000 = 0 and v = 0
+func pluralRuleSpanish(po *PluralOperands) string {
+	if po.N == 1 {
+		return PluralOne
+	}
+	if isMillions(po) {
+		return PluralMany
+	}
+	return PluralOther
+}
+
+// one: i = 1 and v = 0; many: e = 0 and i != 0 and i % 1000000 = 0 and v = 0
+func pluralRuleItalian(po *PluralOperands) string {
+	if po.I == 1 && po.V == 0 {
+		return PluralOne
+	}
+	if isMillions(po) {
+		return PluralMany
+	}
+	return PluralOther
+}
+
+// one: i = 0,1; many: e = 0 and i != 0 and i % 1000000 = 0 and v = 0
+func pluralRuleFrench(po *PluralOperands) string {
+	if po.I == 0 || po.I == 1 {
+		return PluralOne
+	}
+	if isMillions(po) {
+		return PluralMany
+	}
+	return PluralOther
+}
+
+// same as french (pt-BR is the CLDR default for pt)
+func pluralRulePortuguese(po *PluralOperands) string {
+	return pluralRuleFrench(po)
+}
+
+// pt-PT: one: i = 1 and v = 0; many: e = 0 and i != 0 and i % 1000000 = 0 and v = 0
+func pluralRulePortuguesePortugal(po *PluralOperands) string {
+	return pluralRuleItalian(po)
+}
+
+// one: i = 1 and v = 0; few: v != 0 or n = 0 or n != 1 and n % 100 = 1..19
+func pluralRuleRomanian(po *PluralOperands) string {
+	if po.I == 1 && po.V == 0 {
+		return PluralOne
+	}
+	if po.V != 0 || po.N == 0 || (po.N != 1 && inRange(po.I%100, 1, 19)) {
+		return PluralFew
+	}
+	return PluralOther
+}
+
+// one: v = 0 and i % 10 = 1 and i % 100 != 11
+// few: v = 0 and i % 10 = 2..4 and i % 100 != 12..14
+// many: v = 0 and i % 10 = 0 or v = 0 and i % 10 = 5..9 or v = 0 and i % 100 = 11..14
+func pluralRuleRussian(po *PluralOperands) string {
+	if po.V != 0 {
+		return PluralOther
+	}
+
+	i10, i100 := po.I%10, po.I%100
+	switch {
+	case i10 == 1 && i100 != 11:
+		return PluralOne
+	case inRange(i10, 2, 4) && !inRange(i100, 12, 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+// one: i = 1 and v = 0
+// few: v = 0 and i % 10 = 2..4 and i % 100 != 12..14
+// many: v = 0 and i != 1 and i % 10 = 0..1 or v = 0 and i % 10 = 5..9 or v = 0 and i % 100 = 12..14
+func pluralRulePolish(po *PluralOperands) string {
+	if po.V != 0 {
+		return PluralOther
+	}
+
+	if po.I == 1 {
+		return PluralOne
+	}
+
+	i10, i100 := po.I%10, po.I%100
+	if inRange(i10, 2, 4) && !inRange(i100, 12, 14) {
+		return PluralFew
+	}
+	return PluralMany
+}
+
+// one: i = 1 and v = 0; few: i = 2..4 and v = 0; many: v != 0
+func pluralRuleCzech(po *PluralOperands) string {
+	if po.V != 0 {
+		return PluralMany
+	}
+
+	switch {
+	case po.I == 1:
+		return PluralOne
+	case inRange(po.I, 2, 4):
+		return PluralFew
+	default:
+		return PluralOther
+	}
+}
+
+// one: i = 1 and v = 0 or i = 0 and v != 0; two: i = 2 and v = 0
+func pluralRuleHebrew(po *PluralOperands) string {
+	if (po.I == 1 && po.V == 0) || (po.I == 0 && po.V != 0) {
+		return PluralOne
+	}
+	if po.I == 2 && po.V == 0 {
+		return PluralTwo
+	}
+	return PluralOther
+}
+
+// zero: n = 0; one: n = 1; two: n = 2; few: n % 100 = 3..10; many: n % 100 = 11..99
+func pluralRuleArabic(po *PluralOperands) string {
+	switch po.N {
+	case 0:
+		return PluralZero
+	case 1:
+		return PluralOne
+	case 2:
+		return PluralTwo
+	}
+
+	if po.V == 0 || po.T == 0 {
+		n100 := po.I % 100
+		switch {
+		case inRange(n100, 3, 10):
+			return PluralFew
+		case inRange(n100, 11, 99):
+			return PluralMany
+		}
+	}
+	return PluralOther
+}
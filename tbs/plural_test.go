@@ -0,0 +1,78 @@
+package tbs
+
+import (
+	"testing"
+)
+
+func TestPluralCategory(t *testing.T) {
+	cs := []struct {
+		locale string
+		n      any
+		want   string
+	}{
+		{"en", 0, PluralOther},
+		{"en", 1, PluralOne},
+		{"en", "1.0", PluralOther},
+		{"en-US", 2, PluralOther},
+		{"xx", 1, PluralOther},
+		{"", 1, PluralOther},
+		{"ja", 1, PluralOther},
+		{"zh-TW", 1, PluralOther},
+		{"fr", 0, PluralOne},
+		{"fr", 1.5, PluralOne},
+		{"fr", 2, PluralOther},
+		{"fr", 1000000, PluralMany},
+		{"es", 1, PluralOne},
+		{"es", 2000000, PluralMany},
+		{"de", 1, PluralOne},
+		{"ru", 1, PluralOne},
+		{"ru", 11, PluralMany},
+		{"ru", 21, PluralOne},
+		{"ru", 22, PluralFew},
+		{"ru", 12, PluralMany},
+		{"ru", 25, PluralMany},
+		{"ru", 1.5, PluralOther},
+		{"uk", 3, PluralFew},
+		{"pl", 1, PluralOne},
+		{"pl", 2, PluralFew},
+		{"pl", 5, PluralMany},
+		{"pl", 12, PluralMany},
+		{"pl", 22, PluralFew},
+		{"pl", 21, PluralMany},
+		{"pl", "0.5", PluralOther},
+		{"cs", 1, PluralOne},
+		{"cs", 3, PluralFew},
+		{"cs", 5, PluralOther},
+		{"cs", 0.5, PluralMany},
+		{"ar", 0, PluralZero},
+		{"ar", 1, PluralOne},
+		{"ar", 2, PluralTwo},
+		{"ar", 3, PluralFew},
+		{"ar", 103, PluralFew},
+		{"ar", 11, PluralMany},
+		{"ar", 100, PluralOther},
+		{"he", 2, PluralTwo},
+		{"pt", 0, PluralOne},
+		{"pt-BR", 1.5, PluralOne},
+		{"pt-PT", 0, PluralOther},
+		{"pt_PT", 1, PluralOne},
+		{"pt-PT", 1.5, PluralOther},
+		{"pt-PT", 1000000, PluralMany},
+		{"en", -1, PluralOne},
+	}
+
+	for i, c := range cs {
+		a, err := PluralCategory(c.locale, c.n)
+		if err != nil {
+			t.Errorf("#%d PluralCategory(%q, %v) error: %v", i, c.locale, c.n, err)
+			continue
+		}
+		if a != c.want {
+			t.Errorf("#%d PluralCategory(%q, %v) = %q, want %q", i, c.locale, c.n, a, c.want)
+		}
+	}
+
+	if _, err := PluralCategory("en", "abc"); err == nil {
+		t.Error(`PluralCategory("en", "abc") should return error`)
+	}
+}
@@ -33,6 +33,11 @@ func LoadFS(fsys fs.FS, root string) error {
 	return _tbs.LoadFS(fsys, root)
 }
 
+// GetFallbacks returns the locale fallback chain of the locale (exclude the default bundle).
+func GetFallbacks(locale string) []string {
+	return _tbs.GetFallbacks(locale)
+}
+
 // GetBundle get target locale ini bundle
 func GetBundle(locale string) *ini.Ini {
 	return _tbs.GetBundle(locale)
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/askasoft/pango/asg"
@@ -24,7 +25,15 @@ type TextBundles struct {
 	Extensions []string  // file extensions
 	Timestamp  time.Time // modified timestamp
 
-	bundles map[string]*ini.Ini
+	// Fallback the last fallback locale before the default bundle, e.g. "en".
+	Fallback string
+
+	// Fallbacks the explicit parent locale map, e.g. {"zh-HK": "zh-TW"}.
+	// If the locale is not found in the map, the parent locale is the locale without the last "-" segment.
+	Fallbacks map[string]string
+
+	bundles  map[string]*ini.Ini
+	messages sync.Map // parsed message format cache
 }
 
 // NewTextBundles returns a new TextBundles instance
@@ -42,6 +51,7 @@ func NewTextBundles(extensions ...string) *TextBundles {
 // Clear clear all loaded text resources
 func (tbs *TextBundles) Clear() {
 	tbs.bundles = make(map[string]*ini.Ini)
+	tbs.messages.Clear()
 	tbs.Timestamp = time.Now()
 }
 
@@ -97,23 +107,42 @@ func (tbs *TextBundles) loadFile(fsys fs.FS, path string) error {
 		}
 	}
 
+	tbs.messages.Clear()
 	tbs.Timestamp = time.Now()
 	return nil
 }
 
+// GetFallbacks returns the locale fallback chain of the locale (exclude the default bundle).
+// Example: with Fallback "en", GetFallbacks("zh-TW") returns ["zh-TW", "zh", "en"].
+func (tbs *TextBundles) GetFallbacks(locale string) []string {
+	var locales []string
+
+	add := func(loc string) {
+		for loc != "" && !asg.Contains(locales, loc) {
+			locales = append(locales, loc)
+
+			if p, ok := tbs.Fallbacks[loc]; ok {
+				loc = p
+			} else if l2, _, ok := str.LastCutByte(loc, '-'); ok {
+				loc = l2
+			} else {
+				break
+			}
+		}
+	}
+
+	add(locale)
+	add(tbs.Fallback)
+	return locales
+}
+
 // GetBundle get target locale ini bundle
 func (tbs *TextBundles) GetBundle(locale string) *ini.Ini {
 	bundles := []*ini.Ini{}
-	for locale != "" {
-		if bundle, ok := tbs.bundles[locale]; ok {
+	for _, loc := range tbs.GetFallbacks(locale) {
+		if bundle, ok := tbs.bundles[loc]; ok {
 			bundles = append(bundles, bundle)
 		}
-
-		if l2, _, ok := str.LastCutByte(locale, '-'); ok {
-			locale = l2
-		} else {
-			break
-		}
 	}
 
 	if bundle, ok := tbs.bundles[""]; ok {
@@ -127,33 +156,28 @@ func (tbs *TextBundles) GetBundle(locale string) *ini.Ini {
 	return b
 }
 
-// get get the target locale string
-func (tbs *TextBundles) get(locale, sect, name string) (string, bool) {
-	for locale != "" {
-		if bundle, ok := tbs.bundles[locale]; ok {
+// get get the target locale string, and the locale of the bundle which the string is found in.
+// The locale of the default bundle is the Fallback locale (or the target locale if Fallback is empty).
+func (tbs *TextBundles) get(locale, sect, name string) (string, string, bool) {
+	for _, loc := range tbs.GetFallbacks(locale) {
+		if bundle, ok := tbs.bundles[loc]; ok {
 			if sec := bundle.GetSection(sect); sec != nil {
 				if val := sec.Get(name); val != "" {
-					return val, ok
+					return val, loc, ok
 				}
 			}
 		}
-
-		if l2, _, ok := str.LastCutByte(locale, '-'); ok {
-			locale = l2
-		} else {
-			break
-		}
 	}
 
 	if bundle, ok := tbs.bundles[""]; ok {
 		if sec := bundle.GetSection(sect); sec != nil {
 			if val := sec.Get(name); val != "" {
-				return val, ok
+				return val, str.IfEmpty(tbs.Fallback, locale), ok
 			}
 		}
 	}
 
-	return "", false
+	return "", "", false
 }
 
 // GetBool get a bool value of the key from the text bundle.
@@ -183,17 +207,21 @@ func (tbs *TextBundles) GetFloat(locale, key string, defs ...float64) float64 {
 // GetText get the locale text by key.
 // if not found, returns the default defs[0] value.
 func (tbs *TextBundles) GetText(locale, key string, defs ...string) string {
+	if val, _, ok := tbs.getText(locale, key); ok {
+		return val
+	}
+	return asg.First(defs)
+}
+
+// getText get the locale text by key, and the locale of the bundle which the text is found in.
+func (tbs *TextBundles) getText(locale, key string) (string, string, bool) {
 	sect, name := "", key
 
 	if i := str.LastIndexByte(key, '.'); i >= 0 {
 		sect, name = key[:i], key[i+1:]
 	}
 
-	if val, ok := tbs.get(locale, sect, name); ok {
-		return val
-	}
-
-	return asg.First(defs)
+	return tbs.get(locale, sect, name)
 }
 
 // Error create a error with the locale text by key.
@@ -206,17 +234,46 @@ func (tbs *TextBundles) Errorf(locale, key string, args ...any) error {
 	return errors.New(tbs.Format(locale, key, args...))
 }
 
-// Format use fmt.Sprintf to format the locale text by key and args.
+// Format format the locale text by key and args.
+// If the locale text is a ICU MessageFormat style message (see MessageFormat), it is evaluated with the args
+// (e.g. "{count, plural, one {# file} other {# files}}"), otherwise fmt.Sprintf is used.
+// A text which contains a fmt verb (e.g. "Hello {name}, %d items") is always formatted by fmt.Sprintf.
+// The plural rule of the locale which the text is found in (see GetFallbacks) is used for the plural message.
 func (tbs *TextBundles) Format(locale, key string, args ...any) string {
-	format := tbs.GetText(locale, key)
+	format, floc, _ := tbs.getText(locale, key)
 
 	if format == "" || len(args) == 0 {
 		return format
 	}
 
+	if mf := tbs.getMessageFormat(format); mf != nil {
+		return mf.Format(floc, args...)
+	}
+
 	return fmt.Sprintf(format, args...)
 }
 
+// getMessageFormat returns the parsed MessageFormat of the text,
+// or nil if the text is not a valid message format with arguments.
+func (tbs *TextBundles) getMessageFormat(text string) *MessageFormat {
+	if str.IndexByte(text, '{') < 0 || hasFmtVerb(text) {
+		return nil
+	}
+
+	if v, ok := tbs.messages.Load(text); ok {
+		mf, _ := v.(*MessageFormat)
+		return mf
+	}
+
+	mf, err := ParseMessageFormat(text)
+	if err != nil || !mf.hasArgument() {
+		mf = nil
+	}
+
+	tbs.messages.Store(text, mf)
+	return mf
+}
+
 // Replace use strings.Replacer to replace the locale text by args.
 func (tbs *TextBundles) Replace(locale, key string, args ...any) string {
 	var defs []string
@@ -262,3 +319,17 @@ func (tbs *TextBundles) Evaluate(locale, key string, data any, strict ...bool) s
 
 	return txt
 }
+
+// hasFmtVerb returns true if the text contains a fmt verb ("%" not followed by "%").
+func hasFmtVerb(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] == '%' {
+			if i+1 < len(text) && text[i+1] == '%' {
+				i++
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,8 @@ package tbs
 import (
 	"embed"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -150,3 +152,90 @@ func testGetBundle(t *testing.T, tbs *TextBundles) {
 		t.Fatalf("GetBundle()\n actual: %v\n   want: %v", sout.String(), sexp)
 	}
 }
+
+func TestFormatMessage(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"message.ini": `
+[files]
+count = {count, plural, =0 {No files} one {# file} other {# files}}
+owner = {gender, select, male {his} female {her} other {their}} {n, plural, one {file} other {files}}
+welcome = welcome %s
+hello = Hello {name}, %d items
+`,
+		"message_en.ini": `
+[en]
+only = english
+[files]
+en_count = {count, plural, one {# file} other {# files}}
+`,
+		"message_ru.ini": `
+[files]
+count = {count, plural, one {# файл} few {# файла} many {# файлов} other {# файла}}
+`,
+		"message_zh.ini": `
+[files]
+count = {count}个文件
+[zh]
+only = 简体
+`,
+		"message_zh-TW.ini": `
+[files]
+count = {count}個檔案
+`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tbs := NewTextBundles()
+	tbs.Fallback = "en"
+	tbs.Fallbacks = map[string]string{"zh-HK": "zh-TW"}
+	if err := tbs.Load(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	cs := []struct {
+		lang string
+		name string
+		args []any
+		want string
+	}{
+		{"en", "files.count", []any{"count", 0}, "No files"},
+		{"en", "files.count", []any{"count", 1}, "1 file"},
+		{"en", "files.count", []any{map[string]any{"count": 2}}, "2 files"},
+		{"en", "files.owner", []any{"gender", "female", "n", 1}, "her file"},
+		{"en", "files.owner", []any{"gender", "x", "n", 3}, "their files"},
+		{"en", "files.welcome", []any{"home"}, "welcome home"},
+		{"en", "files.hello", []any{3}, "Hello {name}, 3 items"},
+		{"ru", "files.count", []any{"count", 1}, "1 файл"},
+		{"ru", "files.count", []any{"count", 3}, "3 файла"},
+		{"ru", "files.count", []any{"count", 5}, "5 файлов"},
+		{"ru", "files.count", []any{"count", 21}, "21 файл"},
+		{"ru-RU", "files.count", []any{"count", 1.5}, "1.5 файла"},
+		{"zh", "files.count", []any{"count", 2}, "2个文件"},
+		{"zh-TW", "files.count", []any{"count", 2}, "2個檔案"},
+		{"zh-HK", "files.count", []any{"count", 2}, "2個檔案"},
+		{"zh-TW", "zh.only", nil, "简体"},
+		{"zh-TW", "en.only", nil, "english"},
+		{"ja", "en.only", nil, "english"},
+		{"ja", "files.en_count", []any{"count", 1}, "1 file"},
+		{"ja", "files.count", []any{"count", 1}, "1 file"},
+		{"ru", "files.en_count", []any{"count", 2}, "2 files"},
+	}
+
+	for i, c := range cs {
+		a := tbs.Format(c.lang, c.name, c.args...)
+		if a != c.want {
+			t.Errorf("%d Format(%q, %q, %v) = %q, want %q", i, c.lang, c.name, c.args, a, c.want)
+		}
+	}
+
+	fbs := tbs.GetFallbacks("zh-HK")
+	if want := []string{"zh-HK", "zh-TW", "zh", "en"}; !reflect.DeepEqual(fbs, want) {
+		t.Errorf("GetFallbacks(%q) = %q, want %q", "zh-HK", fbs, want)
+	}
+}
@@ -1,25 +1,71 @@
 package tpl
 
 import (
-	"html/template"
+	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/askasoft/pango/asg"
-	"github.com/askasoft/pango/str"
+	"github.com/askasoft/pango/fsw"
 )
 
 var HTMLTemplateExtensions = []string{".tpl", ".html", ".gohtml"}
 
 // HTMLTemplates html template engine
+//
+// Layout inheritance:
+//
+//	{{/* layout/main.html */}}
+//	<html><title>{{block "title" .}}Default{{end}}</title><body>{{block "content" .}}{{end}}</body></html>
+//
+//	{{/* index.html */}}
+//	{{extends "layout/main"}}
+//	{{define "title"}}Index{{end}}
+//	{{define "content"}}<p>{{.Message}}</p>{{end}}
+//
+// The template which extends a parent template overrides the parent's blocks,
+// the content outside of the "define" actions is ignored.
+// The parent template can also extends another template.
+//
+// Components:
+//
+//	{{/* components/card.html */}}
+//	{{props "title:string" "count:int?" "footer:html?"}}
+//	<div class="card"><h3>{{.title}} ({{.count}})</h3>{{.Slot}}{{.footer}}</div>
+//
+//	{{/* page.html */}}
+//	{{define "card-body"}}<p>{{.Message}}</p>{{end}}
+//	{{component "components/card" "title" "Hello" "count" 3 (slot "card-body" .)}}
+//
+// The "props" action declares the typed props of the component ("name:type", optional if suffixed with "?").
+// Supported types: any, string, int, float, bool, html, slice, map.
+// The component function accepts key/value pairs (or a map) as the props,
+// the last odd argument is the default slot content, which can be accessed by ".Slot" in the component.
+// The slot function renders the named template to html.
+//
+// All templates (including the parent templates and the components) are resolved by the locale.
+// For example, if locale is "zh-TW", the template "layout/main_zh-TW" or "layout/main_zh" is used instead of "layout/main".
+// A name suffix is a locale only if it is a known language tag with a two-letter language,
+// so "admin/user_edit" is a normal template, not a locale variant of "admin/user".
 type HTMLTemplates struct {
 	extensions []string // template extensions
 	funcs      FuncMap  // template functions
 	delims     Delims   // delimeters
 
-	template *template.Template
+	mutex   sync.RWMutex
+	set     *htmlSet
+	sources []source // loaded sources for Reload
+	error   error    // the last Reload error
+}
+
+// source a template source (root path of the os file system or fsys)
+type source struct {
+	fsys fs.FS
+	root string
 }
 
 // NewHTMLTemplates new template engine
@@ -50,45 +96,53 @@ func (ht *HTMLTemplates) Funcs(funcMap FuncMap) {
 	ht.funcs = funcMap
 }
 
-func (ht *HTMLTemplates) init() {
-	if ht.template == nil {
-		tpl := template.New("")
-		tpl.Delims(ht.delims.Left, ht.delims.Right)
-		tpl.Funcs(template.FuncMap(ht.funcs))
-		ht.template = tpl
-	}
-}
-
 // Load glob and parse template files under the root path
 func (ht *HTMLTemplates) Load(root string) (err error) {
-	ht.init()
-
 	root, err = filepath.Abs(root)
 	if err != nil {
 		return
 	}
-
 	root = filepath.ToSlash(root)
-	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			return nil
-		}
-
-		return ht.loadFile(nil, root, path)
-	})
 
-	return
+	return ht.load(source{root: root})
 }
 
 // LoadFS glob and parse template files from FS
 func (ht *HTMLTemplates) LoadFS(fsys fs.FS, root string) (err error) {
-	ht.init()
+	return ht.load(source{fsys: fsys, root: root})
+}
+
+func (ht *HTMLTemplates) load(src source) error {
+	ht.mutex.Lock()
+	defer ht.mutex.Unlock()
+
+	if ht.set == nil {
+		ht.set = newHTMLSet(ht.funcs, ht.delims)
+	}
+
+	ht.sources = append(ht.sources, src)
+	defer ht.set.reset()
+
+	return ht.loadSource(ht.set, src)
+}
+
+// loadSource glob and parse template files of the source
+func (ht *HTMLTemplates) loadSource(hs *htmlSet, src source) error {
+	if src.fsys == nil {
+		return filepath.Walk(src.root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
 
-	err = fs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
+			if info.IsDir() {
+				return nil
+			}
+
+			return ht.loadFile(hs, nil, src.root, path)
+		})
+	}
+
+	return fs.WalkDir(src.fsys, src.root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -97,14 +151,12 @@ func (ht *HTMLTemplates) LoadFS(fsys fs.FS, root string) (err error) {
 			return nil
 		}
 
-		return ht.loadFile(fsys, root, path)
+		return ht.loadFile(hs, src.fsys, src.root, path)
 	})
-
-	return
 }
 
 // loadFile load template file
-func (ht *HTMLTemplates) loadFile(fsys fs.FS, root, path string) error {
+func (ht *HTMLTemplates) loadFile(hs *htmlSet, fsys fs.FS, root, path string) error {
 	ext := filepath.Ext(path)
 	if !asg.Contains(ht.extensions, ext) {
 		return nil
@@ -115,11 +167,84 @@ func (ht *HTMLTemplates) loadFile(fsys fs.FS, root, path string) error {
 		return err
 	}
 
-	path = toTemplateName(root, path, ext)
+	name := toTemplateName(root, path, ext)
+
+	if err = hs.add(name, text); err != nil {
+		return fmt.Errorf("tpl: failed to parse template '%s', error: %w", path, err)
+	}
+	return nil
+}
+
+// Reload re-parse all template files of the loaded sources into a new template set,
+// and replace the current template set atomically.
+// If failed, the current template set is kept, and the error can be retrieved by LoadError().
+func (ht *HTMLTemplates) Reload() error {
+	ht.mutex.RLock()
+	sources := ht.sources
+	ht.mutex.RUnlock()
+
+	hs := newHTMLSet(ht.funcs, ht.delims)
+	for _, src := range sources {
+		if err := ht.loadSource(hs, src); err != nil {
+			ht.mutex.Lock()
+			ht.error = err
+			ht.mutex.Unlock()
+			return err
+		}
+	}
+
+	ht.mutex.Lock()
+	ht.set, ht.error = hs, nil
+	ht.mutex.Unlock()
+	return nil
+}
+
+// LoadError returns the last Reload error, or nil if the last Reload succeeded.
+func (ht *HTMLTemplates) LoadError() error {
+	ht.mutex.RLock()
+	defer ht.mutex.RUnlock()
+
+	return ht.error
+}
+
+// Watch watch the loaded template root paths (except the FS sources) with the file watcher,
+// and Reload the templates when a template file is changed (development mode).
+// The callback is called after reload if it is not nil.
+func (ht *HTMLTemplates) Watch(fw *fsw.FileWatcher, callbacks ...func(error)) error {
+	ht.mutex.RLock()
+	sources := ht.sources
+	ht.mutex.RUnlock()
+
+	cb := func(path string, op fsw.Op) {
+		if ext := filepath.Ext(path); ext != "" && !asg.Contains(ht.extensions, ext) {
+			return
+		}
+
+		err := ht.Reload()
+		for _, fn := range callbacks {
+			fn(err)
+		}
+	}
+
+	var errs []error
+	for _, src := range sources {
+		if src.fsys == nil {
+			if err := fw.AddRecursive(filepath.FromSlash(src.root), fsw.OpModifies, cb); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate validate all template files of the loaded sources (production mode),
+// reports the parse errors, the undefined template references and the missing functions.
+func (ht *HTMLTemplates) Validate() error {
+	ht.mutex.RLock()
+	sources := ht.sources
+	ht.mutex.RUnlock()
 
-	tpl := ht.template.New(path)
-	_, err = tpl.Parse(text)
-	return err
+	return validate(sources, ht.extensions, ht.delims, ht.funcs)
 }
 
 // Render render template with io.Writer
@@ -129,17 +254,17 @@ func (ht *HTMLTemplates) loadFile(fsys fs.FS, root, path string) error {
 // 2. "hello.zh.tpl"
 // 3. "hello.tpl"
 func (ht *HTMLTemplates) Render(w io.Writer, locale, name string, data any) error {
-	for locale != "" {
-		if t := ht.template.Lookup(name + "_" + locale); t != nil {
-			return t.Execute(w, data)
-		}
+	ht.mutex.RLock()
+	hs := ht.set
+	ht.mutex.RUnlock()
 
-		if l2, _, ok := str.LastCutByte(locale, '-'); ok {
-			locale = l2
-		} else {
-			break
-		}
+	if hs == nil {
+		return errors.New("tpl: no template loaded")
 	}
 
-	return ht.template.ExecuteTemplate(w, name, data)
+	tpl, err := hs.lookup(locale, name)
+	if err != nil {
+		return err
+	}
+	return tpl.ExecuteTemplate(w, name, data)
 }
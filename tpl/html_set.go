@@ -0,0 +1,519 @@
+package tpl
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/askasoft/pango/asg"
+	"github.com/askasoft/pango/str"
+	"golang.org/x/text/language"
+)
+
+// htmlPage a template which extends a parent template
+type htmlPage struct {
+	parent string
+	text   string
+}
+
+// htmlProp a declared component prop
+type htmlProp struct {
+	name     string
+	kind     string
+	optional bool
+}
+
+var htmlPropKinds = []string{"any", "string", "int", "float", "bool", "html", "slice", "map"}
+
+// htmlFile a parsed template file
+type htmlFile struct {
+	name string
+	tpl  *template.Template
+}
+
+// htmlSet a html template set with the layout inheritance and the components.
+// The template files are parsed separately, and a template set is assembled for each locale (and each extending page),
+// so the locale variant templates (and their blocks) override the general templates only for the locale.
+type htmlSet struct {
+	delims  Delims
+	funcs   template.FuncMap
+	files   []*htmlFile            // the parsed template files in load order
+	pages   map[string]*htmlPage   // the templates which extends a parent template
+	props   map[string][]*htmlProp // the component props
+	locales map[string]bool        // the locale suffixes of the template names
+
+	reExtends *regexp.Regexp
+	reProps   *regexp.Regexp
+
+	mutex sync.Mutex
+	bases map[string]*template.Template // the assembled template set of the locale, the source of the clones
+	cache map[string]*template.Template // the template set clones of the locale (and the extending page)
+}
+
+var reQuoted = regexp.MustCompile(`"([^"]*)"`)
+
+func newHTMLSet(funcs FuncMap, delims Delims) *htmlSet {
+	// the placeholder functions, replaced by the template set clone
+	fm := template.FuncMap{
+		"component": func(name string, args ...any) (template.HTML, error) {
+			return "", errors.New("tpl: component is not available")
+		},
+		"slot": func(name string, data any) (template.HTML, error) {
+			return "", errors.New("tpl: slot is not available")
+		},
+	}
+	for k, v := range funcs {
+		fm[k] = v
+	}
+
+	left, right := regexp.QuoteMeta(delims.Left), regexp.QuoteMeta(delims.Right)
+
+	return &htmlSet{
+		delims:    delims,
+		funcs:     fm,
+		pages:     make(map[string]*htmlPage),
+		props:     make(map[string][]*htmlProp),
+		locales:   make(map[string]bool),
+		reExtends: regexp.MustCompile(left + `(-?)\s*extends\s+"([^"]+)"\s*(-?)` + right),
+		reProps:   regexp.MustCompile(left + `(-?)\s*props((?:\s+"[^"]*")+)\s*(-?)` + right),
+		bases:     make(map[string]*template.Template),
+		cache:     make(map[string]*template.Template),
+	}
+}
+
+// reset clear the cached template set clones
+func (hs *htmlSet) reset() {
+	hs.mutex.Lock()
+	clear(hs.bases)
+	clear(hs.cache)
+	hs.mutex.Unlock()
+}
+
+// cutDirective remove the directive matched by the regexp from the text,
+// returns the removed text and the submatches of the directive.
+func cutDirective(re *regexp.Regexp, text string) (string, []string) {
+	loc := re.FindStringSubmatchIndex(text)
+	if loc == nil {
+		return text, nil
+	}
+
+	sms := make([]string, len(loc)/2)
+	for i := range sms {
+		if loc[i*2] >= 0 {
+			sms[i] = text[loc[i*2]:loc[i*2+1]]
+		}
+	}
+
+	// apply the trim markers of the directive
+	head, tail := text[:loc[0]], text[loc[1]:]
+	if sms[1] == "-" {
+		head = strings.TrimRight(head, " \t\r\n")
+	}
+	if sms[len(sms)-1] == "-" {
+		tail = strings.TrimLeft(tail, " \t\r\n")
+	}
+
+	// keep the line numbers
+	return head + strings.Repeat("\n", strings.Count(sms[0], "\n")) + tail, sms
+}
+
+func parseProps(s string) ([]*htmlProp, error) {
+	var props []*htmlProp
+	for _, m := range reQuoted.FindAllStringSubmatch(s, -1) {
+		name, kind, _ := strings.Cut(m[1], ":")
+		if kind == "" {
+			kind = "any"
+		}
+
+		prop := &htmlProp{name: name, kind: kind}
+		if str.EndsWithByte(prop.kind, '?') {
+			prop.kind, prop.optional = prop.kind[:len(prop.kind)-1], true
+		}
+
+		if prop.name == "" || !asg.Contains(htmlPropKinds, prop.kind) {
+			return nil, fmt.Errorf("invalid prop %q", m[1])
+		}
+		props = append(props, prop)
+	}
+	return props, nil
+}
+
+// add parse the template text
+func (hs *htmlSet) add(name, text string) error {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+
+	if _, loc, ok := cutLocale(name); ok {
+		hs.locales[loc] = true
+	}
+
+	text, sms := cutDirective(hs.reProps, text)
+	if sms != nil {
+		props, err := parseProps(sms[2])
+		if err != nil {
+			return err
+		}
+		hs.props[name] = props
+	}
+
+	text, sms = cutDirective(hs.reExtends, text)
+
+	t, err := hs.newTemplate(name).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	if sms != nil {
+		hs.pages[name] = &htmlPage{parent: sms[2], text: text}
+		return nil
+	}
+
+	hs.files = append(hs.files, &htmlFile{name: name, tpl: t})
+	return nil
+}
+
+func (hs *htmlSet) newTemplate(name string) *template.Template {
+	return template.New(name).Delims(hs.delims.Left, hs.delims.Right).Funcs(hs.funcs)
+}
+
+// cutLocale cut the locale suffix "_<locale>" of the template name.
+// The suffix is a locale only if it is a known BCP 47 tag with a two-letter language (e.g. "ja", "zh-TW"),
+// so the names like "user_edit" or "user_row" are not treated as locale variants.
+func cutLocale(name string) (stem, locale string, ok bool) {
+	i := strings.LastIndexByte(name, '_')
+	if i <= 0 {
+		return
+	}
+
+	stem, locale = name[:i], name[i+1:]
+
+	lang, _, _ := strings.Cut(locale, "-")
+	if len(lang) != 2 {
+		return "", "", false
+	}
+	if _, err := language.Parse(locale); err != nil {
+		return "", "", false
+	}
+	return stem, locale, true
+}
+
+// variant returns the locale suffix if the template file is a locale variant of a defined template.
+func (hs *htmlSet) variant(name string) (string, bool) {
+	if stem, loc, ok := cutLocale(name); ok {
+		if _, ok := hs.pages[stem]; ok {
+			return loc, true
+		}
+		if asg.ContainsFunc(hs.files, func(f *htmlFile) bool { return f.name == stem }) {
+			return loc, true
+		}
+	}
+	return "", false
+}
+
+// localeChain returns the known locales of the locale, from specific to general.
+func (hs *htmlSet) localeChain(locale string) []string {
+	var chain []string
+	for locale != "" {
+		if hs.locales[locale] {
+			chain = append(chain, locale)
+		}
+
+		if l2, _, ok := str.LastCutByte(locale, '-'); ok {
+			locale = l2
+		} else {
+			break
+		}
+	}
+	return chain
+}
+
+// findPage find the extending page of the name for the locale chain.
+// Returns nil if the name (or the locale variant) is a normal template.
+func (hs *htmlSet) findPage(name string, chain []string) *htmlPage {
+	for _, loc := range chain {
+		n := name + "_" + loc
+		if p, ok := hs.pages[n]; ok {
+			return p
+		}
+		if asg.ContainsFunc(hs.files, func(f *htmlFile) bool { return f.name == n }) {
+			return nil
+		}
+	}
+	return hs.pages[name]
+}
+
+// findProps find the component props of the name for the locale chain.
+func (hs *htmlSet) findProps(name string, chain []string) ([]*htmlProp, bool) {
+	for _, loc := range chain {
+		if props, ok := hs.props[name+"_"+loc]; ok {
+			return props, true
+		}
+	}
+	props, ok := hs.props[name]
+	return props, ok
+}
+
+// lookup returns the template set clone to execute the template name for the locale
+func (hs *htmlSet) lookup(locale, name string) (*template.Template, error) {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+
+	chain := hs.localeChain(locale)
+
+	lkey := asg.First(chain)
+
+	key := lkey
+	page := hs.findPage(name, chain)
+	if page != nil {
+		key += "\x00" + name
+	}
+
+	if t, ok := hs.cache[key]; ok {
+		return t, nil
+	}
+
+	// the base is assembled once for the locale and never executed,
+	// the clones of the base share the assembling, and only add the blocks of the extending page.
+	base, ok := hs.bases[lkey]
+	if !ok {
+		var err error
+		if base, err = hs.assemble(chain); err != nil {
+			return nil, err
+		}
+		hs.bases[lkey] = base
+	}
+
+	t, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	if page != nil {
+		if err := hs.extend(t, name, chain); err != nil {
+			return nil, err
+		}
+	}
+
+	t.Funcs(template.FuncMap{
+		"component": func(name string, args ...any) (template.HTML, error) {
+			return hs.component(t, chain, name, args...)
+		},
+		"slot": func(name string, data any) (template.HTML, error) {
+			return executeHTML(t, name, data)
+		},
+	})
+
+	hs.cache[key] = t
+	return t, nil
+}
+
+// assemble assemble a template set from the parsed template files for the locale chain.
+// The templates of the locale variant files replace the general templates, from general to specific.
+// The nested templates (blocks) of a locale variant file override the general templates only for the locale,
+// but are always available if they are not defined by the general template files.
+func (hs *htmlSet) assemble(chain []string) (*template.Template, error) {
+	t := hs.newTemplate("")
+
+	add := func(name string, x *template.Template) error {
+		if x.Tree == nil {
+			return nil
+		}
+		_, err := t.AddParseTree(name, x.Tree.Copy())
+		return err
+	}
+
+	var variants []*htmlFile
+	for _, f := range hs.files {
+		if _, ok := hs.variant(f.name); ok {
+			variants = append(variants, f)
+			continue
+		}
+
+		for _, x := range f.tpl.Templates() {
+			if err := add(x.Name(), x); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, f := range variants {
+		for _, x := range f.tpl.Templates() {
+			if name := x.Name(); name == f.name || t.Lookup(name) == nil {
+				if err := add(name, x); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, f := range hs.files {
+			loc, ok := hs.variant(f.name)
+			if !ok || loc != chain[i] {
+				continue
+			}
+
+			for _, x := range f.tpl.Templates() {
+				name := x.Name()
+				if name == f.name {
+					name = name[:len(name)-len(loc)-1]
+				}
+				if err := add(name, x); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return t, nil
+}
+
+// extend parse the blocks of the extending page and its parent pages into the template set,
+// and replace the page template by the root layout template.
+func (hs *htmlSet) extend(t *template.Template, name string, chain []string) error {
+	var pages []*htmlPage
+
+	n := name
+	for {
+		p := hs.findPage(n, chain)
+		if p == nil {
+			break
+		}
+		if asg.Contains(pages, p) {
+			return fmt.Errorf("tpl: template %q extends cyclically", name)
+		}
+		pages = append(pages, p)
+		n = p.parent
+	}
+
+	root := t.Lookup(n)
+	if root == nil || root.Tree == nil {
+		return fmt.Errorf("tpl: template %q extends undefined template %q", name, n)
+	}
+
+	// parse from the root to the leaf, so the blocks of the leaf page win
+	for i := len(pages) - 1; i >= 0; i-- {
+		if _, err := t.New(fmt.Sprintf("%s#%d", name, i)).Parse(pages[i].text); err != nil {
+			return err
+		}
+	}
+
+	_, err := t.AddParseTree(name, root.Tree.Copy())
+	return err
+}
+
+// component execute the component template with the props and the default slot
+func (hs *htmlSet) component(t *template.Template, chain []string, name string, args ...any) (template.HTML, error) {
+	data := make(map[string]any)
+
+	if len(args) > 0 {
+		if m, ok := args[0].(map[string]any); ok {
+			for k, v := range m {
+				data[k] = v
+			}
+			args = args[1:]
+		}
+	}
+
+	if len(args)&1 != 0 {
+		data["Slot"] = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+
+	for i := 0; i < len(args); i += 2 {
+		k, ok := args[i].(string)
+		if !ok {
+			return "", fmt.Errorf("tpl: component %q: invalid prop name %v", name, args[i])
+		}
+		data[k] = args[i+1]
+	}
+
+	hs.mutex.Lock()
+	props, ok := hs.findProps(name, chain)
+	hs.mutex.Unlock()
+
+	if ok {
+		if err := checkProps(name, props, data); err != nil {
+			return "", err
+		}
+	}
+
+	return executeHTML(t, name, data)
+}
+
+func checkProps(name string, props []*htmlProp, data map[string]any) error {
+	for k := range data {
+		if k == "Slot" {
+			continue
+		}
+		if !asg.ContainsFunc(props, func(p *htmlProp) bool { return p.name == k }) {
+			return fmt.Errorf("tpl: component %q: unknown prop %q", name, k)
+		}
+	}
+
+	for _, p := range props {
+		v, ok := data[p.name]
+		if !ok || v == nil {
+			if !p.optional {
+				return fmt.Errorf("tpl: component %q: missing required prop %q", name, p.name)
+			}
+			data[p.name] = zeroProp(p.kind)
+			continue
+		}
+
+		if !checkPropKind(p.kind, v) {
+			return fmt.Errorf("tpl: component %q: prop %q must be %s, but got %T", name, p.name, p.kind, v)
+		}
+	}
+	return nil
+}
+
+func zeroProp(kind string) any {
+	switch kind {
+	case "string":
+		return ""
+	case "int":
+		return 0
+	case "float":
+		return 0.0
+	case "bool":
+		return false
+	case "html":
+		return template.HTML("")
+	default:
+		return nil
+	}
+}
+
+func checkPropKind(kind string, v any) bool {
+	rk := reflect.TypeOf(v).Kind()
+
+	switch kind {
+	case "string", "html":
+		return rk == reflect.String
+	case "int":
+		return (rk >= reflect.Int && rk <= reflect.Uint64) || rk == reflect.Uintptr
+	case "float":
+		return (rk >= reflect.Int && rk <= reflect.Uint64) || rk == reflect.Float32 || rk == reflect.Float64
+	case "bool":
+		return rk == reflect.Bool
+	case "slice":
+		return rk == reflect.Slice || rk == reflect.Array
+	case "map":
+		return rk == reflect.Map
+	default:
+		return true
+	}
+}
+
+// executeHTML execute the named template and returns the result html
+func executeHTML(t *template.Template, name string, data any) (template.HTML, error) {
+	sb := &strings.Builder{}
+	if err := t.ExecuteTemplate(sb, name, data); err != nil {
+		return "", err
+	}
+	return template.HTML(sb.String()), nil //nolint: gosec
+}
@@ -0,0 +1,263 @@
+package tpl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testWriteFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, text := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func testRender(t *testing.T, ht *HTMLTemplates, locale, name string, data any) string {
+	t.Helper()
+
+	sb := &strings.Builder{}
+	if err := ht.Render(sb, locale, name, data); err != nil {
+		t.Fatalf("Render(%q, %q) = %v", locale, name, err)
+	}
+	return sb.String()
+}
+
+func TestHTMLTemplatesExtends(t *testing.T) {
+	dir := testWriteFiles(t, map[string]string{
+		"layout/main.html":    `<title>{{block "title" .}}Default{{end}}</title>{{template "nav" .}}<main>{{block "content" .}}{{end}}</main>`,
+		"layout/main_ja.html": `<title lang="ja">{{block "title" .}}デフォルト{{end}}</title>{{template "nav" .}}<main>{{block "content" .}}{{end}}</main>`,
+		"layout/nav.html":     `{{define "nav"}}<nav>{{block "menu" .}}home{{end}}</nav>{{end}}`,
+		"layout/admin.html": `{{extends "layout/main"}}
+{{define "menu"}}admin{{end}}
+{{define "content"}}<section>{{block "admin" .}}{{end}}</section>{{end}}`,
+		"index.html": `{{- extends "layout/main" -}}
+ignored
+{{define "title"}}Index{{end}}
+{{define "content"}}<p>{{.Message}}</p>{{end}}`,
+		"index_ja.html": `{{extends "layout/main"}}{{define "content"}}<p>こんにちは {{.Message}}</p>{{end}}`,
+		"users.html": `{{extends "layout/admin"}}
+{{define "title"}}Users{{end}}
+{{define "admin"}}<ul><li>{{.Message}}</li></ul>{{end}}`,
+		"plain.html": `<p>{{.Message}}</p>`,
+	})
+
+	ht := testNewHtmlTpls()
+	if err := ht.Load(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	data := map[string]any{"Message": "world"}
+
+	cs := []struct {
+		locale string
+		name   string
+		want   string
+	}{
+		{"", "index", `<title>Index</title><nav>home</nav><main><p>world</p></main>`},
+		{"en", "index", `<title>Index</title><nav>home</nav><main><p>world</p></main>`},
+		{"ja-JP", "index", `<title lang="ja">デフォルト</title><nav>home</nav><main><p>こんにちは world</p></main>`},
+		{"", "users", `<title>Users</title><nav>admin</nav><main><section><ul><li>world</li></ul></section></main>`},
+		{"ja", "users", `<title lang="ja">Users</title><nav>admin</nav><main><section><ul><li>world</li></ul></section></main>`},
+		{"", "layout/main", `<title>Default</title><nav>home</nav><main></main>`},
+		{"", "plain", `<p>world</p>`},
+	}
+
+	for i, c := range cs {
+		if a := testRender(t, ht, c.locale, c.name, data); a != c.want {
+			t.Errorf("#%d Render(%q, %q) = %q, want %q", i, c.locale, c.name, a, c.want)
+		}
+	}
+
+	// the blocks of the page does not affect the other pages
+	if a := testRender(t, ht, "", "index", data); !strings.Contains(a, "<nav>home</nav>") {
+		t.Errorf("Render(index) = %q", a)
+	}
+}
+
+func TestHTMLTemplatesPageClones(t *testing.T) {
+	dir := testWriteFiles(t, map[string]string{
+		"layout.html": `<h1>{{block "title" .}}Default{{end}}</h1>`,
+		"a.html":      `{{extends "layout"}}{{define "title"}}A{{end}}`,
+		"b.html":      `{{extends "layout"}}{{define "title"}}B{{end}}`,
+		"b_ja.html":   `{{extends "layout"}}{{define "title"}}ビー{{end}}`,
+	})
+
+	ht := testNewHtmlTpls()
+	if err := ht.Load(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	cs := []struct {
+		locale string
+		name   string
+		want   string
+	}{
+		{"", "a", "<h1>A</h1>"},
+		{"", "b", "<h1>B</h1>"},
+		{"", "layout", "<h1>Default</h1>"},
+		{"ja", "a", "<h1>A</h1>"},
+		{"ja", "b", "<h1>ビー</h1>"},
+		{"", "a", "<h1>A</h1>"},
+	}
+
+	for i, c := range cs {
+		if a := testRender(t, ht, c.locale, c.name, nil); a != c.want {
+			t.Errorf("#%d Render(%q, %q) = %q, want %q", i, c.locale, c.name, a, c.want)
+		}
+	}
+
+	// the base template set is assembled once for each locale
+	if n := len(ht.set.bases); n != 2 {
+		t.Errorf("len(bases) = %d, want 2", n)
+	}
+	if n := len(ht.set.cache); n != 5 {
+		t.Errorf("len(cache) = %d, want 5", n)
+	}
+}
+
+func TestHTMLTemplatesUnderscoreName(t *testing.T) {
+	dir := testWriteFiles(t, map[string]string{
+		"admin/user.html":      `USER`,
+		"admin/user_edit.html": `EDIT {{template "row" .}}{{define "row"}}ROW{{end}}`,
+		"admin/user_ja.html":   `ユーザー`,
+	})
+
+	ht := testNewHtmlTpls()
+	if err := ht.Load(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	cs := []struct {
+		locale string
+		name   string
+		want   string
+	}{
+		{"", "admin/user_edit", "EDIT ROW"},
+		{"ja", "admin/user_edit", "EDIT ROW"},
+		{"", "admin/user", "USER"},
+		{"ja", "admin/user", "ユーザー"},
+		{"edit", "admin/user", "USER"},
+	}
+
+	for i, c := range cs {
+		if a := testRender(t, ht, c.locale, c.name, nil); a != c.want {
+			t.Errorf("#%d Render(%q, %q) = %q, want %q", i, c.locale, c.name, a, c.want)
+		}
+	}
+}
+
+func TestHTMLTemplatesVariantBlocks(t *testing.T) {
+	dir := testWriteFiles(t, map[string]string{
+		"list.html":    `<ul>{{template "item" .}}</ul>`,
+		"list_ja.html": `<ol>{{template "item" .}}</ol>{{define "item"}}<li>項目</li>{{end}}`,
+	})
+
+	ht := testNewHtmlTpls()
+	if err := ht.Load(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// the nested template only defined by the variant file is available for all locales
+	if a, w := testRender(t, ht, "", "list", nil), `<ul><li>項目</li></ul>`; a != w {
+		t.Errorf("Render(list) = %q, want %q", a, w)
+	}
+	if a, w := testRender(t, ht, "ja", "list", nil), `<ol><li>項目</li></ol>`; a != w {
+		t.Errorf("Render(ja, list) = %q, want %q", a, w)
+	}
+}
+
+func TestHTMLTemplatesExtendsError(t *testing.T) {
+	dir := testWriteFiles(t, map[string]string{
+		"a.html": `{{extends "missing"}}`,
+		"b.html": `{{extends "c"}}`,
+		"c.html": `{{extends "b"}}`,
+	})
+
+	ht := testNewHtmlTpls()
+	if err := ht.Load(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"a", "b"} {
+		if err := ht.Render(&strings.Builder{}, "", name, nil); err == nil {
+			t.Errorf("Render(%q) should return error", name)
+		}
+	}
+
+	if err := ht.Validate(); err == nil || !strings.Contains(err.Error(), `template "missing" not defined`) {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestHTMLTemplatesComponent(t *testing.T) {
+	dir := testWriteFiles(t, map[string]string{
+		"components/card.html": `{{- props "title:string" "count:int?" "footer:html?" -}}
+<div><h3>{{.title}} ({{.count}})</h3>{{.Slot}}{{.footer}}</div>`,
+		"components/card_ja.html": `{{- props "title:string" "count:int?" "footer:html?" -}}
+<div><h3>{{.title}}（{{.count}}件）</h3>{{.Slot}}{{.footer}}</div>`,
+		"components/badge.html": `<b>{{.text}}</b>`,
+		"page.html": `{{define "body"}}<p>{{.Message}}</p>{{end}}{{define "foot"}}<i>{{.}}</i>{{end -}}
+{{component "components/card" "title" "Hello" "count" 3 (slot "body" .)}}
+{{component "components/card" (Map "title" "<x>" "footer" (slot "foot" "f"))}}
+{{component "components/badge" "text" .Message}}`,
+		"bad1.html": `{{component "components/card" "count" 1}}`,
+		"bad2.html": `{{component "components/card" "title" 1}}`,
+		"bad3.html": `{{component "components/card" "title" "a" "unknown" 1}}`,
+	})
+
+	ht := testNewHtmlTpls()
+	if err := ht.Load(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	data := map[string]any{"Message": "world"}
+
+	want := `<div><h3>Hello (3)</h3><p>world</p></div>
+<div><h3>&lt;x&gt; (0)</h3><i>f</i></div>
+<b>world</b>`
+	if a := testRender(t, ht, "", "page", data); a != want {
+		t.Errorf("Render(page) = %q, want %q", a, want)
+	}
+
+	want = `<div><h3>Hello（3件）</h3><p>world</p></div>
+<div><h3>&lt;x&gt;（0件）</h3><i>f</i></div>
+<b>world</b>`
+	if a := testRender(t, ht, "ja-JP", "page", data); a != want {
+		t.Errorf("Render(ja-JP, page) = %q, want %q", a, want)
+	}
+
+	for name, msg := range map[string]string{
+		"bad1": `missing required prop "title"`,
+		"bad2": `prop "title" must be string`,
+		"bad3": `unknown prop "unknown"`,
+	} {
+		err := ht.Render(&strings.Builder{}, "", name, data)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("Render(%q) = %v, want %q", name, err, msg)
+		}
+	}
+
+	if err := ht.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestHTMLTemplatesInvalidProps(t *testing.T) {
+	dir := testWriteFiles(t, map[string]string{
+		"c.html": `{{props "a:unknown"}}`,
+	})
+
+	ht := testNewHtmlTpls()
+	if err := ht.Load(dir); err == nil {
+		t.Error("Load() should return error")
+	}
+}
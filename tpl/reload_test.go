@@ -0,0 +1,127 @@
+package tpl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/askasoft/pango/fsw"
+)
+
+func testRenderString(t *testing.T, ht *HTMLTemplates, name string, want string) {
+	t.Helper()
+
+	sb := &strings.Builder{}
+	if err := ht.Render(sb, "", name, nil); err != nil {
+		t.Fatalf("Render(%q) = %v", name, err)
+	}
+	if sb.String() != want {
+		t.Errorf("Render(%q) = %q, want %q", name, sb.String(), want)
+	}
+}
+
+func TestHTMLTemplatesReload(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.html")
+
+	os.WriteFile(page, []byte("v1"), 0o600)
+
+	ht := testNewHtmlTpls()
+	if err := ht.Load(dir); err != nil {
+		t.Fatal(err)
+	}
+	testRenderString(t, ht, "page", "v1")
+
+	os.WriteFile(page, []byte("v2"), 0o600)
+	if err := ht.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	testRenderString(t, ht, "page", "v2")
+
+	// parse error keeps the old template set
+	os.WriteFile(page, []byte("{{if}}"), 0o600)
+	if err := ht.Reload(); err == nil || ht.LoadError() == nil {
+		t.Fatalf("Reload() should return error")
+	}
+	testRenderString(t, ht, "page", "v2")
+
+	os.WriteFile(page, []byte("v3"), 0o600)
+	if err := ht.Reload(); err != nil || ht.LoadError() != nil {
+		t.Fatalf("Reload() = %v", err)
+	}
+	testRenderString(t, ht, "page", "v3")
+}
+
+func TestHTMLTemplatesWatch(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.html")
+
+	os.WriteFile(page, []byte("v1"), 0o600)
+
+	ht := testNewHtmlTpls()
+	if err := ht.Load(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	fw := fsw.NewFileWatcher(time.Millisecond * 10)
+	defer fw.Close()
+
+	reloaded := make(chan error, 10)
+	if err := ht.Watch(fw, func(err error) { reloaded <- err }); err != nil {
+		t.Fatal(err)
+	}
+	if err := fw.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(page, []byte("v2"), 0o600)
+
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatalf("reload error: %v", err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("template is not reloaded")
+	}
+	testRenderString(t, ht, "page", "v2")
+}
+
+func TestHTMLTemplatesValidate(t *testing.T) {
+	ht := testNewHtmlTpls()
+	if err := ht.Load("testdata"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ht.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.html"), []byte(`{{define "part"}}{{Upper .}}{{end}}{{template "part" .}}{{template "missing" .}}`), 0o600)
+	os.WriteFile(filepath.Join(dir, "b.html"), []byte(`{{if .X}}{{NoSuchFunc .X | printf "%s"}}{{else}}{{template "a" .}}{{end}}`), 0o600)
+	os.WriteFile(filepath.Join(dir, "c.html"), []byte(`{{range .}}`), 0o600)
+
+	ht = testNewHtmlTpls()
+	if err := ht.LoadFS(os.DirFS(dir), "."); err == nil {
+		t.Fatal("LoadFS() should return error")
+	}
+
+	err := ht.Validate()
+	if err == nil {
+		t.Fatal("Validate() should return error")
+	}
+
+	msg := err.Error()
+	for _, s := range []string{`template "missing" not defined`, `function "NoSuchFunc" not defined`, `c.html`} {
+		if !strings.Contains(msg, s) {
+			t.Errorf("Validate() = %q, does not contain %q", msg, s)
+		}
+	}
+	for _, s := range []string{`"part" not defined`, `"a" not defined`, `"Upper" not defined`, `"printf" not defined`} {
+		if strings.Contains(msg, s) {
+			t.Errorf("Validate() = %q, should not contain %q", msg, s)
+		}
+	}
+}
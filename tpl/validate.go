@@ -0,0 +1,193 @@
+package tpl
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template/parse"
+
+	"github.com/askasoft/pango/asg"
+)
+
+// builtinFuncs the builtin functions of text/template
+var builtinFuncs = map[string]any{
+	"and": nil, "call": nil, "html": nil, "index": nil, "slice": nil, "js": nil, "len": nil,
+	"not": nil, "or": nil, "print": nil, "printf": nil, "println": nil, "urlquery": nil,
+	"eq": nil, "ge": nil, "gt": nil, "le": nil, "lt": nil, "ne": nil,
+	"extends": nil, "props": nil, "component": nil, "slot": nil,
+}
+
+type validator struct {
+	funcs   FuncMap
+	trees   map[string]*parse.Tree // defined templates
+	sources map[string]string      // template name -> file path
+	errs    []error
+}
+
+// validate parse all template files of the sources without function check,
+// and reports the parse errors, the undefined template references and the missing functions.
+func validate(sources []source, extensions []string, delims Delims, funcs FuncMap) error {
+	v := &validator{
+		funcs:   funcs,
+		trees:   make(map[string]*parse.Tree),
+		sources: make(map[string]string),
+	}
+
+	for _, src := range sources {
+		walk := func(path string, isDir bool) error {
+			if isDir {
+				return nil
+			}
+
+			ext := filepath.Ext(path)
+			if !asg.Contains(extensions, ext) {
+				return nil
+			}
+
+			text, err := readFile(src.fsys, path)
+			if err != nil {
+				return err
+			}
+
+			v.parse(path, toTemplateName(src.root, path, ext), text, delims)
+			return nil
+		}
+
+		var err error
+		if src.fsys == nil {
+			err = filepath.Walk(src.root, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				return walk(path, info.IsDir())
+			})
+		} else {
+			err = fs.WalkDir(src.fsys, src.root, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				return walk(path, d.IsDir())
+			})
+		}
+		if err != nil {
+			v.errs = append(v.errs, err)
+		}
+	}
+
+	names := make([]string, 0, len(v.trees))
+	for name := range v.trees {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if t := v.trees[name]; t.Root != nil {
+			v.walk(name, t.Root)
+		}
+	}
+
+	return errors.Join(v.errs...)
+}
+
+func (v *validator) parse(path, name, text string, delims Delims) {
+	t := parse.New(name)
+	t.Mode = parse.SkipFuncCheck | parse.ParseComments
+
+	trees := make(map[string]*parse.Tree)
+	if _, err := t.Parse(text, delims.Left, delims.Right, trees); err != nil {
+		v.errs = append(v.errs, fmt.Errorf("tpl: %s: %w", path, err))
+		return
+	}
+
+	for n, t := range trees {
+		v.trees[n] = t
+		v.sources[n] = path
+	}
+}
+
+func (v *validator) errorf(name string, node parse.Node, format string, args ...any) {
+	loc := v.sources[name]
+	if t := v.trees[name]; t != nil {
+		if l, _ := t.ErrorContext(node); l != "" {
+			loc = l
+		}
+	}
+	v.errs = append(v.errs, fmt.Errorf("tpl: %s: %s", loc, fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) walk(name string, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			for _, c := range n.Nodes {
+				v.walk(name, c)
+			}
+		}
+	case *parse.ActionNode:
+		v.walk(name, n.Pipe)
+	case *parse.IfNode:
+		v.walkBranch(name, &n.BranchNode)
+	case *parse.RangeNode:
+		v.walkBranch(name, &n.BranchNode)
+	case *parse.WithNode:
+		v.walkBranch(name, &n.BranchNode)
+	case *parse.TemplateNode:
+		if _, ok := v.trees[n.Name]; !ok {
+			v.errorf(name, n, "template %q not defined", n.Name)
+		}
+		if n.Pipe != nil {
+			v.walk(name, n.Pipe)
+		}
+	case *parse.PipeNode:
+		if n != nil {
+			for _, c := range n.Cmds {
+				v.walk(name, c)
+			}
+		}
+	case *parse.CommandNode:
+		v.checkTarget(name, n)
+		for _, a := range n.Args {
+			v.walk(name, a)
+		}
+	case *parse.ChainNode:
+		v.walk(name, n.Node)
+	case *parse.IdentifierNode:
+		if _, ok := v.funcs[n.Ident]; !ok {
+			if _, ok := builtinFuncs[n.Ident]; !ok {
+				v.errorf(name, n, "function %q not defined", n.Ident)
+			}
+		}
+	}
+}
+
+// checkTarget check the template name argument of the extends, component and slot functions
+func (v *validator) checkTarget(name string, n *parse.CommandNode) {
+	if len(n.Args) < 2 {
+		return
+	}
+
+	id, ok := n.Args[0].(*parse.IdentifierNode)
+	if !ok {
+		return
+	}
+
+	switch id.Ident {
+	case "extends", "component", "slot":
+		if s, ok := n.Args[1].(*parse.StringNode); ok {
+			if _, ok := v.trees[s.Text]; !ok {
+				v.errorf(name, s, "template %q not defined", s.Text)
+			}
+		}
+	}
+}
+
+func (v *validator) walkBranch(name string, n *parse.BranchNode) {
+	v.walk(name, n.Pipe)
+	v.walk(name, n.List)
+	if n.ElseList != nil {
+		v.walk(name, n.ElseList)
+	}
+}
@@ -13,6 +13,12 @@ const (
 	MIMEPlain             = "text/plain"
 	MIMEPOSTForm          = "application/x-www-form-urlencoded"
 	MIMEMultipartPOSTForm = "multipart/form-data"
+	MIMEYAML              = "application/x-yaml"
+	MIMEYAML2             = "application/yaml"
+	MIMEMSGPACK           = "application/x-msgpack"
+	MIMEMSGPACK2          = "application/msgpack"
+	MIMECBOR              = "application/cbor"
+	MIMETOML              = "application/toml"
 )
 
 // Binding describes the interface which needs to be implemented for binding the
@@ -49,6 +55,10 @@ var (
 	URI           URIBinding  = uriBinding{}
 	Header        Binding     = headerBinding{}
 	Plain         BodyBinding = plainBinding{}
+	YAML          BodyBinding = yamlBinding{}
+	MsgPack       BodyBinding = msgpackBinding{}
+	CBOR          BodyBinding = cborBinding{}
+	TOML          BodyBinding = tomlBinding{}
 )
 
 // Default returns the appropriate Binding instance based on the HTTP method
@@ -63,6 +73,14 @@ func Default(method, contentType string) Binding {
 		return JSON
 	case MIMEXML, MIMEXML2:
 		return XML
+	case MIMEYAML, MIMEYAML2:
+		return YAML
+	case MIMEMSGPACK, MIMEMSGPACK2:
+		return MsgPack
+	case MIMECBOR:
+		return CBOR
+	case MIMETOML:
+		return TOML
 	case MIMEMultipartPOSTForm:
 		return FormMultipart
 	default: // case MIMEPOSTForm:
@@ -0,0 +1,44 @@
+package binding
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+type CborBindError struct {
+	Err error
+}
+
+// Error return a string representing the bind error
+func (cbe *CborBindError) Error() string {
+	return fmt.Sprintf("CborBindError: %v", cbe.Err)
+}
+
+func (cbe *CborBindError) Unwrap() error {
+	return cbe.Err
+}
+
+type cborBinding struct{}
+
+func (cborBinding) Name() string {
+	return "cbor"
+}
+
+func (cborBinding) Bind(req *http.Request, obj any) error {
+	return decodeCBOR(req.Body, obj)
+}
+
+func (cborBinding) BindBody(body []byte, obj any) error {
+	return decodeCBOR(bytes.NewReader(body), obj)
+}
+
+func decodeCBOR(r io.Reader, obj any) error {
+	if err := cbor.NewDecoder(r).Decode(obj); err != nil {
+		return &CborBindError{err}
+	}
+	return nil
+}
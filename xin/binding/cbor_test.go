@@ -0,0 +1,42 @@
+package binding
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/askasoft/pango/test/assert"
+	"github.com/askasoft/pango/test/require"
+	"github.com/fxamacker/cbor/v2"
+)
+
+func TestCBORBindingBindBody(t *testing.T) {
+	type teststruct struct {
+		Foo string `cbor:"foo"`
+	}
+
+	bs, err := cbor.Marshal(teststruct{"FOO"})
+	require.NoError(t, err)
+
+	var s teststruct
+	err = cborBinding{}.BindBody(bs, &s)
+	require.NoError(t, err)
+	assert.Equal(t, "FOO", s.Foo)
+}
+
+func TestCBORBindingBind(t *testing.T) {
+	bs, err := cbor.Marshal(map[string]any{"Foo": "bar"})
+	require.NoError(t, err)
+
+	var s FooStruct
+	req, _ := http.NewRequest(http.MethodPost, "/", bytes.NewReader(bs))
+	err = CBOR.Bind(req, &s)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", s.Foo)
+
+	req, _ = http.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte{0xff}))
+	err = CBOR.Bind(req, &s)
+	var cbe *CborBindError
+	assert.True(t, errors.As(err, &cbe))
+}
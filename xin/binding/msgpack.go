@@ -0,0 +1,44 @@
+package binding
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type MsgPackBindError struct {
+	Err error
+}
+
+// Error return a string representing the bind error
+func (mbe *MsgPackBindError) Error() string {
+	return fmt.Sprintf("MsgPackBindError: %v", mbe.Err)
+}
+
+func (mbe *MsgPackBindError) Unwrap() error {
+	return mbe.Err
+}
+
+type msgpackBinding struct{}
+
+func (msgpackBinding) Name() string {
+	return "msgpack"
+}
+
+func (msgpackBinding) Bind(req *http.Request, obj any) error {
+	return decodeMsgPack(req.Body, obj)
+}
+
+func (msgpackBinding) BindBody(body []byte, obj any) error {
+	return decodeMsgPack(bytes.NewReader(body), obj)
+}
+
+func decodeMsgPack(r io.Reader, obj any) error {
+	if err := msgpack.NewDecoder(r).Decode(obj); err != nil {
+		return &MsgPackBindError{err}
+	}
+	return nil
+}
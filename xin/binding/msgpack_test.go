@@ -0,0 +1,42 @@
+package binding
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/askasoft/pango/test/assert"
+	"github.com/askasoft/pango/test/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestMsgPackBindingBindBody(t *testing.T) {
+	type teststruct struct {
+		Foo string `msgpack:"foo"`
+	}
+
+	bs, err := msgpack.Marshal(teststruct{"FOO"})
+	require.NoError(t, err)
+
+	var s teststruct
+	err = msgpackBinding{}.BindBody(bs, &s)
+	require.NoError(t, err)
+	assert.Equal(t, "FOO", s.Foo)
+}
+
+func TestMsgPackBindingBind(t *testing.T) {
+	bs, err := msgpack.Marshal(FooStruct{"bar"})
+	require.NoError(t, err)
+
+	var s FooStruct
+	req, _ := http.NewRequest(http.MethodPost, "/", bytes.NewReader(bs))
+	err = MsgPack.Bind(req, &s)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", s.Foo)
+
+	req, _ = http.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte{0xc1}))
+	err = MsgPack.Bind(req, &s)
+	var mbe *MsgPackBindError
+	assert.True(t, errors.As(err, &mbe))
+}
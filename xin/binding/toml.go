@@ -0,0 +1,44 @@
+package binding
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+type TomlBindError struct {
+	Err error
+}
+
+// Error return a string representing the bind error
+func (tbe *TomlBindError) Error() string {
+	return fmt.Sprintf("TomlBindError: %v", tbe.Err)
+}
+
+func (tbe *TomlBindError) Unwrap() error {
+	return tbe.Err
+}
+
+type tomlBinding struct{}
+
+func (tomlBinding) Name() string {
+	return "toml"
+}
+
+func (tomlBinding) Bind(req *http.Request, obj any) error {
+	return decodeTOML(req.Body, obj)
+}
+
+func (tomlBinding) BindBody(body []byte, obj any) error {
+	return decodeTOML(bytes.NewReader(body), obj)
+}
+
+func decodeTOML(r io.Reader, obj any) error {
+	if err := toml.NewDecoder(r).Decode(obj); err != nil {
+		return &TomlBindError{err}
+	}
+	return nil
+}
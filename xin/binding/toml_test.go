@@ -0,0 +1,35 @@
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/askasoft/pango/test/assert"
+	"github.com/askasoft/pango/test/require"
+)
+
+func TestTOMLBindingBindBody(t *testing.T) {
+	var s struct {
+		Foo string `toml:"foo"`
+	}
+	err := tomlBinding{}.BindBody([]byte(`foo = "FOO"`), &s)
+	require.NoError(t, err)
+	assert.Equal(t, "FOO", s.Foo)
+}
+
+func TestTOMLBindingBind(t *testing.T) {
+	var s struct {
+		Foo []int `toml:"foo"`
+	}
+	req, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader("foo = [1, 2]"))
+	err := TOML.Bind(req, &s)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, s.Foo)
+
+	req, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader("foo = ["))
+	err = TOML.Bind(req, &s)
+	var tbe *TomlBindError
+	assert.True(t, errors.As(err, &tbe))
+}
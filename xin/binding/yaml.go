@@ -0,0 +1,44 @@
+package binding
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.yaml.in/yaml/v3"
+)
+
+type YamlBindError struct {
+	Err error
+}
+
+// Error return a string representing the bind error
+func (ybe *YamlBindError) Error() string {
+	return fmt.Sprintf("YamlBindError: %v", ybe.Err)
+}
+
+func (ybe *YamlBindError) Unwrap() error {
+	return ybe.Err
+}
+
+type yamlBinding struct{}
+
+func (yamlBinding) Name() string {
+	return "yaml"
+}
+
+func (yamlBinding) Bind(req *http.Request, obj any) error {
+	return decodeYAML(req.Body, obj)
+}
+
+func (yamlBinding) BindBody(body []byte, obj any) error {
+	return decodeYAML(bytes.NewReader(body), obj)
+}
+
+func decodeYAML(r io.Reader, obj any) error {
+	if err := yaml.NewDecoder(r).Decode(obj); err != nil {
+		return &YamlBindError{err}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/askasoft/pango/test/assert"
+	"github.com/askasoft/pango/test/require"
+)
+
+func TestYAMLBindingBindBody(t *testing.T) {
+	var s struct {
+		Foo string `yaml:"foo"`
+	}
+	err := yamlBinding{}.BindBody([]byte("foo: FOO"), &s)
+	require.NoError(t, err)
+	assert.Equal(t, "FOO", s.Foo)
+}
+
+func TestYAMLBindingBind(t *testing.T) {
+	var s struct {
+		Foo []int `yaml:"foo"`
+	}
+	req, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader("foo:\n  - 1\n  - 2\n"))
+	err := YAML.Bind(req, &s)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, s.Foo)
+
+	req, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader("foo: [1, a"))
+	err = YAML.Bind(req, &s)
+	var ybe *YamlBindError
+	assert.True(t, errors.As(err, &ybe))
+}
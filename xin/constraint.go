@@ -0,0 +1,74 @@
+package xin
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/askasoft/pango/str"
+	"github.com/askasoft/pango/vad"
+)
+
+// ParamConstraints is the named constraints of the route parameters.
+// A constraint is declared after the parameter name within angle brackets, e.g. `:id<int>`.
+// A constraint which is not registered here is compiled as a regular expression
+// which must match the entire parameter value, e.g. `:slug<[a-z-]+>`.
+var ParamConstraints = map[string]func(string) bool{
+	"int":   isIntParam,
+	"uint":  str.IsNumber,
+	"alpha": str.IsLetter,
+	"alnum": str.IsLetterNumber,
+	"hex":   str.IsHexadecimal,
+	"uuid":  vad.IsUUID,
+}
+
+// regexConstraints caches the compiled regular expression constraints
+var regexConstraints sync.Map
+
+func isIntParam(s string) bool {
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	return str.IsNumber(s)
+}
+
+// splitParam splits the wildcard `:name<constraint>` to name and constraint.
+func splitParam(wildcard string) (name, constraint string) {
+	name = wildcard[1:]
+	if i := strings.IndexByte(name, '<'); i >= 0 {
+		name, constraint = name[:i], name[i+1:len(name)-1]
+	}
+	return
+}
+
+// compileParamConstraint compiles the constraint of the wildcard `:name<constraint>`.
+// Returns nil if the wildcard has no constraint.
+func compileParamConstraint(wildcard, fullPath string) func(string) bool {
+	name, constraint := splitParam(wildcard)
+	if len(name) == 0 {
+		panic("wildcards must be named with a non-empty name in path '" + fullPath + "'")
+	}
+	if constraint == "" {
+		if len(name)+1 < len(wildcard) {
+			panic("empty constraint of wildcard '" + wildcard + "' in path '" + fullPath + "'")
+		}
+		return nil
+	}
+
+	if fc, ok := ParamConstraints[constraint]; ok {
+		return fc
+	}
+
+	if fc, ok := regexConstraints.Load(constraint); ok {
+		return fc.(func(string) bool)
+	}
+
+	rx, err := regexp.Compile("^(?:" + constraint + ")$")
+	if err != nil {
+		panic("invalid constraint of wildcard '" + wildcard + "' in path '" + fullPath + "': " + err.Error())
+	}
+
+	fc := rx.MatchString
+	regexConstraints.Store(constraint, fc)
+	return fc
+}
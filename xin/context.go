@@ -17,6 +17,7 @@ import (
 	"github.com/askasoft/pango/net/httpx"
 	"github.com/askasoft/pango/net/httpx/sse"
 	"github.com/askasoft/pango/ref"
+	"github.com/askasoft/pango/tbs"
 	"github.com/askasoft/pango/xin/binding"
 	"github.com/askasoft/pango/xin/render"
 )
@@ -30,6 +31,12 @@ const (
 	MIMEPlain             = binding.MIMEPlain
 	MIMEPOSTForm          = binding.MIMEPOSTForm
 	MIMEMultipartPOSTForm = binding.MIMEMultipartPOSTForm
+	MIMEYAML              = binding.MIMEYAML
+	MIMEYAML2             = binding.MIMEYAML2
+	MIMEMSGPACK           = binding.MIMEMSGPACK
+	MIMEMSGPACK2          = binding.MIMEMSGPACK2
+	MIMECBOR              = binding.MIMECBOR
+	MIMETOML              = binding.MIMETOML
 )
 
 // BodyBytesKey indicates a default body bytes key.
@@ -677,8 +684,12 @@ func (c *Context) ReadUploadedFile(file *multipart.FileHeader) ([]byte, error) {
 // MustBind checks the Content-Type to select a binding engine automatically,
 // Depending on the "Content-Type" header different bindings are used:
 //
-//	"application/json" --> JSON binding
-//	"application/xml"  --> XML binding
+//	"application/json"    --> JSON binding
+//	"application/xml"     --> XML binding
+//	"application/x-yaml"  --> YAML binding
+//	"application/msgpack" --> MsgPack binding
+//	"application/cbor"    --> CBOR binding
+//	"application/toml"    --> TOML binding
 //
 // otherwise --> returns an error.
 // It parses the request's body as JSON if Content-Type == "application/json" using JSON or XML as a JSON input.
@@ -699,6 +710,26 @@ func (c *Context) MustBindXML(obj any) error {
 	return c.MustBindWith(obj, binding.XML)
 }
 
+// MustBindYAML is a shortcut for c.MustBindWith(obj, binding.YAML).
+func (c *Context) MustBindYAML(obj any) error {
+	return c.MustBindWith(obj, binding.YAML)
+}
+
+// MustBindMsgPack is a shortcut for c.MustBindWith(obj, binding.MsgPack).
+func (c *Context) MustBindMsgPack(obj any) error {
+	return c.MustBindWith(obj, binding.MsgPack)
+}
+
+// MustBindCBOR is a shortcut for c.MustBindWith(obj, binding.CBOR).
+func (c *Context) MustBindCBOR(obj any) error {
+	return c.MustBindWith(obj, binding.CBOR)
+}
+
+// MustBindTOML is a shortcut for c.MustBindWith(obj, binding.TOML).
+func (c *Context) MustBindTOML(obj any) error {
+	return c.MustBindWith(obj, binding.TOML)
+}
+
 // MustBindQuery is a shortcut for c.MustBindWith(obj, binding.Query).
 func (c *Context) MustBindQuery(obj any) error {
 	return c.MustBindWith(obj, binding.Query)
@@ -738,8 +769,12 @@ func (c *Context) MustBindWith(obj any, b binding.Binding) error {
 // Bind checks the Content-Type to select a binding engine automatically,
 // Depending on the "Content-Type" header different bindings are used:
 //
-//	"application/json" --> JSON binding
-//	"application/xml"  --> XML binding
+//	"application/json"    --> JSON binding
+//	"application/xml"     --> XML binding
+//	"application/x-yaml"  --> YAML binding
+//	"application/msgpack" --> MsgPack binding
+//	"application/cbor"    --> CBOR binding
+//	"application/toml"    --> TOML binding
 //
 // otherwise --> returns an error
 // It parses the request's body as JSON if Content-Type == "application/json" using JSON or XML as a JSON input.
@@ -760,6 +795,26 @@ func (c *Context) BindXML(obj any) error {
 	return c.BindWith(obj, binding.XML)
 }
 
+// BindYAML is a shortcut for c.BindWith(obj, binding.YAML).
+func (c *Context) BindYAML(obj any) error {
+	return c.BindWith(obj, binding.YAML)
+}
+
+// BindMsgPack is a shortcut for c.BindWith(obj, binding.MsgPack).
+func (c *Context) BindMsgPack(obj any) error {
+	return c.BindWith(obj, binding.MsgPack)
+}
+
+// BindCBOR is a shortcut for c.BindWith(obj, binding.CBOR).
+func (c *Context) BindCBOR(obj any) error {
+	return c.BindWith(obj, binding.CBOR)
+}
+
+// BindTOML is a shortcut for c.BindWith(obj, binding.TOML).
+func (c *Context) BindTOML(obj any) error {
+	return c.BindWith(obj, binding.TOML)
+}
+
 // BindQuery is a shortcut for c.BindWith(obj, binding.Query).
 func (c *Context) BindQuery(obj any) error {
 	return c.BindWith(obj, binding.Query)
@@ -842,6 +897,26 @@ func (c *Context) BindBodyWithXML(obj any) error {
 	return c.BindBodyWith(obj, binding.XML)
 }
 
+// BindBodyWithYAML is a shortcut for c.BindBodyWith(obj, binding.YAML).
+func (c *Context) BindBodyWithYAML(obj any) error {
+	return c.BindBodyWith(obj, binding.YAML)
+}
+
+// BindBodyWithMsgPack is a shortcut for c.BindBodyWith(obj, binding.MsgPack).
+func (c *Context) BindBodyWithMsgPack(obj any) error {
+	return c.BindBodyWith(obj, binding.MsgPack)
+}
+
+// BindBodyWithCBOR is a shortcut for c.BindBodyWith(obj, binding.CBOR).
+func (c *Context) BindBodyWithCBOR(obj any) error {
+	return c.BindBodyWith(obj, binding.CBOR)
+}
+
+// BindBodyWithTOML is a shortcut for c.BindBodyWith(obj, binding.TOML).
+func (c *Context) BindBodyWithTOML(obj any) error {
+	return c.BindBodyWith(obj, binding.TOML)
+}
+
 // BindBodyWithPlain is a shortcut for c.BindBodyWith(obj, binding.Plain).
 func (c *Context) BindBodyWithPlain(obj any) error {
 	return c.BindBodyWith(obj, binding.Plain)
@@ -1067,6 +1142,30 @@ func (c *Context) XML(code int, obj any) {
 	c.Render(code, render.XML{Data: obj})
 }
 
+// YAML serializes the given struct as YAML into the response body.
+// It also sets the Content-Type as "application/x-yaml".
+func (c *Context) YAML(code int, obj any) {
+	c.Render(code, render.YAML{Data: obj})
+}
+
+// MsgPack serializes the given struct as MessagePack into the response body.
+// It also sets the Content-Type as "application/x-msgpack".
+func (c *Context) MsgPack(code int, obj any) {
+	c.Render(code, render.MsgPack{Data: obj})
+}
+
+// CBOR serializes the given struct as CBOR into the response body.
+// It also sets the Content-Type as "application/cbor".
+func (c *Context) CBOR(code int, obj any) {
+	c.Render(code, render.CBOR{Data: obj})
+}
+
+// TOML serializes the given struct as TOML into the response body.
+// It also sets the Content-Type as "application/toml".
+func (c *Context) TOML(code int, obj any) {
+	c.Render(code, render.TOML{Data: obj})
+}
+
 // String writes the given string into the response body.
 func (c *Context) String(code int, format string, values ...any) {
 	c.Render(code, render.String{Format: format, Data: values})
@@ -1090,12 +1189,16 @@ func (c *Context) Data(code int, contentType string, data []byte) {
 }
 
 // DataFromReader writes the specified reader into the body stream and updates the HTTP code.
+// If the contentLength is known and the reader is an io.ReaderAt or io.ReadSeeker,
+// the "Range" request header is supported. The "ETag" and "Last-Modified" of the extraHeaders
+// are used to evaluate the "If-Range" request header.
 func (c *Context) DataFromReader(code int, contentLength int64, contentType string, reader io.Reader, extraHeaders map[string]string) {
 	c.Render(code, render.Reader{
 		Headers:       extraHeaders,
 		ContentType:   contentType,
 		ContentLength: contentLength,
 		Reader:        reader,
+		Request:       c.Request,
 	})
 }
 
@@ -1161,18 +1264,33 @@ func (c *Context) Stream(step func(w io.Writer) bool) bool {
 	}
 }
 
+/************************************/
+/*********** LOCALIZATION ***********/
+/************************************/
+
+// T returns the localized text of the key for the context locale (c.Locale).
+// The text is formatted with the args by tbs.Format, which supports the ICU MessageFormat style
+// plural/select message, e.g. c.T("files.count", "count", 3).
+func (c *Context) T(key string, args ...any) string {
+	return tbs.Format(c.Locale, key, args...)
+}
+
 /************************************/
 /******** CONTENT NEGOTIATION *******/
 /************************************/
 
 // Negotiate contains all negotiations data.
 type Negotiate struct {
-	Offered  []string
-	HTMLName string
-	HTMLData any
-	JSONData any
-	XMLData  any
-	Data     any
+	Offered     []string
+	HTMLName    string
+	HTMLData    any
+	JSONData    any
+	XMLData     any
+	YAMLData    any
+	MsgPackData any
+	CBORData    any
+	TOMLData    any
+	Data        any
 }
 
 // Negotiate calls different Render according to acceptable Accept format.
@@ -1190,6 +1308,22 @@ func (c *Context) Negotiate(code int, config Negotiate) {
 		data := chooseData(config.XMLData, config.Data)
 		c.XML(code, data)
 
+	case binding.MIMEYAML, binding.MIMEYAML2:
+		data := chooseData(config.YAMLData, config.Data)
+		c.YAML(code, data)
+
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		data := chooseData(config.MsgPackData, config.Data)
+		c.MsgPack(code, data)
+
+	case binding.MIMECBOR:
+		data := chooseData(config.CBORData, config.Data)
+		c.CBOR(code, data)
+
+	case binding.MIMETOML:
+		data := chooseData(config.TOMLData, config.Data)
+		c.TOML(code, data)
+
 	default:
 		c.AbortWithError(http.StatusNotAcceptable, errors.New("the accepted formats are not offered by the server"))
 	}
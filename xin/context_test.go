@@ -12,6 +12,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"sync"
@@ -20,6 +21,7 @@ import (
 
 	"github.com/askasoft/pango/net/httpx/sse"
 	"github.com/askasoft/pango/net/netx"
+	"github.com/askasoft/pango/tbs"
 	"github.com/askasoft/pango/test/assert"
 	"github.com/askasoft/pango/xin/binding"
 )
@@ -933,6 +935,50 @@ func TestContextRenderXML(t *testing.T) {
 	assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
 }
 
+func TestContextRenderYAML(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.YAML(http.StatusCreated, H{"foo": "bar"})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "foo: bar\n", w.Body.String())
+	assert.Equal(t, "application/x-yaml; charset=utf-8", w.Header().Get("Content-Type"))
+}
+
+func TestContextRenderTOML(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.TOML(http.StatusCreated, H{"foo": "bar"})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "foo = 'bar'\n", w.Body.String())
+	assert.Equal(t, "application/toml; charset=utf-8", w.Header().Get("Content-Type"))
+}
+
+func TestContextRenderMsgPack(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.MsgPack(http.StatusCreated, H{"foo": "bar"})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, []byte{0x81, 0xa3, 'f', 'o', 'o', 0xa3, 'b', 'a', 'r'}, w.Body.Bytes())
+	assert.Equal(t, "application/x-msgpack", w.Header().Get("Content-Type"))
+}
+
+func TestContextRenderCBOR(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.CBOR(http.StatusCreated, H{"foo": "bar"})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, []byte{0xa1, 0x63, 'f', 'o', 'o', 0x63, 'b', 'a', 'r'}, w.Body.Bytes())
+	assert.Equal(t, "application/cbor", w.Header().Get("Content-Type"))
+}
+
 // Tests that no XML is rendered if code is 204
 func TestContextRenderNoContentXML(t *testing.T) {
 	w := httptest.NewRecorder()
@@ -1217,6 +1263,40 @@ func TestContextNegotiationWithXML(t *testing.T) {
 	assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
 }
 
+func TestContextNegotiationWithYAML(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "", nil)
+	c.Request.Header.Add("Accept", "application/yaml")
+
+	c.Negotiate(http.StatusOK, Negotiate{
+		Offered:  []string{MIMEJSON, MIMEYAML2},
+		YAMLData: H{"foo": "yaml"},
+		Data:     H{"foo": "bar"},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "foo: yaml\n", w.Body.String())
+	assert.Equal(t, "application/x-yaml; charset=utf-8", w.Header().Get("Content-Type"))
+}
+
+func TestContextBindWithYAML(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString("foo: FOO\nbar: BAR\n"))
+	c.Request.Header.Add("Content-Type", MIMEYAML)
+
+	var obj struct {
+		Foo string `yaml:"foo"`
+		Bar string `yaml:"bar"`
+	}
+	assert.NoError(t, c.Bind(&obj))
+	assert.Equal(t, "FOO", obj.Foo)
+	assert.Equal(t, "BAR", obj.Bar)
+	assert.Equal(t, 0, w.Body.Len())
+}
+
 func TestContextNegotiationWithHTML(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, router := CreateTestContext(w)
@@ -2017,6 +2097,38 @@ func TestContextRenderDataFromReaderNoHeaders(t *testing.T) {
 	assert.Equal(t, fmt.Sprintf("%d", contentLength), w.Header().Get("Content-Length"))
 }
 
+func TestContextRenderDataFromReaderRange(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Range", "bytes=6-")
+	c.Request.Header.Set("If-Range", `"v1"`)
+
+	body := "#!PNG some raw data"
+	extraHeaders := map[string]string{"ETag": `"v1"`}
+
+	c.DataFromReader(http.StatusOK, int64(len(body)), "image/png", strings.NewReader(body), extraHeaders)
+
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, body[6:], w.Body.String())
+	assert.Equal(t, "bytes 6-18/19", w.Header().Get("Content-Range"))
+	assert.Equal(t, "13", w.Header().Get("Content-Length"))
+}
+
+func TestContextRenderDataFromReaderRangeNotOK(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Range", "bytes=6-")
+
+	body := "not found"
+	c.DataFromReader(http.StatusNotFound, int64(len(body)), "text/plain", strings.NewReader(body), nil)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, body, w.Body.String())
+	assert.Equal(t, "", w.Header().Get("Content-Range"))
+}
+
 type TestResponseRecorder struct {
 	*httptest.ResponseRecorder
 	closeChannel chan bool
@@ -2328,3 +2440,27 @@ func TestCreateTestContextWithRouteParams(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, "hello xin", w.Body.String())
 }
+
+func TestContextT(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "message.ini"), []byte("[files]\ncount = {count, plural, one {# file} other {# files}}\n"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "message_ru.ini"), []byte("[files]\ncount = {count, plural, one {# файл} few {# файла} other {# файлов}}\n"), 0o600))
+
+	tb := tbs.NewTextBundles()
+	assert.NoError(t, tb.Load(dir))
+
+	dtb := tbs.Default()
+	tbs.SetDefault(tb)
+	defer tbs.SetDefault(dtb)
+
+	c, _ := CreateTestContext(httptest.NewRecorder())
+
+	c.Locale = "en"
+	assert.Equal(t, "1 file", c.T("files.count", "count", 1))
+	assert.Equal(t, "2 files", c.T("files.count", "count", 2))
+
+	c.Locale = "ru-RU"
+	assert.Equal(t, "3 файла", c.T("files.count", "count", 3))
+	assert.Equal(t, "5 файлов", c.T("files.count", "count", 5))
+	assert.Equal(t, "", c.T("files.none"))
+}
@@ -0,0 +1,110 @@
+package xin
+
+import (
+	"regexp"
+	"strings"
+)
+
+// hostTree holds the route trees of a virtual host
+type hostTree struct {
+	pattern string
+	regexp  *regexp.Regexp
+	names   []string
+	trees   methodTrees
+}
+
+// newHostTree compiles the host pattern, the `{name}` segment matches a label of the host name
+// and can be retrieved by Context.Param(name).
+func newHostTree(pattern string) *hostTree {
+	if pattern == "" {
+		panic("host pattern can not be empty")
+	}
+
+	ht := &hostTree{
+		pattern: pattern,
+		trees:   make(methodTrees, 0, 9),
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^(?i)")
+	for i, label := range strings.Split(pattern, ".") {
+		if i > 0 {
+			sb.WriteString(`\.`)
+		}
+
+		if len(label) > 2 && label[0] == '{' && label[len(label)-1] == '}' {
+			ht.names = append(ht.names, label[1:len(label)-1])
+			sb.WriteString(`([^.]+)`)
+			continue
+		}
+
+		if strings.ContainsAny(label, "{}") {
+			panic("invalid host pattern '" + pattern + "'")
+		}
+		sb.WriteString(regexp.QuoteMeta(label))
+	}
+	sb.WriteString("$")
+
+	ht.regexp = regexp.MustCompile(sb.String())
+	return ht
+}
+
+// match returns true and the host params if the host name matches the pattern
+func (ht *hostTree) match(host string) (Params, bool) {
+	if len(ht.names) == 0 {
+		return nil, strings.EqualFold(ht.pattern, host)
+	}
+
+	ms := ht.regexp.FindStringSubmatch(host)
+	if ms == nil {
+		return nil, false
+	}
+
+	ps := make(Params, len(ht.names))
+	for i, name := range ht.names {
+		ps[i] = Param{Key: name, Value: ms[i+1]}
+	}
+	return ps, true
+}
+
+// Host creates a new router group for the virtual host pattern, the routes added to the group
+// only serve the requests of the matched host.
+// The `{name}` label of the pattern matches any label of the request host name,
+// and the value can be retrieved by Context.Param(name).
+// The requests which do not match any host pattern are served by the routes of the engine.
+// For example:
+//
+//	api := router.Host("api.example.com")
+//	tenant := router.Host("{tenant}.example.com")
+func (engine *Engine) Host(pattern string, handlers ...HandlerFunc) *RouterGroup {
+	var host *hostTree
+	for _, ht := range engine.hosts {
+		if ht.pattern == pattern {
+			host = ht
+			break
+		}
+	}
+
+	if host == nil {
+		host = newHostTree(pattern)
+		engine.hosts = append(engine.hosts, host)
+	}
+
+	return &RouterGroup{
+		Handlers: engine.combineHandlers(handlers),
+		basePath: "/",
+		engine:   engine,
+		host:     host,
+	}
+}
+
+// matchHost returns the route trees and host params of the first matched host.
+// If no host matches, the engine's route trees are returned.
+func (engine *Engine) matchHost(host string) (methodTrees, Params) {
+	for _, ht := range engine.hosts {
+		if ps, ok := ht.match(host); ok {
+			return ht.trees, ps
+		}
+	}
+	return engine.trees, nil
+}
@@ -0,0 +1,83 @@
+package xin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/askasoft/pango/test/assert"
+)
+
+func performHostRequest(r http.Handler, host, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Host = host
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestEngineHost(t *testing.T) {
+	router := New()
+	router.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "default")
+	})
+
+	api := router.Host("api.example.com")
+	api.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "api")
+	})
+	api.Group("/v1").GET("/users/:id<int>", func(c *Context) {
+		c.String(http.StatusOK, "api user "+c.Param("id"))
+	})
+
+	tenant := router.Host("{tenant}.example.com")
+	tenant.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "tenant "+c.Param("tenant"))
+	})
+	tenant.GET("/users/:id", func(c *Context) {
+		c.String(http.StatusOK, "tenant "+c.Param("tenant")+" user "+c.Param("id"))
+	})
+
+	cs := []struct {
+		host string
+		path string
+		code int
+		body string
+	}{
+		{"example.com", "/", http.StatusOK, "default"},
+		{"api.example.com", "/", http.StatusOK, "api"},
+		{"API.Example.com:8080", "/", http.StatusOK, "api"},
+		{"api.example.com", "/v1/users/1", http.StatusOK, "api user 1"},
+		{"api.example.com", "/v1/users/a", http.StatusNotFound, "404 page not found"},
+		{"foo.example.com", "/", http.StatusOK, "tenant foo"},
+		{"foo.example.com", "/users/2", http.StatusOK, "tenant foo user 2"},
+		{"foo.bar.example.com", "/users/2", http.StatusNotFound, "404 page not found"},
+		{"example.com", "/users/2", http.StatusNotFound, "404 page not found"},
+	}
+
+	for i, c := range cs {
+		w := performHostRequest(router, c.host, c.path)
+		assert.Equal(t, c.code, w.Code, "[%d] %s%s", i, c.host, c.path)
+		if c.code == http.StatusOK {
+			assert.Equal(t, c.body, w.Body.String(), "[%d] %s%s", i, c.host, c.path)
+		}
+	}
+
+	assert.True(t, router.Host("api.example.com").host == api.host)
+
+	routes := router.Routes()
+	assert.Len(t, routes, 5)
+	assert.Equal(t, "", routes[0].Host)
+	assert.Equal(t, "api.example.com", routes[1].Host)
+	assert.Equal(t, "/v1/users/:id<int>", routes[2].Path)
+}
+
+func TestEngineHostInvalid(t *testing.T) {
+	router := New()
+	assert.Panics(t, func() {
+		router.Host("")
+	})
+	assert.Panics(t, func() {
+		router.Host("{a.example.com")
+	})
+}
@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/askasoft/pango/xin"
+)
+
+// AccessLogMetricsBuckets default latency histogram buckets in seconds
+var AccessLogMetricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// AccessLogMetricsContentType the content type of the Prometheus text exposition format
+const AccessLogMetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type accessLogMetricKey struct {
+	route  string
+	method string
+	status string
+}
+
+type accessLogMetric struct {
+	bounds  []float64 // upper bounds of the buckets when the metric is created
+	buckets []uint64  // non-cumulative count of each bucket
+	count   uint64
+	sum     float64
+}
+
+// AccessLogMetricsWriter aggregates the request counts and the latency histograms
+// by route (c.FullPath()), method and status class (2xx, 4xx, ...),
+// and exposes them as Prometheus metrics.
+//
+//	alm := NewAccessLogMetricsWriter()
+//	router.Use(NewAccessLogger(NewAccessLogMultiWriter(NewAccessLogWriter(w, "combined"), alm)).Handle)
+//	router.GET("/metrics", alm.Handle)
+type AccessLogMetricsWriter struct {
+	// Namespace the metric name prefix, default is "xin"
+	Namespace string
+
+	// Buckets the upper bounds of the latency histogram buckets in seconds, must be sorted in increasing order.
+	// The change of Buckets only applies to the metrics created after the change (or Reset()).
+	Buckets []float64
+
+	mutex   sync.Mutex
+	metrics map[accessLogMetricKey]*accessLogMetric
+}
+
+// NewAccessLogMetricsWriter create a metrics writer for AccessLogger
+func NewAccessLogMetricsWriter() *AccessLogMetricsWriter {
+	return &AccessLogMetricsWriter{
+		Namespace: "xin",
+		Buckets:   AccessLogMetricsBuckets,
+		metrics:   make(map[accessLogMetricKey]*accessLogMetric),
+	}
+}
+
+// Write aggregates the request of the context
+func (almw *AccessLogMetricsWriter) Write(c *xin.Context) {
+	latency := c.GetTime(AccessLogEndKey).Sub(c.GetTime(AccessLogStartKey)).Seconds()
+
+	key := accessLogMetricKey{
+		route:  c.FullPath(),
+		method: metricMethod(c.Request.Method),
+		status: strconv.Itoa(c.Writer.Status()/100) + "xx",
+	}
+
+	almw.mutex.Lock()
+	defer almw.mutex.Unlock()
+
+	if almw.metrics == nil {
+		almw.metrics = make(map[accessLogMetricKey]*accessLogMetric)
+	}
+
+	m, ok := almw.metrics[key]
+	if !ok {
+		m = &accessLogMetric{bounds: slices.Clone(almw.Buckets), buckets: make([]uint64, len(almw.Buckets))}
+		almw.metrics[key] = m
+	}
+
+	m.count++
+	m.sum += latency
+	for i, b := range m.bounds {
+		if latency <= b {
+			m.buckets[i]++
+			break
+		}
+	}
+}
+
+// Reset clears the aggregated metrics
+func (almw *AccessLogMetricsWriter) Reset() {
+	almw.mutex.Lock()
+	defer almw.mutex.Unlock()
+
+	almw.metrics = make(map[accessLogMetricKey]*accessLogMetric)
+}
+
+// Handle process xin request, writes the metrics in the Prometheus text exposition format
+func (almw *AccessLogMetricsWriter) Handle(c *xin.Context) {
+	c.Header("Content-Type", AccessLogMetricsContentType)
+	c.Status(http.StatusOK)
+	almw.WriteMetrics(c.Writer) //nolint: errcheck
+}
+
+// WriteMetrics writes the metrics in the Prometheus text exposition format
+func (almw *AccessLogMetricsWriter) WriteMetrics(w io.Writer) error {
+	almw.mutex.Lock()
+	defer almw.mutex.Unlock()
+
+	keys := make([]accessLogMetricKey, 0, len(almw.metrics))
+	for k := range almw.metrics {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b accessLogMetricKey) int {
+		if c := strings.Compare(a.route, b.route); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.method, b.method); c != 0 {
+			return c
+		}
+		return strings.Compare(a.status, b.status)
+	})
+
+	ns := almw.Namespace
+	if ns != "" {
+		ns += "_"
+	}
+
+	bw := bufio.NewWriter(w)
+
+	name := ns + "http_requests_total"
+	bw.WriteString("# HELP " + name + " The total number of HTTP requests.\n")
+	bw.WriteString("# TYPE " + name + " counter\n")
+	for _, k := range keys {
+		m := almw.metrics[k]
+		bw.WriteString(name + "{" + k.labels() + "} " + strconv.FormatUint(m.count, 10) + "\n")
+	}
+
+	name = ns + "http_request_duration_seconds"
+	bw.WriteString("# HELP " + name + " The HTTP request latencies in seconds.\n")
+	bw.WriteString("# TYPE " + name + " histogram\n")
+	for _, k := range keys {
+		m := almw.metrics[k]
+		labels := k.labels()
+
+		var cnt uint64
+		for i, b := range m.bounds {
+			cnt += m.buckets[i]
+			bw.WriteString(name + "_bucket{" + labels + `,le="` + formatMetricFloat(b) + `"} ` + strconv.FormatUint(cnt, 10) + "\n")
+		}
+		bw.WriteString(name + "_bucket{" + labels + `,le="+Inf"} ` + strconv.FormatUint(m.count, 10) + "\n")
+		bw.WriteString(name + "_sum{" + labels + "} " + formatMetricFloat(m.sum) + "\n")
+		bw.WriteString(name + "_count{" + labels + "} " + strconv.FormatUint(m.count, 10) + "\n")
+	}
+
+	return bw.Flush()
+}
+
+// metricMethod returns the method label of the HTTP method,
+// the unknown methods are mapped to "OTHER" to limit the cardinality of the metrics.
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
+var metricLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func (k accessLogMetricKey) labels() string {
+	return `route="` + metricLabelEscaper.Replace(k.route) +
+		`",method="` + metricLabelEscaper.Replace(k.method) +
+		`",status="` + k.status + `"`
+}
+
+func formatMetricFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
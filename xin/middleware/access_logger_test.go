@@ -7,8 +7,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/askasoft/pango/iox"
 	"github.com/askasoft/pango/str"
 	"github.com/askasoft/pango/xin"
 )
@@ -132,3 +135,218 @@ func TestJSONLog(t *testing.T) {
 	json.Unmarshal(buffer.Bytes(), &result)
 	assertJSONResult(t, result, 404, "GET", "/notfound")
 }
+
+func TestTextLogRedact(t *testing.T) {
+	router := xin.New()
+
+	buffer := new(bytes.Buffer)
+	altw := NewAccessLogWriter(buffer, "text:%m %u %q %h{Authorization} %h{Cookie} %H{Set-Cookie}%n")
+	router.Use(NewAccessLogger(altw).Handle)
+
+	router.Any("/example", func(c *xin.Context) {
+		c.SetCookie(&http.Cookie{Name: "sid", Value: "x"})
+	})
+
+	newReq := func() *http.Request {
+		req := httptest.NewRequest("GET", "/example?password=123&a=1", nil)
+		req.Header.Set("Authorization", "Bearer abc")
+		req.Header.Set("Cookie", "sid=abc")
+		return req
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), newReq())
+
+	want := "GET /example?password=%2A%2A%2A%2A%2A%2A&a=1 password=%2A%2A%2A%2A%2A%2A&a=1 ****** sid=****** sid=******" + iox.EOL
+	if buffer.String() != want {
+		t.Errorf("access log = %q, want %q", buffer.String(), want)
+	}
+
+	// disable the redaction
+	altw.(*AccessLogTextWriter).SetRedactor(nil)
+	buffer.Reset()
+	router.ServeHTTP(httptest.NewRecorder(), newReq())
+
+	want = "GET /example?password=123&a=1 password=123&a=1 Bearer abc sid=abc sid=x" + iox.EOL
+	if buffer.String() != want {
+		t.Errorf("access log = %q, want %q", buffer.String(), want)
+	}
+}
+
+func TestAccessLogRedactDefault(t *testing.T) {
+	cs := []string{
+		"json:{%u, %h{Authorization}}",
+		"w3c:cs-uri-stem cs-uri-query cs(Authorization)",
+	}
+
+	for i, c := range cs {
+		router := xin.New()
+
+		buffer := new(bytes.Buffer)
+		router.Use(NewAccessLogger(NewAccessLogWriter(buffer, c)).Handle)
+		router.Any("/example", func(c *xin.Context) {})
+
+		req := httptest.NewRequest("GET", "/example?password=123&a=1", nil)
+		req.Header.Set("Authorization", "Bearer abc")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		if s := buffer.String(); !strings.Contains(s, "/example") || strings.Contains(s, "123") || strings.Contains(s, "abc") {
+			t.Errorf("#%d %q access log is not redacted: %q", i, c, s)
+		}
+	}
+}
+
+type testAuthUser string
+
+func (u testAuthUser) GetUsername() string {
+	return string(u)
+}
+
+func (u testAuthUser) GetPassword() string {
+	return ""
+}
+
+func TestApacheLog(t *testing.T) {
+	router := xin.New()
+
+	buffer := new(bytes.Buffer)
+	router.Use(NewAccessLogger(NewAccessLogMultiWriter(
+		NewAccessLogWriter(buffer, "common"),
+		NewAccessLogWriter(buffer, "combined"),
+	)).Handle)
+
+	router.GET("/example", func(c *xin.Context) {
+		c.Set(AuthUserKey, testAuthUser("alice"))
+		c.String(http.StatusOK, "hello")
+	})
+	router.GET("/empty", func(c *xin.Context) {})
+
+	req := httptest.NewRequest("GET", "/example?a=1", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("User-Agent", `Go "test"`)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), iox.EOL)
+	if len(lines) != 2 {
+		t.Fatalf("lines = %q", lines)
+	}
+
+	common := regexp.MustCompile(`^10\.0\.0\.1 - alice \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [-+]\d{4}\] "GET /example\?a=1 HTTP/1\.1" 200 5$`)
+	if !common.MatchString(lines[0]) {
+		t.Errorf("common log = %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], lines[0]) || !strings.HasSuffix(lines[1], ` 200 5 "-" "Go \"test\""`) {
+		t.Errorf("combined log = %q", lines[1])
+	}
+
+	buffer.Reset()
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/empty", nil))
+	if !strings.Contains(buffer.String(), ` - - [`) || !strings.Contains(buffer.String(), `" 200 -`) {
+		t.Errorf("common log = %q", buffer.String())
+	}
+}
+
+func TestW3CLog(t *testing.T) {
+	router := xin.New()
+
+	buffer := new(bytes.Buffer)
+	router.Use(NewAccessLogger(NewAccessLogWriter(buffer, "w3c:date c-ip cs-method cs-uri-stem cs-uri-query sc-status sc-bytes cs(User-Agent) sc(X-Res) x-unknown")).Handle)
+
+	router.GET("/example", func(c *xin.Context) {
+		c.Header("X-Res", "a b")
+		c.String(http.StatusOK, "hello")
+	})
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/example?a=1", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("User-Agent", "Go test")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), iox.EOL)
+	if len(lines) != 5 {
+		t.Fatalf("lines = %q", lines)
+	}
+
+	if lines[0] != "#Version: 1.0" || !strings.HasPrefix(lines[1], "#Date: ") {
+		t.Errorf("directives = %q", lines[:2])
+	}
+	if lines[2] != "#Fields: date c-ip cs-method cs-uri-stem cs-uri-query sc-status sc-bytes cs(User-Agent) sc(X-Res) x-unknown" {
+		t.Errorf("fields = %q", lines[2])
+	}
+
+	want := regexp.MustCompile(`^\d{4}-\d{2}-\d{2} 10\.0\.0\.1 GET /example a=1 200 5 Go\+test a\+b -$`)
+	for _, l := range lines[3:] {
+		if !want.MatchString(l) {
+			t.Errorf("w3c log = %q", l)
+		}
+	}
+}
+
+func TestMetricsLog(t *testing.T) {
+	router := xin.New()
+
+	alm := NewAccessLogMetricsWriter()
+	alm.Buckets = []float64{0.5, 1}
+	router.Use(NewAccessLogger(alm).Handle)
+
+	router.GET("/users/:id", func(c *xin.Context) {
+		if c.Param("id") == "0" {
+			c.Status(http.StatusNotFound)
+		}
+	})
+	router.GET("/metrics", alm.Handle)
+
+	for _, id := range []string{"1", "2", "0"} {
+		logPerformRequest(router, "GET", "/users/"+id)
+	}
+
+	w := logPerformRequest(router, "GET", "/metrics")
+	if ct := w.Header().Get("Content-Type"); ct != AccessLogMetricsContentType {
+		t.Errorf("content type = %q", ct)
+	}
+
+	body := w.Body.String()
+	for _, s := range []string{
+		"# TYPE xin_http_requests_total counter\n",
+		`xin_http_requests_total{route="/users/:id",method="GET",status="2xx"} 2` + "\n",
+		`xin_http_requests_total{route="/users/:id",method="GET",status="4xx"} 1` + "\n",
+		"# TYPE xin_http_request_duration_seconds histogram\n",
+		`xin_http_request_duration_seconds_bucket{route="/users/:id",method="GET",status="2xx",le="0.5"} 2` + "\n",
+		`xin_http_request_duration_seconds_bucket{route="/users/:id",method="GET",status="2xx",le="1"} 2` + "\n",
+		`xin_http_request_duration_seconds_bucket{route="/users/:id",method="GET",status="2xx",le="+Inf"} 2` + "\n",
+		`xin_http_request_duration_seconds_count{route="/users/:id",method="GET",status="4xx"} 1` + "\n",
+	} {
+		if !strings.Contains(body, s) {
+			t.Errorf("metrics does not contain %q\n%s", s, body)
+		}
+	}
+
+	// the metrics created before the change of Buckets keep their bounds
+	alm.Buckets = []float64{0.1, 0.5, 1, 5}
+	logPerformRequest(router, "GET", "/users/3")
+	logPerformRequest(router, "PROPFIND", "/users/3")
+
+	bb := &bytes.Buffer{}
+	_ = alm.WriteMetrics(bb)
+	for _, s := range []string{
+		`xin_http_requests_total{route="/users/:id",method="GET",status="2xx"} 3` + "\n",
+		`xin_http_request_duration_seconds_bucket{route="/users/:id",method="GET",status="2xx",le="1"} 3` + "\n",
+		`xin_http_requests_total{route="",method="OTHER",status="4xx"} 1` + "\n",
+		`xin_http_request_duration_seconds_bucket{route="",method="OTHER",status="4xx",le="0.1"} 1` + "\n",
+	} {
+		if !strings.Contains(bb.String(), s) {
+			t.Errorf("metrics does not contain %q\n%s", s, bb.String())
+		}
+	}
+	if strings.Contains(bb.String(), `method="GET",status="2xx",le="0.1"`) {
+		t.Errorf("metrics should keep the old buckets\n%s", bb.String())
+	}
+
+	alm.Reset()
+	bb.Reset()
+	_ = alm.WriteMetrics(bb)
+	if strings.Contains(bb.String(), "/users/:id") {
+		t.Errorf("metrics is not reset: %s", bb.String())
+	}
+}
@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/askasoft/pango/iox"
+	"github.com/askasoft/pango/xin"
+)
+
+// AccessLogW3CFields default fields of the W3C Extended Log File Format
+var AccessLogW3CFields = []string{
+	"date", "time", "c-ip", "cs-username", "cs-method", "cs-uri-stem", "cs-uri-query",
+	"sc-status", "sc-bytes", "time-taken", "cs-version", "cs-host", "cs(User-Agent)", "cs(Referer)",
+}
+
+// NewAccessLogW3CWriter create a W3C Extended Log File Format writer for AccessLogger.
+// If fields is empty, AccessLogW3CFields is used.
+//
+//	date - Request start date (UTC), yyyy-mm-dd
+//	time - Request start time (UTC), hh:mm:ss
+//	c-ip - Client IP
+//	s-ip - Server listen address
+//	cs-username - Authenticated user name
+//	cs-method - Request method
+//	cs-uri - Request URL
+//	cs-uri-stem - Request path
+//	cs-uri-query - Query string
+//	cs-version - Request protocol
+//	cs-host - Request host
+//	sc-status - Response status code
+//	sc-bytes - Response body length
+//	time-taken - Time taken to process the request in seconds
+//	cs(name) - Request header
+//	sc(name) - Response header
+//
+// The unknown field and the empty value is written as "-".
+func NewAccessLogW3CWriter(writer io.Writer, fields ...string) *AccessLogW3CWriter {
+	alww := &AccessLogW3CWriter{accessLogRedactor: accessLogRedactor{NewRedactor()}, writer: writer}
+	alww.SetFields(fields...)
+	return alww
+}
+
+// AccessLogW3CWriter format(W3C Extended Log File Format) and write access log
+type AccessLogW3CWriter struct {
+	accessLogRedactor
+
+	writer  io.Writer
+	fields  []string
+	formats []fmtfunc
+	mutex   sync.Mutex
+	started bool
+}
+
+// SetOutput set the access log writer, the directives are written again to the new writer.
+func (alww *AccessLogW3CWriter) SetOutput(w io.Writer) {
+	alww.mutex.Lock()
+	defer alww.mutex.Unlock()
+
+	alww.writer = w
+	alww.started = false
+}
+
+// SetFields set the fields of the access log
+func (alww *AccessLogW3CWriter) SetFields(fields ...string) {
+	if len(fields) == 0 {
+		fields = AccessLogW3CFields
+	}
+
+	fmts := make([]fmtfunc, len(fields))
+	for i, f := range fields {
+		fmts[i] = alww.fieldFormat(f)
+	}
+
+	alww.mutex.Lock()
+	defer alww.mutex.Unlock()
+
+	alww.fields = fields
+	alww.formats = fmts
+	alww.started = false
+}
+
+func (alww *AccessLogW3CWriter) fieldFormat(field string) fmtfunc {
+	switch field {
+	case "date":
+		return func(c *xin.Context) string {
+			return c.GetTime(AccessLogStartKey).UTC().Format("2006-01-02")
+		}
+	case "time":
+		return func(c *xin.Context) string {
+			return c.GetTime(AccessLogStartKey).UTC().Format("15:04:05")
+		}
+	case "c-ip":
+		return clientIP
+	case "s-ip":
+		return listenAddr
+	case "cs-username":
+		return authUser
+	case "cs-method":
+		return requestMethod
+	case "cs-uri":
+		return alww.requestURL
+	case "cs-uri-stem":
+		return func(c *xin.Context) string {
+			return c.Request.URL.EscapedPath()
+		}
+	case "cs-uri-query":
+		return alww.requestQuery
+	case "cs-version":
+		return requestProto
+	case "cs-host":
+		return requestHost
+	case "sc-status":
+		return statusCode
+	case "sc-bytes":
+		return apacheBodyLen
+	case "time-taken":
+		return func(c *xin.Context) string {
+			d := c.GetTime(AccessLogEndKey).Sub(c.GetTime(AccessLogStartKey))
+			return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+		}
+	}
+
+	if len(field) > 4 && field[2] == '(' && field[len(field)-1] == ')' {
+		name := field[3 : len(field)-1]
+		switch field[:2] {
+		case "cs":
+			return alww.requestHeader(name)
+		case "sc":
+			return alww.responseHeader(name)
+		}
+	}
+
+	return strfmtc("-")
+}
+
+// w3cEscaper replaces the white spaces and the quotes of the field value
+var w3cEscaper = strings.NewReplacer(" ", "+", "\t", "+", "\r", "+", "\n", "+", `"`, "'")
+
+// Write write the access log
+func (alww *AccessLogW3CWriter) Write(c *xin.Context) {
+	alww.mutex.Lock()
+	defer alww.mutex.Unlock()
+
+	bb := &bytes.Buffer{}
+	for i, f := range alww.formats {
+		if i > 0 {
+			bb.WriteByte(' ')
+		}
+
+		v := f(c)
+		if v == "" {
+			v = "-"
+		}
+		w3cEscaper.WriteString(bb, v) //nolint: errcheck
+	}
+	bb.WriteString(iox.EOL)
+
+	if !alww.started {
+		alww.started = true
+		alww.writeDirectives()
+	}
+
+	alww.writer.Write(bb.Bytes()) //nolint: errcheck
+}
+
+func (alww *AccessLogW3CWriter) writeDirectives() {
+	bb := &bytes.Buffer{}
+	bb.WriteString("#Version: 1.0")
+	bb.WriteString(iox.EOL)
+	bb.WriteString("#Date: ")
+	bb.WriteString(time.Now().UTC().Format("2006-01-02 15:04:05"))
+	bb.WriteString(iox.EOL)
+	bb.WriteString("#Fields: ")
+	bb.WriteString(strings.Join(alww.fields, " "))
+	bb.WriteString(iox.EOL)
+
+	alww.writer.Write(bb.Bytes()) //nolint: errcheck
+}
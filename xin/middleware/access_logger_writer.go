@@ -26,6 +26,14 @@ const AccessLogTextFormat = "text:%t\t%S\t%D\t%B\t%c\t%r\t%m\t%s://%h%u\t%h{User
 // AccessLogJSONFormat default json log format
 const AccessLogJSONFormat = `json:{"when": %t, "server": %H, "status": %S, "latency": %T, "size": %B, "client_ip": %c, "remote_addr": %r, "method": %m, "scheme": %s, "host": %h, "url": %u, "user_agent": %h{User-Agent}}%n`
 
+// AccessLogCommonFormat Apache Common Log Format
+// CLIENT_IP - USER [TIME] "METHOD URL PROTO" STATUS SIZE
+const AccessLogCommonFormat = `text:%c %l %U [%t{02/Jan/2006:15:04:05 -0700}] "%m %u %p" %S %b%n`
+
+// AccessLogCombinedFormat Apache Combined Log Format
+// CLIENT_IP - USER [TIME] "METHOD URL PROTO" STATUS SIZE "REFERER" "USER_AGENT"
+const AccessLogCombinedFormat = `text:%c %l %U [%t{02/Jan/2006:15:04:05 -0700}] "%m %u %p" %S %b "%i{Referer}" "%i{User-Agent}"%n`
+
 // AccessLogWriter access log writer for XIN
 //
 //	%t{format} - Request start time, if {format} is omitted, '2006-01-02T15:04:05.000Z07:00' is used.
@@ -38,11 +46,15 @@ const AccessLogJSONFormat = `json:{"when": %t, "server": %H, "status": %S, "late
 //	%q - Query string (prepended with a '?' if it exists)
 //	%h - Request host
 //	%h{name} - Request header
+//	%i{name} - Request header, '"' is escaped and "-" is used if the header is empty (Apache style)
+//	%l - Remote logname, always "-" (Apache style)
+//	%U - Authenticated user name, "-" if not authenticated
 //	%A - Server listen address
 //	%D - Time taken to process the request, duration format string
 //	%T - Time taken to process the request, number in milliseconds
 //	%S - Response status code
 //	%B - Response body length (%L)
+//	%b - Response body length, "-" if no content is sent (Apache style)
 //	%H - Local hostname
 //	%H{name} - Response header
 //	%n: EOL(Windows: "\r\n", Other: "\n")
@@ -68,9 +80,25 @@ func (mw *AccessLogMultiWriter) Write(c *xin.Context) {
 }
 
 // NewAccessLogWriter create a text or json access log writer
+// common -> AccessLogTextWriter with AccessLogCommonFormat
+// combined -> AccessLogTextWriter with AccessLogCombinedFormat
+// w3c -> AccessLogW3CWriter with AccessLogW3CFields
+// w3c:... -> AccessLogW3CWriter with space separated fields
 // text:... -> AccessLogTextWriter
 // json:... -> AccessLogJSONWriter
 func NewAccessLogWriter(writer io.Writer, format string) AccessLogWriter {
+	switch format {
+	case "common":
+		format = AccessLogCommonFormat
+	case "combined":
+		format = AccessLogCombinedFormat
+	case "w3c":
+		return NewAccessLogW3CWriter(writer)
+	}
+
+	if strings.HasPrefix(format, "w3c:") {
+		return NewAccessLogW3CWriter(writer, strings.Fields(format[4:])...)
+	}
 	if strings.HasPrefix(format, "text:") {
 		return NewAccessLogTextWriter(writer, format[5:])
 	}
@@ -82,15 +110,70 @@ func NewAccessLogWriter(writer io.Writer, format string) AccessLogWriter {
 
 type fmtfunc func(c *xin.Context) string
 
+// accessLogRedactor redacts the request url, query and headers of the access log
+type accessLogRedactor struct {
+	redactor *Redactor
+}
+
+// SetRedactor set the redactor to redact the request url, query string and headers.
+// The default is NewRedactor(), set nil to disable the redaction.
+func (alr *accessLogRedactor) SetRedactor(rd *Redactor) {
+	alr.redactor = rd
+}
+
+func (alr *accessLogRedactor) requestURL(c *xin.Context) string {
+	if rd := alr.redactor; rd != nil {
+		return rd.RedactURL(c.Request.URL)
+	}
+	return requestURL(c)
+}
+
+func (alr *accessLogRedactor) requestQuery(c *xin.Context) string {
+	if rd := alr.redactor; rd != nil {
+		return rd.RedactQuery(c.Request.URL.RawQuery)
+	}
+	return requestQuery(c)
+}
+
+func (alr *accessLogRedactor) requestHeader(name string) fmtfunc {
+	return func(c *xin.Context) string {
+		if rd := alr.redactor; rd != nil {
+			return rd.RedactHeaderValue(name, c.Request.Header.Get(name))
+		}
+		return c.Request.Header.Get(name)
+	}
+}
+
+func (alr *accessLogRedactor) apacheHeader(name string) fmtfunc {
+	rh := alr.requestHeader(name)
+	return func(c *xin.Context) string {
+		if v := rh(c); v != "" {
+			return apacheEscaper.Replace(v)
+		}
+		return "-"
+	}
+}
+
+func (alr *accessLogRedactor) responseHeader(name string) fmtfunc {
+	return func(c *xin.Context) string {
+		if rd := alr.redactor; rd != nil {
+			return rd.RedactHeaderValue(name, c.Writer.Header().Get(name))
+		}
+		return c.Writer.Header().Get(name)
+	}
+}
+
 // NewAccessLogTextWriter create text style writer for AccessLogger
 func NewAccessLogTextWriter(writer io.Writer, format string) *AccessLogTextWriter {
-	altw := &AccessLogTextWriter{writer: writer}
+	altw := &AccessLogTextWriter{accessLogRedactor: accessLogRedactor{NewRedactor()}, writer: writer}
 	altw.SetFormat(format)
 	return altw
 }
 
 // AccessLogTextWriter format(text) and write access log
 type AccessLogTextWriter struct {
+	accessLogRedactor
+
 	writer  io.Writer
 	formats []fmtfunc
 }
@@ -135,7 +218,7 @@ func (altw *AccessLogTextWriter) SetFormat(format string) {
 		case 'r', 'a':
 			fmt = remoteAddr
 		case 'u':
-			fmt = requestURL
+			fmt = altw.requestURL
 		case 'p':
 			fmt = requestProto
 		case 's':
@@ -143,14 +226,20 @@ func (altw *AccessLogTextWriter) SetFormat(format string) {
 		case 'm':
 			fmt = requestMethod
 		case 'q':
-			fmt = requestQuery
+			fmt = altw.requestQuery
 		case 'h':
 			p := getFormatOption(format, &i)
 			if p != "" {
-				fmt = requestHeader(p)
+				fmt = altw.requestHeader(p)
 			} else {
 				fmt = requestHost
 			}
+		case 'i':
+			fmt = altw.apacheHeader(getFormatOption(format, &i))
+		case 'l':
+			fmt = strfmtc("-")
+		case 'U':
+			fmt = authUser
 		case 't':
 			p := getFormatOption(format, &i)
 			if p == "" {
@@ -167,10 +256,12 @@ func (altw *AccessLogTextWriter) SetFormat(format string) {
 			fmt = latencyMillis
 		case 'B', 'L':
 			fmt = responseBodyLen
+		case 'b':
+			fmt = apacheBodyLen
 		case 'H':
 			p := getFormatOption(format, &i)
 			if p != "" {
-				fmt = responseHeader(p)
+				fmt = altw.responseHeader(p)
 			} else {
 				s, _ := os.Hostname()
 				fmt = strfmtc(s)
@@ -194,13 +285,15 @@ func (altw *AccessLogTextWriter) SetFormat(format string) {
 
 // NewAccessLogJSONWriter create json style writer for AccessLogger
 func NewAccessLogJSONWriter(writer io.Writer, format string) *AccessLogJSONWriter {
-	aljw := &AccessLogJSONWriter{writer: writer}
+	aljw := &AccessLogJSONWriter{accessLogRedactor: accessLogRedactor{NewRedactor()}, writer: writer}
 	aljw.SetFormat(format)
 	return aljw
 }
 
 // AccessLogJSONWriter format(json-style) and write access log
 type AccessLogJSONWriter struct {
+	accessLogRedactor
+
 	writer  io.Writer
 	formats []fmtfunc
 }
@@ -245,7 +338,7 @@ func (aljw *AccessLogJSONWriter) SetFormat(format string) {
 		case 'r', 'a':
 			fmt = quotefmtc(remoteAddr)
 		case 'u':
-			fmt = quotefmtc(requestURL)
+			fmt = quotefmtc(aljw.requestURL)
 		case 'p':
 			fmt = quotefmtc(requestProto)
 		case 's':
@@ -253,15 +346,19 @@ func (aljw *AccessLogJSONWriter) SetFormat(format string) {
 		case 'm':
 			fmt = quotefmtc(requestMethod)
 		case 'q':
-			fmt = quotefmtc(requestQuery)
+			fmt = quotefmtc(aljw.requestQuery)
 		case 'h':
 			p := getFormatOption(format, &i)
 			if p != "" {
-				fmt = requestHeader(p)
+				fmt = aljw.requestHeader(p)
 			} else {
 				fmt = requestHost
 			}
 			fmt = quotefmtc(fmt)
+		case 'i':
+			fmt = quotefmtc(aljw.requestHeader(getFormatOption(format, &i)))
+		case 'U':
+			fmt = quotefmtc(authUser)
 		case 't':
 			p := getFormatOption(format, &i)
 			if p == "" {
@@ -281,7 +378,7 @@ func (aljw *AccessLogJSONWriter) SetFormat(format string) {
 		case 'H':
 			p := getFormatOption(format, &i)
 			if p != "" {
-				fmt = quotefmtc(responseHeader(p))
+				fmt = quotefmtc(aljw.responseHeader(p))
 			} else {
 				s, _ := os.Hostname()
 				fmt = quotefmtc(strfmtc(s))
@@ -328,6 +425,8 @@ func getFormatOption(format string, i *int) string {
 
 // -------------------------------------------------
 
+var apacheEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
 func quotefmtc(ff fmtfunc) fmtfunc {
 	return func(c *xin.Context) string {
 		return fmt.Sprintf("%q", ff(c))
@@ -403,12 +502,6 @@ func requestQuery(c *xin.Context) string {
 	return c.Request.URL.RawQuery
 }
 
-func requestHeader(name string) fmtfunc {
-	return func(c *xin.Context) string {
-		return c.Request.Header.Get(name)
-	}
-}
-
 func statusCode(c *xin.Context) string {
 	return strconv.Itoa(c.Writer.Status())
 }
@@ -417,8 +510,20 @@ func responseBodyLen(c *xin.Context) string {
 	return strconv.Itoa(c.Writer.Size())
 }
 
-func responseHeader(name string) fmtfunc {
-	return func(c *xin.Context) string {
-		return c.Writer.Header().Get(name)
+func apacheBodyLen(c *xin.Context) string {
+	if n := c.Writer.Size(); n > 0 {
+		return strconv.Itoa(n)
+	}
+	return "-"
+}
+
+func authUser(c *xin.Context) string {
+	if au, ok := c.Get(AuthUserKey); ok {
+		if u, ok := au.(AuthUser); ok {
+			if un := u.GetUsername(); un != "" {
+				return un
+			}
+		}
 	}
+	return "-"
 }
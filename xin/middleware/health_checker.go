@@ -0,0 +1,372 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/askasoft/pango/num"
+	"github.com/askasoft/pango/oss/disk"
+	"github.com/askasoft/pango/oss/mem"
+	"github.com/askasoft/pango/sqx"
+	"github.com/askasoft/pango/xin"
+)
+
+// Health check status (https://datatracker.ietf.org/doc/html/draft-inadarei-api-health-check)
+const (
+	HealthPass = "pass"
+	HealthWarn = "warn"
+	HealthFail = "fail"
+)
+
+// HealthContentType the content type of the health check response
+const HealthContentType = "application/health+json"
+
+// HealthCheckResult the result of a health check, a item of the "checks" object of the health check response.
+type HealthCheckResult struct {
+	ComponentID   string `json:"componentId,omitempty"`
+	ComponentType string `json:"componentType,omitempty"`
+	ObservedValue any    `json:"observedValue,omitempty"`
+	ObservedUnit  string `json:"observedUnit,omitempty"`
+	Status        string `json:"status"`
+	Time          string `json:"time,omitempty"`
+	Output        string `json:"output,omitempty"`
+}
+
+// HealthResponse the health check response
+type HealthResponse struct {
+	Status      string                          `json:"status"`
+	Version     string                          `json:"version,omitempty"`
+	ReleaseID   string                          `json:"releaseId,omitempty"`
+	ServiceID   string                          `json:"serviceId,omitempty"`
+	Description string                          `json:"description,omitempty"`
+	Output      string                          `json:"output,omitempty"`
+	Checks      map[string][]*HealthCheckResult `json:"checks,omitempty"`
+}
+
+// HealthCheckFunc the health check function.
+// The function can fill the observed value of the result, or set the result status to HealthWarn.
+// If the function returns a error, the check is failed.
+type HealthCheckFunc func(ctx context.Context, hcr *HealthCheckResult) error
+
+// HealthCheck a named health check
+type HealthCheck struct {
+	// Name the check name (the key of the "checks" object), e.g. "database:responseTime"
+	Name string
+
+	// Func the check function
+	Func HealthCheckFunc
+
+	// Timeout the check timeout, use HealthChecker.Timeout if zero
+	Timeout time.Duration
+
+	// CacheTTL the result cache duration, use HealthChecker.CacheTTL if zero, no cache if negative
+	CacheTTL time.Duration
+
+	// Liveness include the check in the liveness probe.
+	// A liveness check should only check the process itself, not the external dependencies.
+	Liveness bool
+
+	// Optional a failure of the optional check is reported as "warn"
+	Optional bool
+
+	mutex   sync.Mutex
+	result  *HealthCheckResult
+	expires time.Time
+}
+
+// HealthChecker aggregates the registered health checks,
+// and responds the health check JSON in the IETF health check draft format.
+// Readiness checks all registered checks and fails when draining,
+// Liveness checks only the checks marked as Liveness.
+//
+//	hc := NewHealthChecker()
+//	hc.Register("database:responseTime", HealthCheckPing(db))
+//	router.GET("/livez", hc.Liveness)
+//	router.GET("/readyz", hc.Readiness)
+//
+//	// graceful shutdown
+//	hc.SetDraining(true)
+//	server.Shutdown(ctx)
+type HealthChecker struct {
+	Version     string
+	ReleaseID   string
+	ServiceID   string
+	Description string
+
+	// Timeout the default check timeout
+	Timeout time.Duration
+
+	// CacheTTL the default result cache duration
+	CacheTTL time.Duration
+
+	mutex    sync.RWMutex
+	checks   []*HealthCheck
+	draining atomic.Bool
+}
+
+// NewHealthChecker create a default HealthChecker
+func NewHealthChecker() *HealthChecker {
+	return &HealthChecker{
+		Timeout:  time.Second * 5,
+		CacheTTL: time.Second,
+	}
+}
+
+// AddCheck add the health check
+func (hc *HealthChecker) AddCheck(check *HealthCheck) {
+	if check.Name == "" || check.Func == nil {
+		panic("xin: invalid health check")
+	}
+
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+
+	for _, c := range hc.checks {
+		if c.Name == check.Name {
+			panic("xin: duplicated health check '" + check.Name + "'")
+		}
+	}
+	hc.checks = append(hc.checks, check)
+}
+
+// Register register a named health check function, returns the HealthCheck for customization.
+func (hc *HealthChecker) Register(name string, fn HealthCheckFunc) *HealthCheck {
+	check := &HealthCheck{Name: name, Func: fn}
+	hc.AddCheck(check)
+	return check
+}
+
+// SetDraining set the draining state.
+// The readiness probe fails when draining, so the load balancer stops routing new requests to this instance.
+func (hc *HealthChecker) SetDraining(draining bool) {
+	hc.draining.Store(draining)
+}
+
+// IsDraining returns the draining state
+func (hc *HealthChecker) IsDraining() bool {
+	return hc.draining.Load()
+}
+
+// Check run the health checks (only the Liveness checks if liveness is true) and returns the aggregated response.
+func (hc *HealthChecker) Check(ctx context.Context, liveness bool) *HealthResponse {
+	hc.mutex.RLock()
+	checks := make([]*HealthCheck, 0, len(hc.checks))
+	for _, c := range hc.checks {
+		if !liveness || c.Liveness {
+			checks = append(checks, c)
+		}
+	}
+	hc.mutex.RUnlock()
+
+	hr := &HealthResponse{
+		Status:      HealthPass,
+		Version:     hc.Version,
+		ReleaseID:   hc.ReleaseID,
+		ServiceID:   hc.ServiceID,
+		Description: hc.Description,
+	}
+
+	results := make([]*HealthCheckResult, len(checks))
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = hc.run(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	var fails []string
+	for i, c := range checks {
+		r := results[i]
+
+		if hr.Checks == nil {
+			hr.Checks = make(map[string][]*HealthCheckResult, len(checks))
+		}
+		hr.Checks[c.Name] = []*HealthCheckResult{r}
+
+		switch r.Status {
+		case HealthFail:
+			hr.Status = HealthFail
+			fails = append(fails, c.Name)
+		case HealthWarn:
+			if hr.Status == HealthPass {
+				hr.Status = HealthWarn
+			}
+		}
+	}
+
+	if len(fails) > 0 {
+		sort.Strings(fails)
+		hr.Output = fmt.Sprintf("failed checks: %v", fails)
+	}
+
+	if !liveness && hc.IsDraining() {
+		hr.Status = HealthFail
+		hr.Output = "draining"
+	}
+
+	return hr
+}
+
+func (hc *HealthChecker) run(ctx context.Context, c *HealthCheck) *HealthCheckResult {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	if c.result != nil && now.Before(c.expires) {
+		return c.result
+	}
+
+	parent := ctx
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = hc.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	done := make(chan *HealthCheckResult, 1)
+	go func() {
+		r := &HealthCheckResult{Status: HealthPass}
+
+		defer func() {
+			if err := recover(); err != nil {
+				r.Status, r.Output = HealthFail, fmt.Sprint(err)
+			}
+			done <- r
+		}()
+
+		if err := c.Func(ctx, r); err != nil {
+			r.Status, r.Output = HealthFail, err.Error()
+		}
+	}()
+
+	var r *HealthCheckResult
+	select {
+	case r = <-done:
+	case <-ctx.Done():
+		r = &HealthCheckResult{Status: HealthFail, Output: ctx.Err().Error()}
+	}
+
+	if r.Status == HealthFail && c.Optional {
+		r.Status = HealthWarn
+	}
+	r.Time = now.UTC().Format(time.RFC3339)
+
+	ttl := c.CacheTTL
+	if ttl == 0 {
+		ttl = hc.CacheTTL
+	}
+
+	// do not cache the result of the canceled request
+	if ttl > 0 && parent.Err() == nil {
+		c.result, c.expires = r, now.Add(ttl)
+	}
+
+	return r
+}
+
+func (hc *HealthChecker) respond(c *xin.Context, liveness bool) {
+	hr := hc.Check(c.Request.Context(), liveness)
+
+	code := http.StatusOK
+	if hr.Status == HealthFail {
+		code = http.StatusServiceUnavailable
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Content-Type", HealthContentType)
+	c.JSON(code, hr)
+}
+
+// Liveness handle the liveness probe, only the Liveness checks are evaluated, and the draining state is ignored.
+// Responds 200 if the status is "pass" or "warn", otherwise 503.
+func (hc *HealthChecker) Liveness(c *xin.Context) {
+	hc.respond(c, true)
+}
+
+// Readiness handle the readiness probe, all checks are evaluated, and fails if draining.
+// Responds 200 if the status is "pass" or "warn", otherwise 503.
+func (hc *HealthChecker) Readiness(c *xin.Context) {
+	hc.respond(c, false)
+}
+
+// Handle is the same as Readiness
+func (hc *HealthChecker) Handle(c *xin.Context) {
+	hc.Readiness(c)
+}
+
+// HealthCheckPing returns a health check function that pings the database.
+// The observed value is the response time in milliseconds.
+func HealthCheckPing(pinger sqx.ContextPinger) HealthCheckFunc {
+	return func(ctx context.Context, hcr *HealthCheckResult) error {
+		hcr.ComponentType = "datastore"
+		hcr.ObservedUnit = "ms"
+
+		start := time.Now()
+		err := pinger.PingContext(ctx)
+		hcr.ObservedValue = time.Since(start).Milliseconds()
+		return err
+	}
+}
+
+// HealthCheckDiskFree returns a health check function that checks the available bytes of the disk volume path.
+// The check fails if the available bytes is less than minFree.
+func HealthCheckDiskFree(path string, minFree uint64) HealthCheckFunc {
+	return func(ctx context.Context, hcr *HealthCheckResult) error {
+		hcr.ComponentID = path
+		hcr.ComponentType = "system"
+		hcr.ObservedUnit = "bytes"
+
+		du, err := disk.GetDiskUsage(path)
+		if err != nil {
+			return err
+		}
+
+		hcr.ObservedValue = du.Available
+		if du.Available < minFree {
+			return fmt.Errorf("disk free %s is less than %s", num.HumanSize(du.Available), num.HumanSize(minFree))
+		}
+		return nil
+	}
+}
+
+// HealthCheckMemory returns a health check function that checks the system memory usage.
+// The check fails if the memory usage (0.0 ~ 1.0) is greater than maxUsage.
+func HealthCheckMemory(maxUsage float64) HealthCheckFunc {
+	return func(ctx context.Context, hcr *HealthCheckResult) error {
+		hcr.ComponentType = "system"
+		hcr.ObservedUnit = "percent"
+
+		ms, err := mem.GetMemoryStats()
+		if err != nil {
+			return err
+		}
+
+		usage := ms.Usage()
+		hcr.ObservedValue = int(usage * 100)
+		if usage > maxUsage {
+			return fmt.Errorf("memory usage %d%% is greater than %d%%", int(usage*100), int(maxUsage*100))
+		}
+		return nil
+	}
+}
+
+// HealthCheckError returns a health check function that fails if the fn returns a error.
+func HealthCheckError(fn func(ctx context.Context) error) HealthCheckFunc {
+	return func(ctx context.Context, hcr *HealthCheckResult) error {
+		return fn(ctx)
+	}
+}
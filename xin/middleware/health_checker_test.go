@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/askasoft/pango/xin"
+)
+
+type testPinger struct {
+	err error
+}
+
+func (tp *testPinger) PingContext(ctx context.Context) error {
+	return tp.err
+}
+
+func healthPerformRequest(t *testing.T, router *xin.Engine, path string, code int) *HealthResponse {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+	if w.Code != code {
+		t.Errorf("%s status = %d, want %d", path, w.Code, code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != HealthContentType {
+		t.Errorf("%s content type = %q", path, ct)
+	}
+
+	hr := &HealthResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), hr); err != nil {
+		t.Fatalf("%s body %q: %v", path, w.Body.String(), err)
+	}
+	return hr
+}
+
+func TestHealthChecker(t *testing.T) {
+	db := &testPinger{}
+
+	var calls atomic.Int32
+
+	hc := NewHealthChecker()
+	hc.Version = "1"
+	hc.CacheTTL = time.Hour
+	hc.Register("database:responseTime", HealthCheckPing(db)).CacheTTL = -1
+	hc.Register("cache", HealthCheckError(func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}))
+	hc.Register("uptime", func(ctx context.Context, hcr *HealthCheckResult) error {
+		hcr.ObservedValue = 1
+		return nil
+	}).Liveness = true
+	hc.Register("queue", HealthCheckError(func(ctx context.Context) error {
+		return errors.New("queue is full")
+	})).Optional = true
+
+	router := xin.New()
+	router.GET("/livez", hc.Liveness)
+	router.GET("/readyz", hc.Readiness)
+
+	hr := healthPerformRequest(t, router, "/readyz", http.StatusOK)
+	if hr.Status != HealthWarn || hr.Version != "1" || len(hr.Checks) != 4 {
+		t.Errorf("readyz = %+v", hr)
+	}
+	if r := hr.Checks["database:responseTime"][0]; r.Status != HealthPass || r.ObservedUnit != "ms" || r.ComponentType != "datastore" {
+		t.Errorf("database check = %+v", r)
+	}
+	if r := hr.Checks["queue"][0]; r.Status != HealthWarn || r.Output != "queue is full" {
+		t.Errorf("queue check = %+v", r)
+	}
+
+	// cached
+	healthPerformRequest(t, router, "/readyz", http.StatusOK)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("cache check calls = %d, want 1", n)
+	}
+
+	db.err = errors.New("connection refused")
+	hr = healthPerformRequest(t, router, "/readyz", http.StatusServiceUnavailable)
+	if hr.Status != HealthFail || hr.Output != "failed checks: [database:responseTime]" {
+		t.Errorf("readyz = %+v", hr)
+	}
+	if r := hr.Checks["database:responseTime"][0]; r.Status != HealthFail || r.Output != "connection refused" {
+		t.Errorf("database check = %+v", r)
+	}
+
+	hr = healthPerformRequest(t, router, "/livez", http.StatusOK)
+	if hr.Status != HealthPass || len(hr.Checks) != 1 || hr.Checks["uptime"] == nil {
+		t.Errorf("livez = %+v", hr)
+	}
+
+	db.err = nil
+	hc.SetDraining(true)
+	hr = healthPerformRequest(t, router, "/readyz", http.StatusServiceUnavailable)
+	if hr.Status != HealthFail || hr.Output != "draining" {
+		t.Errorf("readyz = %+v", hr)
+	}
+	healthPerformRequest(t, router, "/livez", http.StatusOK)
+}
+
+func TestHealthCheckerTimeout(t *testing.T) {
+	hc := NewHealthChecker()
+	hc.Register("slow", func(ctx context.Context, hcr *HealthCheckResult) error {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}).Timeout = time.Millisecond
+	hc.Register("panic", func(ctx context.Context, hcr *HealthCheckResult) error {
+		panic("boom")
+	})
+
+	hr := hc.Check(context.Background(), false)
+	if r := hr.Checks["slow"][0]; r.Status != HealthFail || r.Output != context.DeadlineExceeded.Error() {
+		t.Errorf("slow check = %+v", r)
+	}
+	if r := hr.Checks["panic"][0]; r.Status != HealthFail || r.Output != "boom" {
+		t.Errorf("panic check = %+v", r)
+	}
+}
+
+func TestHealthCheckSystem(t *testing.T) {
+	hc := NewHealthChecker()
+	hc.Register("disk", HealthCheckDiskFree(".", 0))
+	hc.Register("disk:full", HealthCheckDiskFree(".", 1<<62))
+	hc.Register("memory", HealthCheckMemory(1))
+
+	hr := hc.Check(context.Background(), false)
+	if r := hr.Checks["disk"][0]; r.Status != HealthPass || r.ObservedUnit != "bytes" {
+		t.Errorf("disk check = %+v", r)
+	}
+	if r := hr.Checks["disk:full"][0]; r.Status != HealthFail {
+		t.Errorf("disk:full check = %+v", r)
+	}
+	if r := hr.Checks["memory"][0]; r.Status != HealthPass || r.ObservedUnit != "percent" {
+		t.Errorf("memory check = %+v", r)
+	}
+}
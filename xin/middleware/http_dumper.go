@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httputil"
+	"strconv"
 	"time"
 
 	"github.com/askasoft/pango/iox"
@@ -24,7 +25,8 @@ const dumpTimeFormat = "2006-01-02T15:04:05.000"
 type HTTPDumper struct {
 	Outputer   io.Writer
 	Maxlength  int64
-	DumpCtxKey string // use xin.Context.Set(key, true | false) to enable/disable dump
+	DumpCtxKey string    // use xin.Context.Set(key, true | false) to enable/disable dump
+	Redactor   *Redactor // redact the sensitive information, nil to dump as is
 	disabled   bool
 }
 
@@ -34,9 +36,9 @@ func DefaultHTTPDumper(xin *xin.Engine) *HTTPDumper {
 	return NewHTTPDumper(xin.Logger.GetOutputer("XHD", log.LevelTrace))
 }
 
-// NewHTTPDumper create a middleware for xin http dumper
+// NewHTTPDumper create a middleware for xin http dumper with the default Redactor
 func NewHTTPDumper(outputer io.Writer) *HTTPDumper {
-	return &HTTPDumper{Outputer: outputer, Maxlength: 1 << 20, DumpCtxKey: HTTPDumpKey}
+	return &HTTPDumper{Outputer: outputer, Maxlength: 1 << 20, DumpCtxKey: HTTPDumpKey, Redactor: NewRedactor()}
 }
 
 // Disable disable the dumper or not
@@ -77,7 +79,13 @@ func (hd *HTTPDumper) Handle(c *xin.Context) {
 
 func (hd *HTTPDumper) dumpRequest(w io.Writer, req *http.Request) string {
 	db := (req.ContentLength >= 0 && req.ContentLength <= hd.Maxlength)
-	bs, _ := httputil.DumpRequest(req, db)
+
+	var bs []byte
+	if rd := hd.Redactor; rd != nil {
+		bs = hd.dumpRedactedRequest(rd, req, db)
+	} else {
+		bs, _ = httputil.DumpRequest(req, db)
+	}
 
 	id := fmt.Sprintf("%x", md5.Sum(bs)) //nolint: gosec
 
@@ -94,6 +102,38 @@ func (hd *HTTPDumper) dumpRequest(w io.Writer, req *http.Request) string {
 	return id
 }
 
+func (hd *HTTPDumper) dumpRedactedRequest(rd *Redactor, req *http.Request, db bool) []byte {
+	ct := req.Header.Get("Content-Type")
+
+	rr := *req
+	rr.Header = rd.RedactHeader(req.Header)
+
+	ru := *req.URL
+	ru.RawQuery = rd.RedactQuery(req.URL.RawQuery)
+	rr.URL = &ru
+	rr.RequestURI = ""
+
+	if db && !rd.SkipBody(ct) && req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		if err == nil {
+			body = rd.RedactBody(ct, body)
+			rr.Body = io.NopCloser(bytes.NewReader(body))
+			rr.ContentLength = int64(len(body))
+			if rr.Header.Get("Content-Length") != "" {
+				rr.Header.Set("Content-Length", strconv.Itoa(len(body)))
+			}
+		} else {
+			db = false
+		}
+	} else {
+		db = false
+	}
+
+	bs, _ := httputil.DumpRequest(&rr, db)
+	return bs
+}
+
 func (hd *HTTPDumper) dumpResponse(w io.Writer, id string, req *http.Request, hdw *httpDumpWriter) {
 	bb := &bytes.Buffer{}
 
@@ -107,6 +147,19 @@ func (hd *HTTPDumper) dumpResponse(w io.Writer, id string, req *http.Request, hd
 	res.StatusCode = hdw.Status()
 	res.Header = hdw.Header()
 	res.Body = io.NopCloser(hdw.bb)
+	if rd := hd.Redactor; rd != nil {
+		ct := res.Header.Get("Content-Type")
+
+		res.Header = rd.RedactHeader(res.Header)
+		res.Header.Del("Content-Length") // written by the ContentLength of the redacted body
+		if rd.SkipBody(ct) {
+			res.Body = http.NoBody
+		} else {
+			body := rd.RedactBody(ct, hdw.bb.Bytes())
+			res.Body = io.NopCloser(bytes.NewReader(body))
+			res.ContentLength = int64(len(body))
+		}
+	}
 	res.Write(bb) //nolint: errcheck
 
 	bb.WriteString("\r\n\r\n")
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -93,3 +94,68 @@ func TestHttpDump(t *testing.T) {
 	dumpPerformRequest(router, "GET", "/example?enable=true")
 	dumpAssertContains(t, "GET /example?enable=true", buffer.String(), "GET /example?enable=true HTTP/1.1", "HTTP/1.1 200 OK")
 }
+
+func TestHttpDumpRedact(t *testing.T) {
+	router := xin.New()
+
+	buffer := new(bytes.Buffer)
+	hd := NewHTTPDumper(buffer)
+	router.Use(hd.Handle)
+
+	router.POST("/login", func(c *xin.Context) {
+		c.SetCookie(&http.Cookie{Name: "sid", Value: "secret-session", Path: "/"})
+		c.String(http.StatusOK, c.PostForm("password"))
+	})
+	router.GET("/secret", func(c *xin.Context) {
+		body := `{"secret":"0123456789"}`
+		c.Header("Content-Length", strconv.Itoa(len(body)))
+		c.Data(http.StatusOK, "application/json", []byte(body))
+	})
+	router.GET("/image", func(c *xin.Context) {
+		c.Data(http.StatusOK, "image/png", []byte("PNG-BINARY-DATA"))
+	})
+
+	req := httptest.NewRequest("POST", "/login?token=abc&q=1", strings.NewReader("user=u&password=p%40ss"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Content-Length", "22")
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	req.Header.Set("Cookie", "sid=old-session")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// the request body is still readable by the handler
+	if w.Body.String() != "p@ss" {
+		t.Errorf("body = %q, want %q", w.Body.String(), "p@ss")
+	}
+
+	dump := buffer.String()
+	dumpAssertContains(t, "POST /login", dump,
+		"POST /login?token=abc&q=1 HTTP/1.1",
+		"Authorization: ******",
+		"Cookie: sid=******",
+		"Set-Cookie: sid=******; Path=/",
+		"Content-Length: 34\r\n",
+		"user=u&password=%2A%2A%2A%2A%2A%2A",
+	)
+	for _, s := range []string{"dXNlcjpwYXNz", "old-session", "secret-session", "p%40ss"} {
+		if str.Contains(dump, s) {
+			t.Errorf("http dump contains %q", s)
+		}
+	}
+
+	buffer.Reset()
+	dumpPerformRequest(router, "GET", "/secret")
+	dumpAssertContains(t, "GET /secret", buffer.String(),
+		"Content-Length: 19\r\n",
+		`{"secret":"******"}`,
+	)
+	if str.Contains(buffer.String(), "Content-Length: 23") {
+		t.Errorf("http dump contains the original Content-Length\n%s", buffer.String())
+	}
+
+	buffer.Reset()
+	dumpPerformRequest(router, "GET", "/image")
+	if str.Contains(buffer.String(), "PNG-BINARY-DATA") {
+		t.Error("http dump contains binary body")
+	}
+}
@@ -101,14 +101,24 @@ func (ll *Localizer) getLocaleFromHeader(c *xin.Context, ks []string) string {
 	return ""
 }
 
+// acceptable returns the exactly matched locale, or the longest locale that the loc starts with.
 func (ll *Localizer) acceptable(loc string) (string, bool) {
-	if loc != "" {
-		locs := ll.Locales
-		for _, al := range locs {
-			if str.StartsWith(loc, al) {
-				return al, true
-			}
+	if loc == "" {
+		return loc, false
+	}
+
+	best := ""
+	for _, al := range ll.Locales {
+		if str.EqualFold(loc, al) {
+			return al, true
 		}
+		if len(al) > len(best) && str.StartsWith(loc, al) {
+			best = al
+		}
+	}
+
+	if best != "" {
+		return best, true
 	}
 	return loc, false
 }
@@ -68,3 +68,33 @@ func TestLocalizerCookie(t *testing.T) {
 
 	doLocalizerTest(t, req, "zh")
 }
+
+func TestLocalizerLongestMatch(t *testing.T) {
+	router := xin.New()
+	router.Use(NewLocalizer("en", "zh", "zh-TW").Handle)
+	router.Any("/", func(c *xin.Context) {
+		c.String(200, c.Locale)
+	})
+
+	cs := []struct {
+		lang string
+		want string
+	}{
+		{"zh-TW", "zh-TW"},
+		{"zh-tw", "zh-TW"},
+		{"zh-TW-x", "zh-TW"},
+		{"zh-CN", "zh"},
+		{"fr;en-US", "en"},
+	}
+
+	for i, c := range cs {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Add(LocaleHeaderNames[0], c.lang)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Body.String() != c.want {
+			t.Errorf("#%d %q = %q, want %q", i, c.lang, w.Body.String(), c.want)
+		}
+	}
+}
@@ -0,0 +1,364 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// RedactMask is the default mask of the redacted value
+const RedactMask = "******"
+
+var (
+	// RedactCardNumberPattern matches the payment card numbers (13-19 digits, may be separated by spaces or hyphens).
+	// The Redactor only redacts the matched numbers that pass the Luhn check.
+	RedactCardNumberPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+	// RedactBearerTokenPattern matches the bearer tokens
+	RedactBearerTokenPattern = regexp.MustCompile(`(?i)\bbearer\s+[a-z0-9\-._~+/]+=*`)
+)
+
+// Redactor redacts the sensitive information of the http request and response.
+// It is used by the HTTPDumper and the access log writers.
+type Redactor struct {
+	// Mask the mask of the redacted value, default is RedactMask.
+	Mask string
+
+	// Headers the header names (case-insensitive) whose values are redacted entirely.
+	Headers []string
+
+	// Cookies the cookie names whose values are redacted in the Cookie and Set-Cookie headers.
+	// "*" redacts all cookies.
+	Cookies []string
+
+	// Fields the form, query and JSON field names (case-insensitive) whose values are redacted.
+	// A name without '.' matches the field at any depth, a dot separated path (e.g. "user.password")
+	// matches the JSON field from the root.
+	Fields []string
+
+	// Patterns the regular expressions to redact from the query values and the text bodies.
+	Patterns []*regexp.Regexp
+
+	// SkipContentTypes the content type prefixes whose bodies are not dumped, e.g. "image/".
+	SkipContentTypes []string
+}
+
+// NewRedactor create a Redactor with the default rules
+func NewRedactor() *Redactor {
+	return &Redactor{
+		Mask:    RedactMask,
+		Headers: []string{"Authorization", "Proxy-Authorization", TokenHeaderName},
+		Cookies: []string{"*"},
+		Fields:  []string{"password", "passwd", "secret", TokenParamName},
+		Patterns: []*regexp.Regexp{
+			RedactCardNumberPattern,
+			RedactBearerTokenPattern,
+		},
+		SkipContentTypes: []string{
+			"image/",
+			"audio/",
+			"video/",
+			"font/",
+			"application/octet-stream",
+			"application/pdf",
+			"application/zip",
+			"application/gzip",
+			"application/x-gzip",
+			"application/x-tar",
+			"application/x-7z-compressed",
+			"application/x-rar-compressed",
+			"application/x-msgpack",
+			"application/msgpack",
+			"application/cbor",
+			"application/vnd.ms-",
+			"application/vnd.openxmlformats-",
+			"multipart/",
+		},
+	}
+}
+
+func (rd *Redactor) mask() string {
+	if rd.Mask == "" {
+		return RedactMask
+	}
+	return rd.Mask
+}
+
+func (rd *Redactor) isHeader(name string) bool {
+	for _, h := range rd.Headers {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rd *Redactor) isCookie(name string) bool {
+	for _, c := range rd.Cookies {
+		if c == "*" || c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isField returns true if the field of the path should be redacted.
+func (rd *Redactor) isField(path []string) bool {
+	if len(path) == 0 {
+		return false
+	}
+
+	for _, f := range rd.Fields {
+		if strings.IndexByte(f, '.') < 0 {
+			if strings.EqualFold(f, path[len(path)-1]) {
+				return true
+			}
+		} else if strings.EqualFold(f, strings.Join(path, ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// SkipBody returns true if the body of the content type should not be dumped.
+func (rd *Redactor) SkipBody(contentType string) bool {
+	ct := strings.ToLower(contentType)
+	for _, s := range rd.SkipContentTypes {
+		if strings.HasPrefix(ct, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString redacts the string by the Patterns.
+func (rd *Redactor) RedactString(s string) string {
+	for _, p := range rd.Patterns {
+		if p == RedactCardNumberPattern {
+			s = p.ReplaceAllStringFunc(s, func(m string) string {
+				if isLuhnNumber(m) {
+					return rd.mask()
+				}
+				return m
+			})
+			continue
+		}
+		s = p.ReplaceAllLiteralString(s, rd.mask())
+	}
+	return s
+}
+
+// isLuhnNumber returns true if the digits of s (spaces and hyphens are ignored) pass the Luhn check.
+func isLuhnNumber(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n > 0 && sum%10 == 0
+}
+
+// RedactHeader returns a redacted copy of the header.
+func (rd *Redactor) RedactHeader(h http.Header) http.Header {
+	rh := make(http.Header, len(h))
+	for k, vs := range h {
+		if rd.isHeader(k) {
+			rh[k] = []string{rd.mask()}
+			continue
+		}
+
+		vs = append([]string(nil), vs...)
+		switch http.CanonicalHeaderKey(k) {
+		case "Cookie":
+			for i, v := range vs {
+				vs[i] = rd.redactCookie(v)
+			}
+		case "Set-Cookie":
+			for i, v := range vs {
+				vs[i] = rd.redactSetCookie(v)
+			}
+		default:
+			for i, v := range vs {
+				vs[i] = rd.RedactString(v)
+			}
+		}
+		rh[k] = vs
+	}
+	return rh
+}
+
+// RedactHeaderValue returns the redacted header value of the name.
+func (rd *Redactor) RedactHeaderValue(name, value string) string {
+	if value == "" {
+		return value
+	}
+
+	if rd.isHeader(name) {
+		return rd.mask()
+	}
+
+	switch http.CanonicalHeaderKey(name) {
+	case "Cookie":
+		return rd.redactCookie(value)
+	case "Set-Cookie":
+		return rd.redactSetCookie(value)
+	default:
+		return rd.RedactString(value)
+	}
+}
+
+func (rd *Redactor) redactCookie(s string) string {
+	if len(rd.Cookies) == 0 {
+		return s
+	}
+
+	ps := strings.Split(s, ";")
+	for i, p := range ps {
+		if k, _, ok := strings.Cut(p, "="); ok && rd.isCookie(strings.TrimSpace(k)) {
+			ps[i] = k + "=" + rd.mask()
+		}
+	}
+	return strings.Join(ps, ";")
+}
+
+func (rd *Redactor) redactSetCookie(s string) string {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || !rd.isCookie(strings.TrimSpace(k)) {
+		return s
+	}
+
+	if i := strings.IndexByte(v, ';'); i >= 0 {
+		return k + "=" + rd.mask() + v[i:]
+	}
+	return k + "=" + rd.mask()
+}
+
+// RedactURL returns the redacted url string.
+func (rd *Redactor) RedactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.String()
+	}
+
+	ru := *u
+	ru.RawQuery = rd.RedactQuery(u.RawQuery)
+	return ru.String()
+}
+
+// RedactQuery returns the redacted query string (or url encoded form), the order of the parameters is kept.
+func (rd *Redactor) RedactQuery(query string) string {
+	if query == "" {
+		return query
+	}
+
+	ps := strings.Split(query, "&")
+	for i, p := range ps {
+		k, v, ok := strings.Cut(p, "=")
+		if !ok {
+			continue
+		}
+
+		key, err := url.QueryUnescape(k)
+		if err != nil {
+			key = k
+		}
+		if rd.isField([]string{key}) {
+			ps[i] = k + "=" + url.QueryEscape(rd.mask())
+			continue
+		}
+
+		val, err := url.QueryUnescape(v)
+		if err != nil {
+			continue
+		}
+		if rv := rd.RedactString(val); rv != val {
+			ps[i] = k + "=" + url.QueryEscape(rv)
+		}
+	}
+	return strings.Join(ps, "&")
+}
+
+// RedactBody returns the redacted body of the content type.
+// The url encoded form and the JSON body are redacted by the Fields and the Patterns,
+// other bodies are redacted by the Patterns.
+func (rd *Redactor) RedactBody(contentType string, body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+
+	mt, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mt == "application/x-www-form-urlencoded":
+		return []byte(rd.RedactQuery(string(body)))
+	case mt == "application/json" || strings.HasSuffix(mt, "+json"):
+		if bs, err := rd.redactJSON(body); err == nil {
+			return bs
+		}
+	}
+
+	return []byte(rd.RedactString(string(body)))
+}
+
+func (rd *Redactor) redactJSON(body []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	v = rd.redactJSONValue(nil, v)
+
+	bb := &bytes.Buffer{}
+	enc := json.NewEncoder(bb)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(bb.Bytes(), []byte{'\n'}), nil
+}
+
+func (rd *Redactor) redactJSONValue(path []string, v any) any {
+	switch o := v.(type) {
+	case map[string]any:
+		for k, e := range o {
+			p := append(path, k) //nolint: gocritic
+			if rd.isField(p) {
+				o[k] = rd.mask()
+			} else {
+				o[k] = rd.redactJSONValue(p, e)
+			}
+		}
+		return o
+	case []any:
+		for i, e := range o {
+			o[i] = rd.redactJSONValue(path, e)
+		}
+		return o
+	case string:
+		return rd.RedactString(o)
+	case json.Number:
+		// keep the redacted number numeric
+		if rs := rd.RedactString(string(o)); rs != string(o) {
+			return json.Number("0")
+		}
+		return o
+	default:
+		return v
+	}
+}
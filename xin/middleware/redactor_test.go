@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/askasoft/pango/test/assert"
+)
+
+func TestRedactorHeader(t *testing.T) {
+	rd := NewRedactor()
+	rd.Cookies = []string{"sid"}
+
+	h := http.Header{
+		"Authorization": {"Basic dXNlcjpwYXNz"},
+		"Cookie":        {"sid=abc; lang=ja"},
+		"Set-Cookie":    {"sid=abc; Path=/; HttpOnly", "lang=ja"},
+		"X-Note":        {"card 4111 1111 1111 1111"},
+		"User-Agent":    {"go"},
+	}
+
+	rh := rd.RedactHeader(h)
+	assert.Equal(t, []string{RedactMask}, rh["Authorization"])
+	assert.Equal(t, []string{"sid=" + RedactMask + "; lang=ja"}, rh["Cookie"])
+	assert.Equal(t, []string{"sid=" + RedactMask + "; Path=/; HttpOnly", "lang=ja"}, rh["Set-Cookie"])
+	assert.Equal(t, []string{"card " + RedactMask}, rh["X-Note"])
+	assert.Equal(t, []string{"go"}, rh["User-Agent"])
+
+	// the original header is not modified
+	assert.Equal(t, "sid=abc; lang=ja", h.Get("Cookie"))
+
+	assert.Equal(t, RedactMask, rd.RedactHeaderValue("authorization", "Bearer x"))
+	assert.Equal(t, "", rd.RedactHeaderValue("authorization", ""))
+	assert.Equal(t, "a "+RedactMask, rd.RedactHeaderValue("X-Token", "a Bearer abc.def"))
+}
+
+func TestRedactorQuery(t *testing.T) {
+	rd := NewRedactor()
+
+	assert.Equal(t, "", rd.RedactQuery(""))
+	assert.Equal(t, "user=a&password=%2A%2A%2A%2A%2A%2A&x", rd.RedactQuery("user=a&password=123&x"))
+	assert.Equal(t, "card=%2A%2A%2A%2A%2A%2A&n=1", rd.RedactQuery("card=4111-1111-1111-1111&n=1"))
+
+	u, _ := url.Parse("/login?PassWord=1&q=go")
+	assert.Equal(t, "/login?PassWord=%2A%2A%2A%2A%2A%2A&q=go", rd.RedactURL(u))
+}
+
+func TestRedactorBody(t *testing.T) {
+	rd := NewRedactor()
+	rd.Fields = append(rd.Fields, "user.pin")
+
+	cs := []struct {
+		ct   string
+		body string
+		want string
+	}{
+		{"application/x-www-form-urlencoded", "a=1&password=2", "a=1&password=%2A%2A%2A%2A%2A%2A"},
+		{"application/json; charset=utf-8", `{"password":"x","items":[{"secret":1,"n":2}],"user":{"pin":"1","name":"<a>"}}`, `{"items":[{"n":2,"secret":"******"}],"password":"******","user":{"name":"<a>","pin":"******"}}`},
+		{"application/json", `{"pin":"1","card":4111111111111111}`, `{"card":0,"pin":"1"}`},
+		{"application/json", `{"order":1234567890123,"card":"4111-1111-1111-1111"}`, `{"card":"******","order":1234567890123}`},
+		{"application/json", `{invalid password 4111111111111111`, `{invalid password ******`},
+		{"text/plain", "card 4111 1111 1111 1111", "card ******"},
+		{"text/plain", "order 1234 5678 9012 3456", "order 1234 5678 9012 3456"},
+	}
+
+	for i, c := range cs {
+		assert.Equal(t, c.want, string(rd.RedactBody(c.ct, []byte(c.body))), "[%d] %s", i, c.ct)
+	}
+}
+
+func TestRedactorSkipBody(t *testing.T) {
+	rd := NewRedactor()
+
+	assert.True(t, rd.SkipBody("image/png"))
+	assert.True(t, rd.SkipBody("Application/Zip"))
+	assert.False(t, rd.SkipBody("text/html; charset=utf-8"))
+	assert.False(t, rd.SkipBody("application/json"))
+	assert.False(t, rd.SkipBody("application/x-www-form-urlencoded"))
+	assert.True(t, rd.SkipBody("multipart/form-data; boundary=x"))
+}
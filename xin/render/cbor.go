@@ -0,0 +1,25 @@
+package render
+
+import (
+	"net/http"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// CBOR contains the given interface object.
+type CBOR struct {
+	Data any
+}
+
+var cborContentType = "application/cbor"
+
+// Render (CBOR) encodes the given interface object and writes data with custom ContentType.
+func (r CBOR) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return cbor.NewEncoder(w).Encode(r.Data)
+}
+
+// WriteContentType (CBOR) writes CBOR ContentType for response.
+func (r CBOR) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, cborContentType)
+}
@@ -0,0 +1,26 @@
+package render
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/askasoft/pango/test/assert"
+	"github.com/fxamacker/cbor/v2"
+)
+
+func TestRenderCBOR(t *testing.T) {
+	w := httptest.NewRecorder()
+	data := map[string]any{
+		"foo": "bar",
+	}
+
+	(CBOR{data}).WriteContentType(w)
+	assert.Equal(t, "application/cbor", w.Header().Get("Content-Type"))
+
+	err := (CBOR{data}).Render(w)
+	assert.NoError(t, err)
+
+	var m map[string]any
+	assert.NoError(t, cbor.Unmarshal(w.Body.Bytes(), &m))
+	assert.Equal(t, data, m)
+}
@@ -0,0 +1,108 @@
+package render
+
+import (
+	"encoding/csv"
+	"net/http"
+	"time"
+
+	"github.com/askasoft/pango/cas"
+	"github.com/askasoft/pango/iox"
+)
+
+// CSV renders the records as CSV, the records are written incrementally.
+// If the Header is nil and the record is a struct, the header is the column names
+// of the exported struct fields, which is the whole value of the `csv` tag or the field name.
+// The field with the `csv:"-"` tag is skipped, and the fields of the embedded struct are flattened.
+type CSV struct {
+	Filename  string // attachment filename, sets the Content-Disposition header if not empty
+	BOM       bool   // writes the UTF-8 BOM
+	Delimiter rune   // field delimiter, default is ','
+	Header    []string
+	Records   Records
+	FlushRows int // flushes the response every FlushRows rows, 0 means flushes only at the end
+}
+
+var csvContentType = "text/csv; charset=utf-8"
+
+// Render (CSV) writes the records with custom ContentType.
+func (r CSV) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	writeAttachment(w, r.Filename)
+
+	if r.BOM {
+		if _, err := iox.WriteString(w, string(iox.BOM)); err != nil {
+			return err
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	if r.Delimiter != 0 {
+		cw.Comma = r.Delimiter
+	}
+
+	tr := &tableRow{tag: "csv"}
+	head := r.Header == nil
+
+	if !head {
+		if err := cw.Write(r.Header); err != nil {
+			return err
+		}
+	}
+
+	n := 0
+	for rec, err := range r.Records {
+		if err != nil {
+			return err
+		}
+
+		if head {
+			head = false
+			if hs := tr.header(rec); hs != nil {
+				if err := cw.Write(hs); err != nil {
+					return err
+				}
+			}
+		}
+
+		vs, err := tr.values(rec)
+		if err != nil {
+			return err
+		}
+
+		ss := make([]string, len(vs))
+		for i, v := range vs {
+			ss[i] = csvFormat(v)
+		}
+		if err := cw.Write(ss); err != nil {
+			return err
+		}
+
+		if n++; r.FlushRows > 0 && n%r.FlushRows == 0 {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			flush(w)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteContentType (CSV) writes CSV ContentType.
+func (r CSV) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, csvContentType)
+}
+
+func csvFormat(v any) string {
+	if t, ok := v.(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	s, _ := cas.ToString(v)
+	return s
+}
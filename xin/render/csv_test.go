@@ -0,0 +1,78 @@
+package render
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/askasoft/pango/test/assert"
+)
+
+type csvEmbed struct {
+	ID int64 `csv:"id"`
+}
+
+type csvRecord struct {
+	csvEmbed
+	Name    string
+	Skip    string `csv:"-"`
+	Score   *float64
+	Created time.Time
+	private string
+}
+
+func TestRenderCSV(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	score := 1.5
+	recs := []*csvRecord{
+		{csvEmbed{1}, "a,b", "x", &score, time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC), ""},
+		{csvEmbed{2}, "c\"d", "y", nil, time.Time{}, ""},
+	}
+
+	r := CSV{
+		Filename: "report.csv",
+		BOM:      true,
+		Records:  SliceRecords(recs),
+	}
+	err := r.Render(w)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="report.csv"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "\ufeffid,Name,Score,Created\n1,\"a,b\",1.5,2000-01-02T03:04:05Z\n2,\"c\"\"d\",,\n", w.Body.String())
+}
+
+func TestRenderCSVHeaderDelimiter(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	r := CSV{
+		Delimiter: '\t',
+		Header:    []string{"A", "B"},
+		Records:   SliceRecords([]any{[]string{"1", "2"}, []any{3, true}}),
+		FlushRows: 1,
+	}
+	err := r.Render(w)
+
+	assert.NoError(t, err)
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "", w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "A\tB\n1\t2\n3\ttrue\n", w.Body.String())
+}
+
+func TestRenderCSVError(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	r := CSV{
+		Records: func(yield func(any, error) bool) {
+			if yield([]string{"a"}, nil) {
+				yield(nil, errors.New("test"))
+			}
+		},
+	}
+	assert.EqualError(t, r.Render(w), "test")
+
+	r = CSV{Records: SliceRecords([]int{1})}
+	assert.Error(t, r.Render(w))
+}
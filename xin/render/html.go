@@ -1,6 +1,8 @@
 package render
 
 import (
+	"html"
+	"io"
 	"net/http"
 
 	"github.com/askasoft/pango/tpl"
@@ -30,11 +32,33 @@ type HTMLRender struct {
 }
 
 // Render renders data with html template.
+// If the templates failed to reload (see tpl.HTMLTemplates.Watch), a error page is rendered with status 500.
 func (r HTMLRender) Render(w http.ResponseWriter) error {
 	r.WriteContentType(w)
+
+	if le, ok := r.Templates.(interface{ LoadError() error }); ok {
+		if err := le.LoadError(); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return writeHTMLError(w, err)
+		}
+	}
+
 	return r.Templates.Render(w, r.Locale, r.Name, r.Data)
 }
 
+func writeHTMLError(w io.Writer, err error) error {
+	_, err = io.WriteString(w, `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Template Error</title></head>
+<body>
+<h1>Template Error</h1>
+<pre style="color: #c00; white-space: pre-wrap;">`+html.EscapeString(err.Error())+`</pre>
+</body>
+</html>
+`)
+	return err
+}
+
 // WriteContentType write html content type
 func (r HTMLRender) WriteContentType(w http.ResponseWriter) {
 	writeContentType(w, "text/html; charset=utf-8")
@@ -1,7 +1,10 @@
 package render
 
 import (
+	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/askasoft/pango/test/assert"
@@ -45,3 +48,22 @@ func TestRenderHTMLTemplateJA(t *testing.T) {
 	assert.Equal(t, "<h1>こんにちは、alexandernyquistさん！</h1>", w.Body.String())
 	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
 }
+
+func TestRenderHTMLTemplateLoadError(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "hello.html"), []byte("<h1>Hello {{.name}}</h1>"), 0o600))
+
+	ht := tpl.NewHTMLTemplates()
+	assert.NoError(t, ht.Load(dir))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "hello.html"), []byte("<h1>Hello {{.name</h1>"), 0o600))
+	assert.Error(t, ht.Reload())
+
+	w := httptest.NewRecorder()
+	err := NewHTMLRenderer(ht)("", "hello", map[string]any{"name": "<b>"}).Render(w)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "<h1>Template Error</h1>")
+	assert.Contains(t, w.Body.String(), "hello.html")
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+}
@@ -0,0 +1,25 @@
+package render
+
+import (
+	"net/http"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MsgPack contains the given interface object.
+type MsgPack struct {
+	Data any
+}
+
+var msgpackContentType = "application/x-msgpack"
+
+// Render (MsgPack) encodes the given interface object and writes data with custom ContentType.
+func (r MsgPack) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return msgpack.NewEncoder(w).Encode(r.Data)
+}
+
+// WriteContentType (MsgPack) writes MsgPack ContentType for response.
+func (r MsgPack) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, msgpackContentType)
+}
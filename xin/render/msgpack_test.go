@@ -0,0 +1,26 @@
+package render
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/askasoft/pango/test/assert"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestRenderMsgPack(t *testing.T) {
+	w := httptest.NewRecorder()
+	data := map[string]any{
+		"foo": "bar",
+	}
+
+	(MsgPack{data}).WriteContentType(w)
+	assert.Equal(t, "application/x-msgpack", w.Header().Get("Content-Type"))
+
+	err := (MsgPack{data}).Render(w)
+	assert.NoError(t, err)
+
+	var m map[string]any
+	assert.NoError(t, msgpack.Unmarshal(w.Body.Bytes(), &m))
+	assert.Equal(t, data, m)
+}
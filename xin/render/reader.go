@@ -1,22 +1,52 @@
 package render
 
 import (
+	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"strconv"
+	"time"
+
+	"github.com/askasoft/pango/net/httpx"
 )
 
 // Reader contains the IO reader and its length, and custom ContentType and other headers.
+// If the Request is set, the ContentLength is known and the Reader is an io.ReaderAt or io.ReadSeeker,
+// the "Range" request header is evaluated and the "206 Partial Content" response is written.
+// The ETag and ModTime (or the "ETag" and "Last-Modified" of the Headers) are used to evaluate
+// the "If-Range" request header.
 type Reader struct {
 	ContentType   string
 	ContentLength int64
 	Reader        io.Reader
 	Headers       map[string]string
+	Request       *http.Request
+	ETag          string
+	ModTime       time.Time
 }
 
 // Render (Reader) writes data with custom ContentType and headers.
 func (r Reader) Render(w http.ResponseWriter) (err error) {
 	r.WriteContentType(w)
+
+	header := w.Header()
+	if r.ETag != "" && header.Get("ETag") == "" {
+		header.Set("ETag", r.ETag)
+	}
+	if !r.ModTime.IsZero() && header.Get("Last-Modified") == "" {
+		header.Set("Last-Modified", r.ModTime.UTC().Format(http.TimeFormat))
+	}
+
+	if r.Request != nil {
+		if ra := r.readerAt(); ra != nil {
+			header.Set("Accept-Ranges", "bytes")
+			if r.isStatusOK(w) {
+				return r.renderRanges(w, ra)
+			}
+		}
+	}
+
 	if r.ContentLength >= 0 {
 		if r.Headers == nil {
 			r.Headers = map[string]string{}
@@ -42,3 +72,151 @@ func (r Reader) writeHeaders(w http.ResponseWriter, headers map[string]string) {
 		}
 	}
 }
+
+// readerAt returns a io.ReaderAt of the Reader if the content is seekable, otherwise returns nil.
+func (r Reader) readerAt() io.ReaderAt {
+	if r.ContentLength < 0 {
+		return nil
+	}
+
+	switch rd := r.Reader.(type) {
+	case io.ReaderAt:
+		return rd
+	case io.ReadSeeker:
+		start, err := rd.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil
+		}
+		return &seekReaderAt{rs: rd, start: start}
+	default:
+		return nil
+	}
+}
+
+// isStatusOK returns false if the status of the response writer is not 200 OK.
+func (r Reader) isStatusOK(w http.ResponseWriter) bool {
+	if sw, ok := w.(interface{ Status() int }); ok {
+		return sw.Status() == http.StatusOK
+	}
+	return true
+}
+
+func (r Reader) renderRanges(w http.ResponseWriter, ra io.ReaderAt) error {
+	size := r.ContentLength
+
+	etag, modtime := r.ETag, r.ModTime
+	if etag == "" {
+		etag = r.Headers["ETag"]
+	}
+	if modtime.IsZero() {
+		if lm, ok := r.Headers["Last-Modified"]; ok {
+			modtime, _ = http.ParseTime(lm)
+		}
+	}
+
+	var ranges []httpx.HttpRange
+
+	method := r.Request.Method
+	if method == "" || method == http.MethodGet || method == http.MethodHead {
+		if httpx.CheckIfRange(r.Request, etag, modtime) {
+			var err error
+			ranges, err = httpx.ParseRange(r.Request.Header.Get("Range"), size)
+			if err != nil {
+				r.writeHeaders(w, r.Headers)
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				_, err = io.WriteString(w, err.Error())
+				return err
+			}
+
+			if sumRangesSize(ranges) > size {
+				// The total number of bytes in all the ranges is larger than the size of the content,
+				// ignore the range request.
+				ranges = nil
+			}
+		}
+	}
+
+	r.writeHeaders(w, r.Headers)
+
+	header := w.Header()
+	switch len(ranges) {
+	case 0:
+		header.Set("Content-Length", strconv.FormatInt(size, 10))
+		_, err := io.Copy(w, io.NewSectionReader(ra, 0, size))
+		return err
+	case 1:
+		hr := ranges[0]
+		header.Set("Content-Range", hr.ContentRange(size))
+		header.Set("Content-Length", strconv.FormatInt(hr.Length, 10))
+		w.WriteHeader(http.StatusPartialContent)
+		_, err := io.Copy(w, io.NewSectionReader(ra, hr.Start, hr.Length))
+		return err
+	default:
+		mw := multipart.NewWriter(w)
+
+		header.Set("Content-Type", "multipart/byteranges; boundary="+mw.Boundary())
+		header.Set("Content-Length", strconv.FormatInt(r.multipartSize(ranges, mw.Boundary()), 10))
+		w.WriteHeader(http.StatusPartialContent)
+
+		for _, hr := range ranges {
+			part, err := mw.CreatePart(hr.MimeHeader(r.ContentType, size))
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(part, io.NewSectionReader(ra, hr.Start, hr.Length)); err != nil {
+				return err
+			}
+		}
+		return mw.Close()
+	}
+}
+
+// multipartSize returns the size of the multipart/byteranges body.
+func (r Reader) multipartSize(ranges []httpx.HttpRange, boundary string) int64 {
+	var cw countingWriter
+
+	mw := multipart.NewWriter(&cw)
+	_ = mw.SetBoundary(boundary)
+	for _, hr := range ranges {
+		_, _ = mw.CreatePart(hr.MimeHeader(r.ContentType, r.ContentLength))
+		cw += countingWriter(hr.Length)
+	}
+	_ = mw.Close()
+	return int64(cw)
+}
+
+func sumRangesSize(ranges []httpx.HttpRange) (size int64) {
+	for _, hr := range ranges {
+		size += hr.Length
+	}
+	return
+}
+
+// countingWriter counts how many bytes have been written to it.
+type countingWriter int64
+
+func (w *countingWriter) Write(p []byte) (n int, err error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
+
+// seekReaderAt adapts a io.ReadSeeker to io.ReaderAt.
+// The offset is relative to the start position of the io.ReadSeeker when adapted.
+// It is not safe for concurrent use.
+type seekReaderAt struct {
+	rs    io.ReadSeeker
+	start int64
+}
+
+func (s *seekReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if _, err := s.rs.Seek(s.start+off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(s.rs, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -1,10 +1,15 @@
 package render
 
 import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/askasoft/pango/test/assert"
 	"github.com/askasoft/pango/test/require"
@@ -16,8 +21,10 @@ func TestReaderRenderNoHeaders(t *testing.T) {
 		ContentLength: int64(len(content)),
 		Reader:        strings.NewReader(content),
 	}
-	err := r.Render(httptest.NewRecorder())
+	w := httptest.NewRecorder()
+	err := r.Render(w)
 	require.NoError(t, err)
+	assert.Equal(t, "", w.Header().Get("Accept-Ranges"))
 }
 
 func TestRenderReader(t *testing.T) {
@@ -65,3 +72,170 @@ func TestRenderReaderNoContentLength(t *testing.T) {
 	assert.Equal(t, headers["Content-Disposition"], w.Header().Get("Content-Disposition"))
 	assert.Equal(t, headers["x-request-id"], w.Header().Get("x-request-id"))
 }
+
+func TestRenderReaderRange(t *testing.T) {
+	body := "0123456789"
+
+	cs := []struct {
+		rng    string
+		status int
+		crange string
+		want   string
+	}{
+		{"", http.StatusOK, "", body},
+		{"bytes=2-5", http.StatusPartialContent, "bytes 2-5/10", "2345"},
+		{"bytes=7-", http.StatusPartialContent, "bytes 7-9/10", "789"},
+		{"bytes=-3", http.StatusPartialContent, "bytes 7-9/10", "789"},
+		{"bytes=5-100", http.StatusPartialContent, "bytes 5-9/10", "56789"},
+		{"bytes=10-", http.StatusRequestedRangeNotSatisfiable, "bytes */10", ""},
+		{"bytes=a-b", http.StatusRequestedRangeNotSatisfiable, "bytes */10", ""},
+	}
+
+	for i, c := range cs {
+		for _, rd := range []io.Reader{strings.NewReader(body), struct{ io.ReadSeeker }{strings.NewReader(body)}} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if c.rng != "" {
+				req.Header.Set("Range", c.rng)
+			}
+
+			w := httptest.NewRecorder()
+			err := (Reader{
+				ContentType:   "text/plain",
+				ContentLength: int64(len(body)),
+				Reader:        rd,
+				Request:       req,
+			}).Render(w)
+
+			require.NoError(t, err)
+			assert.Equal(t, c.status, w.Code, "[%d] %s", i, c.rng)
+			assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"), "[%d] %s", i, c.rng)
+			assert.Equal(t, c.crange, w.Header().Get("Content-Range"), "[%d] %s", i, c.rng)
+			if c.status != http.StatusRequestedRangeNotSatisfiable {
+				assert.Equal(t, c.want, w.Body.String(), "[%d] %s", i, c.rng)
+				assert.Equal(t, strconv.Itoa(len(c.want)), w.Header().Get("Content-Length"), "[%d] %s", i, c.rng)
+			}
+		}
+	}
+}
+
+func TestRenderReaderRangeSeekerOffset(t *testing.T) {
+	body := "0123456789"
+
+	// the content starts at the current offset of the io.ReadSeeker
+	rs := strings.NewReader("header" + body)
+	_, err := rs.Seek(6, io.SeekStart)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=2-5")
+
+	w := httptest.NewRecorder()
+	err = (Reader{
+		ContentLength: int64(len(body)),
+		Reader:        struct{ io.ReadSeeker }{rs},
+		Request:       req,
+	}).Render(w)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "bytes 2-5/10", w.Header().Get("Content-Range"))
+	assert.Equal(t, "2345", w.Body.String())
+}
+
+func TestRenderReaderRangeNotSeekable(t *testing.T) {
+	body := "0123456789"
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=2-5")
+
+	w := httptest.NewRecorder()
+	err := (Reader{
+		ContentLength: int64(len(body)),
+		Reader:        struct{ io.Reader }{strings.NewReader(body)},
+		Request:       req,
+	}).Render(w)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "", w.Header().Get("Accept-Ranges"))
+	assert.Equal(t, body, w.Body.String())
+}
+
+func TestRenderReaderMultiRange(t *testing.T) {
+	body := "0123456789"
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=0-1,5-6")
+
+	w := httptest.NewRecorder()
+	err := (Reader{
+		ContentType:   "text/plain",
+		ContentLength: int64(len(body)),
+		Reader:        strings.NewReader(body),
+		Request:       req,
+	}).Render(w)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, strconv.Itoa(w.Body.Len()), w.Header().Get("Content-Length"))
+
+	mt, ps, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/byteranges", mt)
+
+	mr := multipart.NewReader(w.Body, ps["boundary"])
+
+	wants := []struct{ crange, body string }{
+		{"bytes 0-1/10", "01"},
+		{"bytes 5-6/10", "56"},
+	}
+	for _, want := range wants {
+		p, err := mr.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, "text/plain", p.Header.Get("Content-Type"))
+		assert.Equal(t, want.crange, p.Header.Get("Content-Range"))
+
+		bs, err := io.ReadAll(p)
+		require.NoError(t, err)
+		assert.Equal(t, want.body, string(bs))
+	}
+
+	_, err = mr.NextPart()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestRenderReaderIfRange(t *testing.T) {
+	body := "0123456789"
+	modtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cs := []struct {
+		ifRange string
+		status  int
+	}{
+		{`"v1"`, http.StatusPartialContent},
+		{`"v2"`, http.StatusOK},
+		{`W/"v1"`, http.StatusOK},
+		{modtime.Format(http.TimeFormat), http.StatusPartialContent},
+		{modtime.Add(time.Hour).Format(http.TimeFormat), http.StatusOK},
+	}
+
+	for i, c := range cs {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Range", "bytes=2-5")
+		req.Header.Set("If-Range", c.ifRange)
+
+		w := httptest.NewRecorder()
+		err := (Reader{
+			ContentLength: int64(len(body)),
+			Reader:        strings.NewReader(body),
+			Request:       req,
+			ETag:          `"v1"`,
+			ModTime:       modtime,
+		}).Render(w)
+
+		require.NoError(t, err)
+		assert.Equal(t, c.status, w.Code, "[%d] %s", i, c.ifRange)
+		assert.Equal(t, `"v1"`, w.Header().Get("ETag"))
+		assert.Equal(t, modtime.Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+	}
+}
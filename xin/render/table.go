@@ -0,0 +1,149 @@
+package render
+
+import (
+	"fmt"
+	"iter"
+	"net/http"
+	"reflect"
+
+	"github.com/askasoft/pango/net/httpx"
+)
+
+// Records is an iterator of the records for the table renders (CSV, XLSX).
+// A record can be a []string, a []any, a struct or a pointer to struct.
+// For example, iterate the rows of sqlx.Rows:
+//
+//	func(yield func(any, error) bool) {
+//		for rows.Next() {
+//			var r Report
+//			if !yield(&r, rows.StructScan(&r)) {
+//				return
+//			}
+//		}
+//		if err := rows.Err(); err != nil {
+//			yield(nil, err)
+//		}
+//	}
+type Records = iter.Seq2[any, error]
+
+// SliceRecords returns a Records iterator of the slice.
+func SliceRecords[T any](rs []T) Records {
+	return func(yield func(any, error) bool) {
+		for _, r := range rs {
+			if !yield(r, nil) {
+				return
+			}
+		}
+	}
+}
+
+// tableColumn is a column of the struct record
+type tableColumn struct {
+	name  string
+	index []int
+}
+
+// tableColumns returns the columns of the struct type.
+// The column name is the value of the struct field tag, or the field name if the tag is empty.
+// The field with tag "-" is ignored.
+func tableColumns(rt reflect.Type, tag string) []tableColumn {
+	var tcs []tableColumn
+
+	for _, sf := range reflect.VisibleFields(rt) {
+		if !sf.IsExported() || (sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+			continue
+		}
+
+		name := sf.Tag.Get(tag)
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		tcs = append(tcs, tableColumn{name: name, index: sf.Index})
+	}
+	return tcs
+}
+
+// tableRow converts the record to a row of values
+type tableRow struct {
+	tag     string
+	rtype   reflect.Type
+	columns []tableColumn
+}
+
+// header returns the column names if the record is a struct, otherwise returns nil.
+func (tr *tableRow) header(rec any) []string {
+	rv := reflect.Indirect(reflect.ValueOf(rec))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	tr.init(rv.Type())
+
+	hs := make([]string, len(tr.columns))
+	for i, tc := range tr.columns {
+		hs[i] = tc.name
+	}
+	return hs
+}
+
+func (tr *tableRow) init(rt reflect.Type) {
+	if tr.rtype != rt {
+		tr.rtype = rt
+		tr.columns = tableColumns(rt, tr.tag)
+	}
+}
+
+// values returns the values of the record.
+func (tr *tableRow) values(rec any) ([]any, error) {
+	switch r := rec.(type) {
+	case []any:
+		return r, nil
+	case []string:
+		vs := make([]any, len(r))
+		for i, s := range r {
+			vs[i] = s
+		}
+		return vs, nil
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(rec))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("render: invalid record type %T", rec)
+	}
+
+	tr.init(rv.Type())
+
+	vs := make([]any, len(tr.columns))
+	for i, tc := range tr.columns {
+		fv, err := rv.FieldByIndexErr(tc.index)
+		if err != nil {
+			// nil embedded struct pointer
+			continue
+		}
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		vs[i] = fv.Interface()
+	}
+	return vs, nil
+}
+
+// writeAttachment writes the Content-Disposition header if the filename is not empty.
+func writeAttachment(w http.ResponseWriter, filename string) {
+	if filename != "" {
+		httpx.SetAttachmentHeader(w.Header(), filename)
+	}
+}
+
+func flush(w http.ResponseWriter) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,78 @@
+package render
+
+import (
+	"net/http"
+
+	"github.com/askasoft/pango/doc/ooxml"
+)
+
+// XLSX renders the records as a xlsx document, the records are written incrementally.
+// If the Header is nil and the record is a struct, the header is the column names
+// of the struct fields, which is the value of the `xlsx` tag or the field name.
+type XLSX struct {
+	Filename  string // attachment filename, sets the Content-Disposition header if not empty
+	SheetName string // the worksheet name, default is "Sheet1"
+	Header    []string
+	Records   Records
+	FlushRows int // flushes the response every FlushRows rows, 0 means flushes only at the end
+}
+
+var xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Render (XLSX) writes the records with custom ContentType.
+func (r XLSX) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	writeAttachment(w, r.Filename)
+
+	xw := ooxml.NewXlsxWriter(w)
+	xw.SheetName = r.SheetName
+
+	tr := &tableRow{tag: "xlsx"}
+	head := r.Header == nil
+
+	if !head {
+		hs, _ := tr.values(r.Header)
+		if err := xw.WriteRow(hs...); err != nil {
+			return err
+		}
+	}
+
+	n := 0
+	for rec, err := range r.Records {
+		if err != nil {
+			return err
+		}
+
+		if head {
+			head = false
+			if hs := tr.header(rec); hs != nil {
+				vs, _ := tr.values(hs)
+				if err := xw.WriteRow(vs...); err != nil {
+					return err
+				}
+			}
+		}
+
+		vs, err := tr.values(rec)
+		if err != nil {
+			return err
+		}
+		if err := xw.WriteRow(vs...); err != nil {
+			return err
+		}
+
+		if n++; r.FlushRows > 0 && n%r.FlushRows == 0 {
+			if err := xw.Flush(); err != nil {
+				return err
+			}
+			flush(w)
+		}
+	}
+
+	return xw.Close()
+}
+
+// WriteContentType (XLSX) writes XLSX ContentType.
+func (r XLSX) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, xlsxContentType)
+}
@@ -0,0 +1,58 @@
+package render
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/askasoft/pango/test/assert"
+)
+
+func TestRenderXLSX(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	recs := []struct {
+		Name string `xlsx:"名前"`
+		Age  int
+	}{
+		{"a", 1},
+		{"b", 2},
+	}
+
+	r := XLSX{
+		Filename:  "報告.xlsx",
+		Records:   SliceRecords(recs),
+		FlushRows: 1,
+	}
+	err := r.Render(w)
+
+	assert.NoError(t, err)
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", w.Header().Get("Content-Type"))
+	assert.Equal(t, "attachment; filename*=UTF-8''%E5%A0%B1%E5%91%8A.xlsx", w.Header().Get("Content-Disposition"))
+
+	bs := w.Body.Bytes()
+	zr, err := zip.NewReader(bytes.NewReader(bs), int64(len(bs)))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, zf := range zr.File {
+		if zf.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+
+		r, _ := zf.Open()
+		sx, _ := io.ReadAll(r)
+		r.Close()
+
+		sheet := string(sx)
+		assert.True(t, strings.Contains(sheet, `<c r="A1" t="inlineStr"><is><t xml:space="preserve">名前</t></is></c><c r="B1" t="inlineStr"><is><t xml:space="preserve">Age</t></is></c>`), sheet)
+		assert.True(t, strings.Contains(sheet, `<row r="3"><c r="A3" t="inlineStr"><is><t xml:space="preserve">b</t></is></c><c r="B3"><v>2</v></c></row>`), sheet)
+		return
+	}
+	t.Error("missing sheet1.xml")
+}
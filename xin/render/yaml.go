@@ -0,0 +1,30 @@
+package render
+
+import (
+	"net/http"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// YAML contains the given interface object.
+type YAML struct {
+	Data any
+}
+
+var yamlContentType = "application/x-yaml; charset=utf-8"
+
+// Render (YAML) encodes the given interface object and writes data with custom ContentType.
+func (r YAML) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+
+	ye := yaml.NewEncoder(w)
+	if err := ye.Encode(r.Data); err != nil {
+		return err
+	}
+	return ye.Close()
+}
+
+// WriteContentType (YAML) writes YAML ContentType for response.
+func (r YAML) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, yamlContentType)
+}
@@ -0,0 +1,25 @@
+package render
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/askasoft/pango/test/assert"
+)
+
+func TestRenderYAML(t *testing.T) {
+	w := httptest.NewRecorder()
+	data := map[string]any{
+		"foo": "bar",
+		"num": 1,
+	}
+
+	(YAML{data}).WriteContentType(w)
+	assert.Equal(t, "application/x-yaml; charset=utf-8", w.Header().Get("Content-Type"))
+
+	err := (YAML{data}).Render(w)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "foo: bar\nnum: 1\n", w.Body.String())
+	assert.Equal(t, "application/x-yaml; charset=utf-8", w.Header().Get("Content-Type"))
+}
@@ -38,6 +38,8 @@ type IRoutes interface {
 	PUT(string, ...HandlerFunc) IRoutes
 	OPTIONS(string, ...HandlerFunc) IRoutes
 	HEAD(string, ...HandlerFunc) IRoutes
+
+	Name(string) IRoutes
 }
 
 // RouterGroup is used internally to configure router, a RouterGroup is associated with
@@ -46,6 +48,7 @@ type RouterGroup struct {
 	Handlers HandlersChain
 	basePath string
 	engine   *Engine
+	host     *hostTree
 	root     bool
 }
 
@@ -62,6 +65,7 @@ func (group *RouterGroup) Group(relativePath string, handlers ...HandlerFunc) *R
 		Handlers: group.combineHandlers(handlers),
 		basePath: group.calculateAbsolutePath(relativePath),
 		engine:   group.engine,
+		host:     group.host,
 	}
 }
 
@@ -74,7 +78,7 @@ func (group *RouterGroup) BasePath() string {
 func (group *RouterGroup) handle(httpMethod, relativePath string, handlers HandlersChain) IRoutes {
 	absolutePath := group.calculateAbsolutePath(relativePath)
 	handlers = group.combineHandlers(handlers)
-	group.engine.addRoute(httpMethod, absolutePath, handlers)
+	group.engine.addHostRoute(group.host, httpMethod, absolutePath, handlers)
 	return group.returnObj()
 }
 
@@ -130,6 +134,13 @@ func (group *RouterGroup) HEAD(relativePath string, handlers ...HandlerFunc) IRo
 	return group.handle(http.MethodHead, relativePath, handlers)
 }
 
+// Name sets the name of the last registered route, so that the URL of the route can be built by Engine.URL(name, params...).
+// For example: router.GET("/users/:id", handler).Name("user.show")
+func (group *RouterGroup) Name(name string) IRoutes {
+	group.engine.nameRoute(name)
+	return group.returnObj()
+}
+
 // Any registers a route that matches all the HTTP methods.
 // GET, POST, PUT, PATCH, HEAD, OPTIONS, DELETE, CONNECT, TRACE.
 func (group *RouterGroup) Any(relativePath string, handlers ...HandlerFunc) IRoutes {
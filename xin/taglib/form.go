@@ -0,0 +1,451 @@
+package taglib
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/askasoft/pango/asg"
+	"github.com/askasoft/pango/str"
+	"github.com/askasoft/pango/tbs"
+	"github.com/askasoft/pango/vad"
+)
+
+func FormRender(args ...any) (any, error) {
+	return TagRender(&FormRenderer{}, args...)
+}
+
+// FormRenderer renders a form of the struct fields.
+//
+//	{{Form .User "Locale=" .Locale "Errors=" .Errors "Values=" .Request.PostForm "Action=" "/users/save" "Label=" "user" "Lists=" (Map "Role" .Roles)}}
+//
+// The input name is the "form" tag name (same as the form binding), a field with "form:-" is skipped.
+// The input type is determined by the field type, or the "input" option of the "form" tag, e.g. `form:"note,input=textarea"`.
+// A field with `validate:"required"` is marked as required.
+//
+// The label text is tbs "<Label>.<name>" (default: the field name).
+// The error message is tbs "<Label>.error.<name>.<tag>" or "validation.<tag>" (default: the FieldError's message),
+// formatted with the named arguments {field}, {param} and {value}.
+type FormRenderer struct {
+	Locale string
+	Model  any
+	Errors vad.ValidationErrors
+	Values map[string][]string // submitted values, take precedence over the model values
+	Lists  map[string]any      // the option lists of the fields (field name -> List)
+	Fields []string            // the struct field names to render (default: all)
+	Action string
+	Method string
+	Label  string // the tbs key prefix of the labels
+	Submit string // the tbs key of the submit button text
+}
+
+func (fr *FormRenderer) TagName() string {
+	return "Form"
+}
+
+func (fr *FormRenderer) SetErrors(err any) {
+	switch o := err.(type) {
+	case nil:
+	case vad.ValidationErrors:
+		fr.Errors = o
+	case *vad.ValidationErrors:
+		if o != nil {
+			fr.Errors = *o
+		}
+	case error:
+		if ves, ok := vad.AsValidationErrors(o); ok {
+			fr.Errors = *ves
+		}
+	default:
+		panic(fmt.Errorf("taglib: invalid 'Errors' argument: %T", o))
+	}
+}
+
+func (fr *FormRenderer) SetValues(values any) {
+	switch o := values.(type) {
+	case map[string][]string:
+		fr.Values = o
+	case url.Values:
+		fr.Values = o
+	default:
+		panic(fmt.Errorf("taglib: invalid 'Values' argument: %T", o))
+	}
+}
+
+func (fr *FormRenderer) SetFields(fields any) {
+	switch o := fields.(type) {
+	case string:
+		fr.Fields = str.Fields(o)
+	case []string:
+		fr.Fields = o
+	default:
+		panic(fmt.Errorf("taglib: invalid 'Fields' argument: %T", o))
+	}
+}
+
+func (fr *FormRenderer) Render(sb *strings.Builder, args ...any) error {
+	if len(args) > 0 {
+		if s, ok := args[0].(string); !ok || !str.EndsWithByte(s, '=') {
+			fr.Model = args[0]
+			args = args[1:]
+		}
+	}
+
+	a := Attrs{}
+
+	if err := TagSetAttrs(fr, a, args); err != nil {
+		return err
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(fr.Model))
+	if rv.Kind() != reflect.Struct {
+		return errors.New(fr.TagName() + ": model must be a struct")
+	}
+
+	if fr.Method == "" {
+		fr.Method = "post"
+	}
+
+	a.Class("ui-form")
+	a.Set("method", fr.Method)
+	if fr.Action != "" {
+		a.Set("action", fr.Action)
+	}
+
+	TagStart(sb, "form", a)
+
+	fields := fr.collect(rv, "", "", "", nil)
+	for _, ff := range fields {
+		if len(fr.Fields) == 0 || asg.Contains(fr.Fields, ff.path) {
+			fr.writeField(sb, ff)
+		}
+	}
+
+	if fr.Submit != "" {
+		sb.WriteString(`<div class="ui-form-buttons"><button type="submit">`)
+		sb.WriteString(html.EscapeString(tbs.GetText(fr.Locale, fr.Submit, fr.Submit)))
+		sb.WriteString(`</button></div>`)
+	}
+
+	TagClose(sb, "form")
+
+	return nil
+}
+
+// formField a input field of the form
+type formField struct {
+	path     string // the struct field path, e.g. "Address.City"
+	ns       string // the validation namespace without the top struct name, includes the embedded struct names, e.g. "Base.Code"
+	name     string // the input name, e.g. "address.city"
+	input    string // the input type
+	required bool
+	value    reflect.Value
+}
+
+var typeTime = reflect.TypeOf(time.Time{})
+var typeDuration = reflect.TypeOf(time.Duration(0))
+
+// collect collect the form fields of the struct value
+func (fr *FormRenderer) collect(rv reflect.Value, path, ns, prefix string, fields []*formField) []*formField {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous { // unexported
+			continue
+		}
+
+		tag := sf.Tag.Get("form")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := str.CutByte(tag, ',')
+
+		fv := rv.Field(i)
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+			if fv.IsNil() {
+				fv = reflect.Zero(ft)
+			} else {
+				fv = fv.Elem()
+			}
+		}
+
+		fp, fn := sf.Name, sf.Name
+		if path != "" {
+			fp = path + "." + fp
+		}
+		if ns != "" {
+			fn = ns + "." + fn
+		}
+
+		if ft.Kind() == reflect.Struct && ft != typeTime {
+			if sf.Anonymous && name == "" {
+				fields = fr.collect(fv, path, fn, prefix, fields)
+				continue
+			}
+
+			if name == "" {
+				name = sf.Name
+			}
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			fields = fr.collect(fv, fp, fn, name, fields)
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		ff := &formField{path: fp, ns: fn, name: name, value: fv}
+
+		for opts != "" {
+			var opt string
+			opt, opts, _ = str.CutByte(opts, ',')
+			if k, v, _ := str.CutByte(opt, '='); k == "input" {
+				ff.input = v
+			}
+		}
+
+		ff.required = asg.Contains(strings.Split(sf.Tag.Get("validate"), ","), "required")
+
+		fields = append(fields, ff)
+	}
+	return fields
+}
+
+func (fr *FormRenderer) label(ff *formField) string {
+	if fr.Label == "" {
+		return ff.path
+	}
+	return tbs.GetText(fr.Locale, fr.Label+"."+ff.name, ff.path)
+}
+
+// errors returns the localized error messages of the field
+func (fr *FormRenderer) errors(ff *formField, label string) []string {
+	var msgs []string
+
+	for _, fe := range fr.Errors {
+		// remove the top struct name of the namespace
+		_, ns, _ := str.CutByte(fe.StructNamespace(), '.')
+		if ns != ff.ns {
+			continue
+		}
+
+		args := map[string]any{"field": label, "param": fe.Param(), "value": fe.Value()}
+
+		var msg string
+		if fr.Label != "" {
+			msg = tbs.GetText(fr.Locale, fr.Label+".error."+ff.name+"."+fe.Tag())
+		}
+		if msg == "" {
+			msg = tbs.GetText(fr.Locale, "validation."+fe.Tag())
+		}
+
+		if msg == "" {
+			msg = fe.Error()
+		} else if s, err := tbs.FormatMessage(fr.Locale, msg, args); err == nil {
+			msg = s
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// values returns the submitted values or the model values of the field
+func (fr *FormRenderer) values(ff *formField) []string {
+	if vs, ok := fr.Values[ff.name]; ok {
+		return vs
+	}
+
+	fv := ff.value
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+		vs := make([]string, fv.Len())
+		for i := range vs {
+			vs[i] = formatValue(fv.Index(i), ff.input)
+		}
+		return vs
+	}
+
+	return []string{formatValue(fv, ff.input)}
+}
+
+func formatValue(rv reflect.Value, input string) string {
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ""
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Type() {
+	case typeTime:
+		t := rv.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+		switch input {
+		case "date":
+			return t.Format(time.DateOnly)
+		case "time":
+			return t.Format("15:04")
+		default:
+			return t.Format("2006-01-02T15:04")
+		}
+	case typeDuration:
+		return rv.Interface().(time.Duration).String()
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64)
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return string(rv.Bytes())
+		}
+	}
+	return fmt.Sprint(rv.Interface())
+}
+
+// inputType returns the input type by the field type
+func inputType(rt reflect.Type) string {
+	switch rt {
+	case typeTime:
+		return "datetime-local"
+	case typeDuration:
+		return "text"
+	}
+
+	switch rt.Kind() {
+	case reflect.Bool:
+		return "checkbox"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		if rt.Elem().Kind() != reflect.Uint8 {
+			return "textarea"
+		}
+	}
+	return "text"
+}
+
+func (fr *FormRenderer) writeField(sb *strings.Builder, ff *formField) {
+	if ff.input == "" {
+		ff.input = inputType(ff.value.Type())
+	}
+
+	id := "form_" + strings.ReplaceAll(ff.name, ".", "_")
+	label := fr.label(ff)
+	msgs := fr.errors(ff, label)
+	vals := fr.values(ff)
+
+	if ff.input == "hidden" {
+		TagStartClose(sb, "input", Attrs{"type": "hidden", "id": id, "name": ff.name, "value": str.Join(vals, ",")})
+		return
+	}
+
+	da := Attrs{}
+	da.Class("ui-field")
+	if ff.required {
+		da.Class("required")
+	}
+	if len(msgs) > 0 {
+		da.Class("has-error")
+	}
+	TagStart(sb, "div", da)
+
+	TagStart(sb, "label", Attrs{"for": id})
+	sb.WriteString(html.EscapeString(label))
+	TagClose(sb, "label")
+
+	fr.writeInput(sb, ff, id, vals)
+
+	for _, msg := range msgs {
+		TagStart(sb, "div", Attrs{"class": "ui-field-error"})
+		sb.WriteString(html.EscapeString(msg))
+		TagClose(sb, "div")
+	}
+
+	TagClose(sb, "div")
+}
+
+func (fr *FormRenderer) writeInput(sb *strings.Builder, ff *formField, id string, vals []string) {
+	if list, ok := fr.Lists[ff.path]; ok {
+		fr.writeList(sb, ff, id, AsList(list), vals)
+		return
+	}
+
+	a := Attrs{"id": id, "name": ff.name}
+	if ff.required && ff.input != "checkbox" {
+		a.Set("required", "")
+	}
+
+	switch ff.input {
+	case "textarea":
+		TagStart(sb, "textarea", a)
+		sb.WriteString(html.EscapeString(str.Join(vals, "\n")))
+		TagClose(sb, "textarea")
+	case "checkbox":
+		a.Set("type", "checkbox")
+		a.Set("value", "true")
+		if len(vals) > 0 {
+			if b, _ := strconv.ParseBool(vals[0]); b {
+				a.Set("checked", "")
+			}
+		}
+		TagStartClose(sb, "input", a)
+	default:
+		a.Set("type", ff.input)
+		if ff.input == "number" && (ff.value.Kind() == reflect.Float32 || ff.value.Kind() == reflect.Float64) {
+			a.Set("step", "any")
+		}
+		if ff.input != "password" {
+			a.Set("value", str.Join(vals, ","))
+		}
+		TagStartClose(sb, "input", a)
+	}
+}
+
+// writeList write the select, radios or checks of the list field
+func (fr *FormRenderer) writeList(sb *strings.Builder, ff *formField, id string, list List, vals []string) {
+	values := AsValues(vals)
+
+	switch {
+	case ff.input == "radio":
+		rr := &RadiosRenderer{Name: ff.name, List: list, Value: asg.First(vals)}
+		_ = rr.Render(sb, "id=", id)
+	case ff.input == "checks" || (ff.input == "textarea" && ff.value.Kind() == reflect.Slice):
+		cr := &ChecksRenderer{Name: ff.name, List: list, Values: values}
+		_ = cr.Render(sb, "id=", id)
+	default:
+		sr := &SelectRenderer{Name: ff.name, List: list, Values: values, Multiple: ff.value.Kind() == reflect.Slice}
+		if !ff.required {
+			sr.Empty = " "
+		}
+		_ = sr.Render(sb, "id=", id)
+	}
+}
@@ -0,0 +1,159 @@
+package taglib
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/askasoft/pango/tbs"
+	"github.com/askasoft/pango/vad"
+)
+
+type testFormAddress struct {
+	City string `form:"city" validate:"required"`
+}
+
+type testFormBase struct {
+	ID   int64  `form:"id,input=hidden"`
+	Code string `form:"code" validate:"required"`
+}
+
+type testFormUser struct {
+	testFormBase
+
+	Name     string          `form:"name,strip" validate:"required,maxlen=5"`
+	Age      int             `form:"age" validate:"min=18"`
+	Score    float64         `form:"score"`
+	Active   bool            `form:"active"`
+	Password string          `form:"password,input=password"`
+	Note     string          `form:"note,input=textarea"`
+	Birthday time.Time       `form:"birthday,input=date"`
+	Role     string          `form:"role"`
+	Tags     []string        `form:"tags"`
+	Address  testFormAddress `form:"addr"`
+	Secret   string          `form:"-"`
+}
+
+func testFormRender(t *testing.T, args ...any) string {
+	t.Helper()
+
+	a, err := FormRender(args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return toString(a)
+}
+
+func TestFormRender(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "message_ja.ini"), []byte(`
+[user]
+name = 名前
+[user.error.name]
+required = 名前を入力してください。
+[validation]
+min = {field}は{param}以上にしてください。
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	otb := tbs.Default()
+	defer tbs.SetDefault(otb)
+
+	tb := tbs.NewTextBundles()
+	if err := tb.Load(dir); err != nil {
+		t.Fatal(err)
+	}
+	tbs.SetDefault(tb)
+
+	u := &testFormUser{
+		testFormBase: testFormBase{ID: 3},
+		Age:          10,
+		Score:        1.5,
+		Active:       true,
+		Password:     "pw123",
+		Note:         "a<b",
+		Birthday:     time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC),
+		Role:         "admin",
+		Tags:         []string{"x", "y"},
+		Secret:       "topsecret",
+	}
+
+	verr := vad.New().Struct(u)
+
+	s := testFormRender(t, u,
+		"Locale=", "ja",
+		"Label=", "user",
+		"Errors=", verr,
+		"Values=", map[string][]string{"age": {"abc"}},
+		"Lists=", map[string]any{"Role": map[string]string{"admin": "Admin", "user": "User"}, "Tags": []string{"x", "y", "z"}},
+		"Action=", "/users",
+		"Submit=", "save",
+	)
+
+	cs := []string{
+		`<form`,
+		`action="/users"`,
+		`method="post"`,
+		`type="hidden"`,
+		`value="3"`,
+		`<label for="form_name">名前</label>`,
+		`class="ui-field required has-error"`,
+		`<div class="ui-field-error">名前を入力してください。</div>`,
+		`<div class="ui-field-error">Ageは18以上にしてください。</div>`,
+		`value="abc"`,
+		`step="any"`,
+		`value="1.5"`,
+		`checked`,
+		`type="password"`,
+		`<textarea`,
+		`a&lt;b</textarea>`,
+		`value="2000-01-02"`,
+		`<select`,
+		`ui-checks`,
+		`name="addr.city"`,
+		`name="code"`,
+		`vad: validation for &#39;testFormUser.testFormBase.Code&#39; failed on the &#39;required&#39; tag`,
+		`vad: validation for &#39;testFormUser.Address.City&#39; failed on the &#39;required&#39; tag`,
+		`<button type="submit">save</button>`,
+		`</form>`,
+	}
+	for _, c := range cs {
+		if !strings.Contains(s, c) {
+			t.Errorf("Form() does not contains %q\n%s", c, s)
+		}
+	}
+
+	// the order of the attributes is not fixed
+	if !regexp.MustCompile(`<option (value="admin" selected|selected value="admin")>Admin</option>`).MatchString(s) {
+		t.Errorf("Form() does not contains the selected admin option\n%s", s)
+	}
+
+	for _, c := range []string{"pw123", "topsecret", `name="Secret"`} {
+		if strings.Contains(s, c) {
+			t.Errorf("Form() should not contains %q\n%s", c, s)
+		}
+	}
+}
+
+func TestFormRenderFields(t *testing.T) {
+	s := testFormRender(t, testFormUser{Name: "bob"}, "Fields=", "Name Address.City", "Method=", "get")
+
+	for _, c := range []string{`method="get"`, `value="bob"`, `name="addr.city"`, `<label for="form_name">Name</label>`} {
+		if !strings.Contains(s, c) {
+			t.Errorf("Form() does not contains %q\n%s", c, s)
+		}
+	}
+	for _, c := range []string{`name="age"`, `name="id"`} {
+		if strings.Contains(s, c) {
+			t.Errorf("Form() should not contains %q\n%s", c, s)
+		}
+	}
+
+	if _, err := FormRender("x"); err == nil {
+		t.Error("Form(string) should return error")
+	}
+}
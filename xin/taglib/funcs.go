@@ -20,5 +20,6 @@ func Functions() tpl.FuncMap {
 		"Radios": RadiosRender,
 		"Select": SelectRender,
 		"Pager":  PagerRender,
+		"Form":   FormRender,
 	}
 }
@@ -110,6 +110,37 @@ type node struct {
 	children  []*node // child nodes, at most 1 :param style node at the end of the array
 	handlers  HandlersChain
 	fullPath  string
+
+	constraint func(string) bool // constraint of the param node
+	next       *node             // next alternative param node with different constraint
+}
+
+// paramKey returns the name of the param node without the constraint
+func (n *node) paramKey() string {
+	name, _ := splitParam(n.path)
+	return name
+}
+
+// matchParam checks whether the value satisfies the constraint of the param node
+func (n *node) matchParam(value string) bool {
+	return n.constraint == nil || n.constraint(value)
+}
+
+// addParamAlternative adds the param node as an alternative of the last wildcard child,
+// the constrained params are placed before the unconstrained one.
+func (n *node) addParamAlternative(alt *node) {
+	p := &n.children[len(n.children)-1]
+	if alt.constraint != nil {
+		for *p != nil && (*p).constraint != nil {
+			p = &(*p).next
+		}
+	} else {
+		for *p != nil {
+			p = &(*p).next
+		}
+	}
+	alt.next = *p
+	*p = alt
 }
 
 // Increments priority of the given child and reorders if necessary
@@ -214,6 +245,7 @@ walk:
 				n = child
 			} else if n.wildChild {
 				// inserting a wildcard node, need to check if it conflicts with the existing wildcard
+				pn := n
 				n = n.children[len(n.children)-1]
 				n.priority++
 
@@ -226,6 +258,29 @@ walk:
 					continue walk
 				}
 
+				// Params with different constraints can coexist
+				if n.nType == param && c == ':' {
+					wildcard, _, _ := findWildcard(path)
+
+					conflict := false
+					for alt := n; alt != nil; alt = alt.next {
+						if alt.path == wildcard {
+							n = alt
+							continue walk
+						}
+						if alt.constraint == nil {
+							conflict = true
+						}
+					}
+
+					if !conflict || strings.IndexByte(wildcard, '<') > 0 {
+						holder := &node{}
+						holder.insertChild(path, fullPath, handlers)
+						pn.addParamAlternative(holder.children[0])
+						return
+					}
+				}
+
 				// Wildcard conflict
 				pathSeg := path
 				if n.nType != catchAll {
@@ -267,12 +322,20 @@ func findWildcard(path string) (wildcard string, i int, valid bool) {
 
 		// Find end and check for invalid characters
 		valid = true
-		for end, c := range pbs[start+1:] {
-			switch c {
+		for end := start + 1; end < len(pbs); end++ {
+			switch pbs[end] {
 			case '/':
-				return path[start : start+1+end], start, valid
+				return path[start:end], start, valid
 			case ':', '*':
 				valid = false
+			case '<':
+				// Skip the constraint, e.g. :id<int>, :slug<[a-z-]+>
+				var ok bool
+				end, ok = skipConstraint(pbs, end)
+				if !ok || (end < len(pbs) && pbs[end] != '/') {
+					valid = false
+				}
+				end--
 			}
 		}
 		return path[start:], start, valid
@@ -280,6 +343,40 @@ func findWildcard(path string) (wildcard string, i int, valid bool) {
 	return "", -1, false
 }
 
+// skipConstraint skips the constraint starts at pbs[start] ('<'), returns the index after the closing '>'.
+// The '<' and '>' in a character class (e.g. [^>]) or escaped by '\' (e.g. \>) are not counted.
+// Returns false if the constraint is not closed.
+func skipConstraint(pbs []byte, start int) (int, bool) {
+	depth, class := 0, false
+	for i := start; i < len(pbs); i++ {
+		switch c := pbs[i]; {
+		case c == '\\':
+			i++
+		case class:
+			if c == ']' {
+				class = false
+			}
+		case c == '[':
+			class = true
+			// the ']' at the beginning of the character class is a literal, e.g. []a], [^]a]
+			if i+1 < len(pbs) && pbs[i+1] == '^' {
+				i++
+			}
+			if i+1 < len(pbs) && pbs[i+1] == ']' {
+				i++
+			}
+		case c == '<':
+			depth++
+		case c == '>':
+			depth--
+			if depth == 0 {
+				return i + 1, true
+			}
+		}
+	}
+	return len(pbs), false
+}
+
 func (n *node) insertChild(path string, fullPath string, handlers HandlersChain) {
 	for {
 		// Find prefix until first wildcard
@@ -307,9 +404,10 @@ func (n *node) insertChild(path string, fullPath string, handlers HandlersChain)
 			}
 
 			child := &node{
-				nType:    param,
-				path:     wildcard,
-				fullPath: fullPath,
+				nType:      param,
+				path:       wildcard,
+				fullPath:   fullPath,
+				constraint: compileParamConstraint(wildcard, fullPath),
 			}
 			n.addChild(child)
 			n.wildChild = true
@@ -336,6 +434,9 @@ func (n *node) insertChild(path string, fullPath string, handlers HandlersChain)
 		}
 
 		// catchAll
+		if strings.IndexByte(wildcard, '<') > 0 {
+			panic("catch-all routes can not have a constraint in path '" + fullPath + "'")
+		}
 		if i+len(wildcard) != len(path) {
 			panic("catch-all routes are only allowed at the end of the path in path '" + fullPath + "'")
 		}
@@ -475,6 +576,7 @@ walk: // Outer loop for walking the tree
 				}
 
 				// Handle wildcard child, which is always at the end of the array
+				pn := n
 				n = n.children[len(n.children)-1]
 				globalParamsCount++
 
@@ -489,6 +591,46 @@ walk: // Outer loop for walking the tree
 						end++
 					}
 
+					// Find the first param alternative which constraint is satisfied
+					for n != nil && !n.matchParam(path[:end]) {
+						n = n.next
+					}
+
+					if n == nil {
+						// roll back to last valid skippedNode
+						for l := len(*skippedNodes); l > 0; l-- {
+							skippedNode := (*skippedNodes)[l-1]
+							*skippedNodes = (*skippedNodes)[:l-1]
+							if strings.HasSuffix(skippedNode.path, path) {
+								path = skippedNode.path
+								n = skippedNode.node
+								if value.params != nil {
+									*value.params = (*value.params)[:skippedNode.paramsCount]
+								}
+								globalParamsCount = skippedNode.paramsCount
+								continue walk
+							}
+						}
+						return
+					}
+
+					if n.next != nil {
+						// the rest alternatives will be tried if the deeper path does not match
+						*skippedNodes = append(*skippedNodes, skippedNode{
+							path: prefix + path,
+							node: &node{
+								path:      pn.path,
+								wildChild: true,
+								nType:     pn.nType,
+								priority:  pn.priority,
+								children:  []*node{n.next},
+								handlers:  pn.handlers,
+								fullPath:  pn.fullPath,
+							},
+							paramsCount: globalParamsCount - 1,
+						})
+					}
+
 					// Save param value
 					if params != nil {
 						// Preallocate capacity if necessary
@@ -511,7 +653,7 @@ walk: // Outer loop for walking the tree
 							}
 						}
 						(*value.params)[i] = Param{
-							Key:   n.path[1:],
+							Key:   n.paramKey(),
 							Value: val,
 						}
 					}
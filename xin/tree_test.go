@@ -991,3 +991,84 @@ func TestWildcardInvalidSlash(t *testing.T) {
 		}
 	}
 }
+
+func TestTreeParamConstraints(t *testing.T) {
+	tree := &node{}
+
+	routes := [...]string{
+		"/users/:name",
+		"/users/:id<int>",
+		"/users/:uid<uuid>/edit",
+		"/posts/:slug<[a-z-]+>",
+		"/posts/:id<\\d{1,3}>/comments",
+		"/posts/:pid<int>/likes",
+		"/files/:name<[^/]*\\.txt>",
+		"/items/:id<int>/x",
+		"/items/:name/y",
+		"/tags/:tag<[^>]+>/posts",
+		"/marks/:mark<[]>]+>",
+		"/codes/:code<\\<\\w+\\>>",
+		"/groups/:gid<(?P<n>\\d+)>",
+	}
+	for _, route := range routes {
+		tree.addRoute(route, fakeHandler(route))
+	}
+
+	checkRequests(t, tree, testRequests{
+		{"/users/123", false, "/users/:id<int>", Params{Param{"id", "123"}}},
+		{"/users/-1", false, "/users/:id<int>", Params{Param{"id", "-1"}}},
+		{"/users/bob", false, "/users/:name", Params{Param{"name", "bob"}}},
+		{"/users/bob/edit", true, "", Params{Param{"name", "bob"}}},
+		{"/users/0f0e3b2a-3c1d-4a8e-9f1b-2c3d4e5f6a7b/edit", false, "/users/:uid<uuid>/edit", Params{Param{"uid", "0f0e3b2a-3c1d-4a8e-9f1b-2c3d4e5f6a7b"}}},
+		{"/posts/hello-world", false, "/posts/:slug<[a-z-]+>", Params{Param{"slug", "hello-world"}}},
+		{"/posts/Hello", true, "", Params{}},
+		{"/posts/12/comments", false, "/posts/:id<\\d{1,3}>/comments", Params{Param{"id", "12"}}},
+		{"/posts/12/likes", false, "/posts/:pid<int>/likes", Params{Param{"pid", "12"}}},
+		{"/posts/1234/likes", false, "/posts/:pid<int>/likes", Params{Param{"pid", "1234"}}},
+		{"/posts/1234/comments", true, "", Params{Param{"pid", "1234"}}},
+		{"/files/a.txt", false, "/files/:name<[^/]*\\.txt>", Params{Param{"name", "a.txt"}}},
+		{"/files/a.png", true, "", Params{}},
+		{"/items/12/x", false, "/items/:id<int>/x", Params{Param{"id", "12"}}},
+		{"/items/12/y", false, "/items/:name/y", Params{Param{"name", "12"}}},
+		{"/items/ab/x", true, "", Params{Param{"name", "ab"}}},
+		{"/tags/a<b/posts", false, "/tags/:tag<[^>]+>/posts", Params{Param{"tag", "a<b"}}},
+		{"/tags/a>b/posts", true, "", Params{}},
+		{"/marks/]>]", false, "/marks/:mark<[]>]+>", Params{Param{"mark", "]>]"}}},
+		{"/codes/<abc>", false, "/codes/:code<\\<\\w+\\>>", Params{Param{"code", "<abc>"}}},
+		{"/codes/abc", true, "", Params{}},
+		{"/groups/12", false, "/groups/:gid<(?P<n>\\d+)>", Params{Param{"gid", "12"}}},
+	})
+}
+
+func TestTreeParamConstraintsConflict(t *testing.T) {
+	conflicts := []struct {
+		routes []string
+		panic  bool
+	}{
+		{[]string{"/users/:id<int>", "/users/:name"}, false},
+		{[]string{"/users/:id<int>", "/users/:id<int>"}, true},
+		{[]string{"/users/:id", "/users/:name"}, true},
+		{[]string{"/users/:id<int>", "/users/:name", "/users/:other"}, true},
+		{[]string{"/users/*any", "/users/:id<int>"}, true},
+		{[]string{"/users/*any<int>"}, true},
+		{[]string{"/users/:id<>"}, true},
+		{[]string{"/users/:id<int"}, true},
+		{[]string{"/users/:id<int>x"}, true},
+		{[]string{"/users/:id<[a-z>"}, true},
+		{[]string{"/users/:id<[^>]+>x"}, true},
+		{[]string{"/users/:id<\\>"}, true},
+		{[]string{"/users/:id<[^>]+>"}, false},
+	}
+
+	for i, c := range conflicts {
+		tree := &node{}
+		recv := catchPanic(func() {
+			for _, route := range c.routes {
+				tree.addRoute(route, fakeHandler(route))
+			}
+		})
+		if (recv != nil) != c.panic {
+			t.Errorf("[%d] %v: expected panic %v, got %v", i, c.routes, c.panic, recv)
+		}
+	}
+}
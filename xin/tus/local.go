@@ -0,0 +1,203 @@
+package tus
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/askasoft/pango/str"
+)
+
+// LocalStorage stores the uploads in a local directory.
+// The data of the upload is stored in the file "<id>" and the information is stored in the file "<id>.info".
+type LocalStorage struct {
+	Dir      string
+	DirPerm  os.FileMode
+	FilePerm os.FileMode
+}
+
+// NewLocalStorage create a LocalStorage for the directory
+func NewLocalStorage(dir string) *LocalStorage {
+	return &LocalStorage{
+		Dir:      dir,
+		DirPerm:  0770,
+		FilePerm: 0660,
+	}
+}
+
+// Path returns the data file path of the upload.
+func (ls *LocalStorage) Path(id string) string {
+	return filepath.Join(ls.Dir, id)
+}
+
+func (ls *LocalStorage) infoPath(id string) string {
+	return filepath.Join(ls.Dir, id+".info")
+}
+
+func (ls *LocalStorage) checkID(id string) error {
+	if id == "" || !str.IsLetterNumber(id) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Save creates or updates the information of the upload.
+func (ls *LocalStorage) Save(u *Upload) error {
+	if err := ls.checkID(u.ID); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(ls.Dir, ls.DirPerm); err != nil {
+		return err
+	}
+
+	bs, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(ls.infoPath(u.ID), bs, ls.FilePerm); err != nil {
+		return err
+	}
+
+	// create the data file
+	f, err := os.OpenFile(ls.Path(u.ID), os.O_CREATE|os.O_WRONLY, ls.FilePerm)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Get returns the upload with the current Offset, or ErrNotFound if it does not exist.
+func (ls *LocalStorage) Get(id string) (*Upload, error) {
+	if err := ls.checkID(id); err != nil {
+		return nil, err
+	}
+
+	bs, err := os.ReadFile(ls.infoPath(id))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	u := &Upload{}
+	if err := json.Unmarshal(bs, u); err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Stat(ls.Path(id))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	u.Offset = fi.Size()
+	return u, nil
+}
+
+// Write appends the data to the upload at the offset, returns the written bytes.
+func (ls *LocalStorage) Write(id string, offset int64, r io.Reader) (int64, error) {
+	if err := ls.checkID(id); err != nil {
+		return 0, err
+	}
+
+	f, err := os.OpenFile(ls.Path(id), os.O_WRONLY, ls.FilePerm)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(f, r)
+	if err != nil {
+		return n, err
+	}
+	return n, f.Close()
+}
+
+// Truncate truncates the data of the upload to the size.
+func (ls *LocalStorage) Truncate(id string, size int64) error {
+	if err := ls.checkID(id); err != nil {
+		return err
+	}
+	return os.Truncate(ls.Path(id), size)
+}
+
+// Open opens the data of the upload for reading.
+func (ls *LocalStorage) Open(id string) (io.ReadCloser, error) {
+	if err := ls.checkID(id); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(ls.Path(id))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// Delete deletes the upload.
+func (ls *LocalStorage) Delete(id string) error {
+	if err := ls.checkID(id); err != nil {
+		return err
+	}
+
+	err := os.Remove(ls.infoPath(id))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	if err := os.Remove(ls.Path(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List returns all uploads.
+func (ls *LocalStorage) List() ([]*Upload, error) {
+	des, err := os.ReadDir(ls.Dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var us []*Upload
+	for _, de := range des {
+		id, ok := strings.CutSuffix(de.Name(), ".info")
+		if !ok || de.IsDir() {
+			continue
+		}
+
+		u, err := ls.Get(id)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		us = append(us, u)
+	}
+	return us, nil
+}
@@ -0,0 +1,108 @@
+package tus
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by the Storage if the upload does not exist.
+var ErrNotFound = errors.New("tus: upload not found")
+
+// Upload is the information of an upload
+type Upload struct {
+	ID        string            `json:"id"`
+	Size      int64             `json:"size"`
+	Offset    int64             `json:"-"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// IsComplete returns true if all bytes of the upload are received.
+func (u *Upload) IsComplete() bool {
+	return u.Offset >= u.Size
+}
+
+// IsExpired returns true if the upload is not completed before the expiration time.
+func (u *Upload) IsExpired(now time.Time) bool {
+	return !u.IsComplete() && !u.ExpiresAt.IsZero() && u.ExpiresAt.Before(now)
+}
+
+// Storage is the storage backend of the uploads
+type Storage interface {
+	// Save creates or updates the information of the upload.
+	Save(u *Upload) error
+
+	// Get returns the upload with the current Offset, or ErrNotFound if it does not exist.
+	Get(id string) (*Upload, error)
+
+	// Write appends the data to the upload at the offset, returns the written bytes.
+	Write(id string, offset int64, r io.Reader) (int64, error)
+
+	// Truncate truncates the data of the upload to the size.
+	Truncate(id string, size int64) error
+
+	// Open opens the data of the upload for reading.
+	Open(id string) (io.ReadCloser, error)
+
+	// Delete deletes the upload.
+	Delete(id string) error
+
+	// List returns all uploads.
+	List() ([]*Upload, error)
+}
+
+// ParseMetadata parses the Upload-Metadata header value.
+// The header value consists of comma separated key/value pairs,
+// the key and the value are separated by a space, and the value is base64 encoded.
+func ParseMetadata(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	md := make(map[string]string)
+	for pair := range strings.SplitSeq(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		k, v, _ := strings.Cut(pair, " ")
+		if k == "" {
+			return nil, errors.New("tus: invalid Upload-Metadata " + s)
+		}
+
+		bs, err := base64.StdEncoding.DecodeString(strings.TrimSpace(v))
+		if err != nil {
+			return nil, errors.New("tus: invalid Upload-Metadata " + s)
+		}
+		md[k] = string(bs)
+	}
+	return md, nil
+}
+
+// FormatMetadata formats the metadata to the Upload-Metadata header value.
+func FormatMetadata(md map[string]string) string {
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(k)
+		if v := md[k]; v != "" {
+			sb.WriteByte(' ')
+			sb.WriteString(base64.StdEncoding.EncodeToString([]byte(v)))
+		}
+	}
+	return sb.String()
+}
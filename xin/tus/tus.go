@@ -0,0 +1,440 @@
+// Package tus implements the tus 1.0 resumable upload protocol (https://tus.io/protocols/resumable-upload)
+// for the xin engine, with the creation, creation-with-upload, termination, checksum and expiration extensions.
+//
+//	th := tus.NewHandler(tus.NewLocalStorage("/var/uploads"))
+//	th.OnComplete = func(c *xin.Context, u *tus.Upload) error {
+//		...
+//	}
+//	th.Register(router.Group("/files"))
+//	th.ScheduleCleanup(sch.Default(), "tus", time.Hour)
+package tus
+
+import (
+	"crypto/md5"  //nolint: gosec
+	"crypto/sha1" //nolint: gosec
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/askasoft/pango/ran"
+	"github.com/askasoft/pango/sch"
+	"github.com/askasoft/pango/xin"
+)
+
+const (
+	// Version the supported tus protocol version
+	Version = "1.0.0"
+
+	// Extensions the supported tus protocol extensions
+	Extensions = "creation,creation-with-upload,termination,checksum,expiration"
+
+	// ContentType the content type of the PATCH request
+	ContentType = "application/offset+octet-stream"
+
+	// StatusChecksumMismatch the status of the checksum mismatch
+	StatusChecksumMismatch = 460
+)
+
+// ChecksumAlgorithms the supported checksum algorithms
+var ChecksumAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// Handler is the tus protocol server handler
+type Handler struct {
+	// Storage the storage backend
+	Storage Storage
+
+	// MaxSize the maximum size of an upload, 0 means no limit
+	MaxSize int64
+
+	// Expiration the expiration duration of the unfinished upload since the last PATCH request, 0 means never expire.
+	Expiration time.Duration
+
+	// OnComplete the hook function called when the upload is completed.
+	// If it returns an error, the status 500 is responded.
+	OnComplete func(c *xin.Context, u *Upload) error
+
+	locks sync.Map
+}
+
+// NewHandler create a tus Handler with the storage, the default Expiration is 24 hours.
+func NewHandler(storage Storage) *Handler {
+	return &Handler{
+		Storage:    storage,
+		Expiration: time.Hour * 24,
+	}
+}
+
+// Register registers the tus handlers to the routes.
+// The upload creation url is the base path of the routes, and the upload url is "<base path>/:id".
+func (h *Handler) Register(r xin.IRoutes) {
+	r.OPTIONS("", h.Options)
+	r.POST("", h.Create)
+	r.OPTIONS("/:id", h.Options)
+	r.HEAD("/:id", h.Head)
+	r.PATCH("/:id", h.Patch)
+	r.DELETE("/:id", h.Delete)
+}
+
+func (h *Handler) checksumAlgorithms() string {
+	var algs []string
+	for k := range ChecksumAlgorithms {
+		algs = append(algs, k)
+	}
+	slices.Sort(algs)
+	return strings.Join(algs, ",")
+}
+
+// Options handles the OPTIONS request, responds the server capabilities.
+func (h *Handler) Options(c *xin.Context) {
+	c.Header("Tus-Resumable", Version)
+	c.Header("Tus-Version", Version)
+	c.Header("Tus-Extension", Extensions)
+	c.Header("Tus-Checksum-Algorithm", h.checksumAlgorithms())
+	if h.MaxSize > 0 {
+		c.Header("Tus-Max-Size", strconv.FormatInt(h.MaxSize, 10))
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// checkVersion checks the Tus-Resumable header, responds 412 Precondition Failed if the version is not supported.
+func (h *Handler) checkVersion(c *xin.Context) bool {
+	c.Header("Tus-Resumable", Version)
+
+	if c.GetHeader("Tus-Resumable") != Version {
+		c.Header("Tus-Version", Version)
+		c.AbortWithStatus(http.StatusPreconditionFailed)
+		return false
+	}
+	return true
+}
+
+func (h *Handler) abortError(c *xin.Context, err error) {
+	if errors.Is(err, ErrNotFound) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.Logger.Errorf("tus: %v", err)
+	c.AbortWithStatus(http.StatusInternalServerError)
+}
+
+func (h *Handler) lock(id string) (func(), bool) {
+	v, _ := h.locks.LoadOrStore(id, &sync.Mutex{})
+
+	mu := v.(*sync.Mutex)
+	if !mu.TryLock() {
+		return nil, false
+	}
+	return mu.Unlock, true
+}
+
+// getUpload returns the upload of the id parameter, responds 404 Not Found if it does not exist,
+// or 410 Gone if it is expired.
+func (h *Handler) getUpload(c *xin.Context) (*Upload, bool) {
+	u, err := h.Storage.Get(c.Param("id"))
+	if err != nil {
+		h.abortError(c, err)
+		return nil, false
+	}
+
+	if u.IsExpired(time.Now()) {
+		c.AbortWithStatus(http.StatusGone)
+		return nil, false
+	}
+	return u, true
+}
+
+func (h *Handler) writeExpires(c *xin.Context, u *Upload) {
+	if !u.IsComplete() && !u.ExpiresAt.IsZero() {
+		c.Header("Upload-Expires", u.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// Create handles the POST request, creates a new upload.
+// If the request Content-Type is "application/offset+octet-stream", the request body is written to the upload.
+func (h *Handler) Create(c *xin.Context) {
+	if !h.checkVersion(c) {
+		return
+	}
+
+	size, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || size < 0 {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if h.MaxSize > 0 && size > h.MaxSize {
+		c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	md, err := ParseMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	u := &Upload{
+		ID:        ran.RandLetterNumbers(32),
+		Size:      size,
+		Metadata:  md,
+		CreatedAt: now,
+	}
+	if h.Expiration > 0 {
+		u.ExpiresAt = now.Add(h.Expiration)
+	}
+
+	if err := h.Storage.Save(u); err != nil {
+		h.abortError(c, err)
+		return
+	}
+
+	c.Header("Location", strings.TrimSuffix(c.Request.URL.Path, "/")+"/"+u.ID)
+
+	if c.ContentType() == ContentType {
+		if !h.write(c, u) {
+			return
+		}
+		c.Header("Upload-Offset", strconv.FormatInt(u.Offset, 10))
+	}
+
+	if u.IsComplete() && !h.complete(c, u) {
+		return
+	}
+
+	h.writeExpires(c, u)
+	c.Status(http.StatusCreated)
+}
+
+// Head handles the HEAD request, responds the offset of the upload.
+func (h *Handler) Head(c *xin.Context) {
+	if !h.checkVersion(c) {
+		return
+	}
+
+	u, ok := h.getUpload(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Upload-Offset", strconv.FormatInt(u.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(u.Size, 10))
+	if len(u.Metadata) > 0 {
+		c.Header("Upload-Metadata", FormatMetadata(u.Metadata))
+	}
+	h.writeExpires(c, u)
+	c.Status(http.StatusOK)
+}
+
+// Patch handles the PATCH request, appends the request body to the upload.
+func (h *Handler) Patch(c *xin.Context) {
+	if !h.checkVersion(c) {
+		return
+	}
+
+	if c.ContentType() != ContentType {
+		c.AbortWithStatus(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	id := c.Param("id")
+
+	unlock, ok := h.lock(id)
+	if !ok {
+		c.AbortWithStatus(http.StatusLocked)
+		return
+	}
+	defer unlock()
+
+	u, ok := h.getUpload(c)
+	if !ok {
+		h.locks.Delete(id)
+		return
+	}
+
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	if offset != u.Offset {
+		c.AbortWithStatus(http.StatusConflict)
+		return
+	}
+
+	completed := u.IsComplete()
+
+	if !h.write(c, u) {
+		return
+	}
+
+	if u.IsComplete() {
+		// the completed upload can not be written any more, release the lock entry
+		h.locks.Delete(id)
+
+		// fire the OnComplete hook only when the upload is completed by this request
+		if !completed && !h.complete(c, u) {
+			return
+		}
+	} else if h.Expiration > 0 {
+		u.ExpiresAt = time.Now().Add(h.Expiration)
+		if err := h.Storage.Save(u); err != nil {
+			h.abortError(c, err)
+			return
+		}
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(u.Offset, 10))
+	h.writeExpires(c, u)
+	c.Status(http.StatusNoContent)
+}
+
+// write writes the request body to the upload and verifies the Upload-Checksum.
+func (h *Handler) write(c *xin.Context, u *Upload) bool {
+	var (
+		hasher   hash.Hash
+		checksum []byte
+	)
+
+	if uc := c.GetHeader("Upload-Checksum"); uc != "" {
+		alg, sum, _ := strings.Cut(uc, " ")
+
+		nh, ok := ChecksumAlgorithms[alg]
+		if !ok {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return false
+		}
+
+		bs, err := base64.StdEncoding.DecodeString(sum)
+		if err != nil {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return false
+		}
+
+		hasher, checksum = nh(), bs
+	}
+
+	var r io.Reader = io.LimitReader(c.Request.Body, u.Size-u.Offset)
+	if hasher != nil {
+		r = io.TeeReader(r, hasher)
+	}
+
+	n, err := h.Storage.Write(u.ID, u.Offset, r)
+	if err != nil {
+		// the received data without checksum is kept for resuming
+		if n > 0 && hasher != nil {
+			_ = h.Storage.Truncate(u.ID, u.Offset)
+		}
+		h.abortError(c, err)
+		return false
+	}
+
+	if hasher != nil && string(hasher.Sum(nil)) != string(checksum) {
+		if err := h.Storage.Truncate(u.ID, u.Offset); err != nil {
+			h.abortError(c, err)
+			return false
+		}
+		c.AbortWithStatus(StatusChecksumMismatch)
+		return false
+	}
+
+	u.Offset += n
+	return true
+}
+
+func (h *Handler) complete(c *xin.Context, u *Upload) bool {
+	if h.OnComplete != nil {
+		if err := h.OnComplete(c, u); err != nil {
+			c.Logger.Errorf("tus: failed to complete upload %q: %v", u.ID, err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return false
+		}
+	}
+	return true
+}
+
+// Delete handles the DELETE request, terminates the upload.
+func (h *Handler) Delete(c *xin.Context) {
+	if !h.checkVersion(c) {
+		return
+	}
+
+	id := c.Param("id")
+
+	unlock, ok := h.lock(id)
+	if !ok {
+		c.AbortWithStatus(http.StatusLocked)
+		return
+	}
+	defer unlock()
+
+	if err := h.Storage.Delete(id); err != nil {
+		h.abortError(c, err)
+		return
+	}
+
+	h.locks.Delete(id)
+	c.Status(http.StatusNoContent)
+}
+
+// Cleanup deletes the expired unfinished uploads, returns the count of the deleted uploads.
+func (h *Handler) Cleanup() (int, error) {
+	us, err := h.Storage.List()
+	if err != nil {
+		return 0, err
+	}
+
+	now, cnt := time.Now(), 0
+	for _, u := range us {
+		if !u.IsExpired(now) {
+			continue
+		}
+
+		unlock, ok := h.lock(u.ID)
+		if !ok {
+			continue
+		}
+
+		err := h.Storage.Delete(u.ID)
+		h.locks.Delete(u.ID)
+		unlock()
+
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return cnt, err
+		}
+		cnt++
+	}
+	return cnt, nil
+}
+
+// ScheduleCleanup schedules the Cleanup task to the scheduler with the interval.
+func (h *Handler) ScheduleCleanup(s *sch.Scheduler, name string, interval time.Duration) {
+	s.Schedule(name, &sch.RepeatTrigger{Duration: interval, InitialDelay: interval}, func() {
+		cnt, err := h.Cleanup()
+		if err != nil {
+			if s.Logger != nil {
+				s.Logger.Errorf("tus: cleanup failed: %v", err)
+			}
+			return
+		}
+		if cnt > 0 && s.Logger != nil {
+			s.Logger.Infof("tus: %d expired uploads deleted", cnt)
+		}
+	})
+}
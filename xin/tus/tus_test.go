@@ -0,0 +1,206 @@
+package tus
+
+import (
+	"bytes"
+	"crypto/sha1" //nolint: gosec
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/askasoft/pango/test/assert"
+	"github.com/askasoft/pango/test/require"
+	"github.com/askasoft/pango/xin"
+	"github.com/askasoft/pango/xin/xintest"
+)
+
+func newTestClient(t *testing.T) (*xintest.Client, *Handler) {
+	th := NewHandler(NewLocalStorage(t.TempDir()))
+	th.MaxSize = 100
+
+	router := xin.New()
+	th.Register(router.Group("/files"))
+
+	client := xintest.NewClient(t, router)
+	client.Header.Set("Tus-Resumable", Version)
+	return client, th
+}
+
+func patch(client *xintest.Client, loc string, offset int, data string) *xintest.Response {
+	return client.PATCH(loc).
+		WithHeader("Upload-Offset", strconv.Itoa(offset)).
+		WithBody(ContentType, bytes.NewReader([]byte(data))).
+		Do()
+}
+
+func TestTusOptions(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	client.OPTIONS("/files").Do().
+		AssertStatus(http.StatusNoContent).
+		AssertHeader("Tus-Version", Version).
+		AssertHeader("Tus-Extension", Extensions).
+		AssertHeader("Tus-Max-Size", "100").
+		AssertHeader("Tus-Checksum-Algorithm", "md5,sha1,sha256,sha512")
+}
+
+func TestTusUpload(t *testing.T) {
+	client, th := newTestClient(t)
+
+	var (
+		completed *Upload
+		count     int
+	)
+	th.OnComplete = func(c *xin.Context, u *Upload) error {
+		completed = u
+		count++
+		return nil
+	}
+
+	client.POST("/files").WithHeader("Tus-Resumable", "0.2.2").Do().
+		AssertStatus(http.StatusPreconditionFailed).
+		AssertHeader("Tus-Version", Version)
+
+	client.POST("/files").WithHeader("Upload-Length", "101").Do().
+		AssertStatus(http.StatusRequestEntityTooLarge)
+
+	res := client.POST("/files").
+		WithHeader("Upload-Length", "11").
+		WithHeader("Upload-Metadata", "filename "+base64.StdEncoding.EncodeToString([]byte("a.txt"))+",empty").
+		Do().
+		AssertStatus(http.StatusCreated)
+
+	loc := res.Header().Get("Location")
+	assert.Contains(t, loc, "/files/")
+	assert.NotEqual(t, "", res.Header().Get("Upload-Expires"))
+
+	client.HEAD(loc).Do().
+		AssertStatus(http.StatusOK).
+		AssertHeader("Upload-Offset", "0").
+		AssertHeader("Upload-Length", "11").
+		AssertHeader("Upload-Metadata", "empty,filename YS50eHQ=").
+		AssertHeader("Cache-Control", "no-store")
+
+	client.PATCH(loc).WithHeader("Upload-Offset", "0").WithBody("text/plain", bytes.NewReader([]byte("hello"))).Do().
+		AssertStatus(http.StatusUnsupportedMediaType)
+
+	patch(client, loc, 0, "hello").
+		AssertStatus(http.StatusNoContent).
+		AssertHeader("Upload-Offset", "5")
+
+	patch(client, loc, 0, "hello").
+		AssertStatus(http.StatusConflict)
+
+	// checksum mismatch
+	client.PATCH(loc).
+		WithHeader("Upload-Offset", "5").
+		WithHeader("Upload-Checksum", "sha1 "+base64.StdEncoding.EncodeToString([]byte("xxxxxxxxxxxxxxxxxxxx"))).
+		WithBody(ContentType, bytes.NewReader([]byte(" worl"))).
+		Do().
+		AssertStatus(StatusChecksumMismatch)
+
+	client.HEAD(loc).Do().AssertHeader("Upload-Offset", "5")
+
+	// unsupported checksum algorithm
+	client.PATCH(loc).
+		WithHeader("Upload-Offset", "5").
+		WithHeader("Upload-Checksum", "crc32 AAAA").
+		WithBody(ContentType, bytes.NewReader([]byte(" worl"))).
+		Do().
+		AssertStatus(http.StatusBadRequest)
+
+	sum := sha1.Sum([]byte(" world")) //nolint: gosec
+	client.PATCH(loc).
+		WithHeader("Upload-Offset", "5").
+		WithHeader("Upload-Checksum", "sha1 "+base64.StdEncoding.EncodeToString(sum[:])).
+		WithBody(ContentType, bytes.NewReader([]byte(" world!!!"[:6]))).
+		Do().
+		AssertStatus(http.StatusNoContent).
+		AssertHeader("Upload-Offset", "11").
+		AssertHeader("Upload-Expires", "")
+
+	require.NotNil(t, completed)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, int64(11), completed.Offset)
+
+	// the PATCH to the completed upload does not fire the OnComplete hook again
+	patch(client, loc, 11, "").
+		AssertStatus(http.StatusNoContent).
+		AssertHeader("Upload-Offset", "11")
+	assert.Equal(t, 1, count)
+
+	_, locked := th.locks.Load(completed.ID)
+	assert.False(t, locked)
+	assert.Equal(t, map[string]string{"filename": "a.txt", "empty": ""}, completed.Metadata)
+
+	rc, err := th.Storage.Open(completed.ID)
+	require.NoError(t, err)
+	bs, _ := io.ReadAll(rc)
+	rc.Close()
+	assert.Equal(t, "hello world", string(bs))
+
+	client.DELETE(loc).Do().AssertStatus(http.StatusNoContent)
+	client.HEAD(loc).Do().AssertStatus(http.StatusNotFound)
+	client.DELETE(loc).Do().AssertStatus(http.StatusNotFound)
+}
+
+func TestTusCreationWithUpload(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	res := client.POST("/files").
+		WithHeader("Upload-Length", "10").
+		WithBody(ContentType, bytes.NewReader([]byte("01234"))).
+		Do().
+		AssertStatus(http.StatusCreated).
+		AssertHeader("Upload-Offset", "5")
+
+	loc := res.Header().Get("Location")
+	patch(client, loc, 5, "56789").
+		AssertStatus(http.StatusNoContent).
+		AssertHeader("Upload-Offset", "10")
+
+	// the exceeded data is ignored
+	patch(client, loc, 10, "x").
+		AssertStatus(http.StatusNoContent).
+		AssertHeader("Upload-Offset", "10")
+}
+
+func TestTusExpiration(t *testing.T) {
+	client, th := newTestClient(t)
+
+	res := client.POST("/files").WithHeader("Upload-Length", "10").Do().AssertStatus(http.StatusCreated)
+	loc := res.Header().Get("Location")
+
+	done := client.POST("/files").WithHeader("Upload-Length", "0").Do().
+		AssertStatus(http.StatusCreated).
+		AssertHeader("Upload-Expires", "")
+
+	cnt, err := th.Cleanup()
+	require.NoError(t, err)
+	assert.Equal(t, 0, cnt)
+
+	th.Expiration = time.Millisecond
+	patch(client, loc, 0, "0").AssertStatus(http.StatusNoContent)
+	time.Sleep(time.Millisecond * 5)
+	patch(client, loc, 1, "1").AssertStatus(http.StatusGone)
+	client.HEAD(loc).Do().AssertStatus(http.StatusGone)
+
+	cnt, err = th.Cleanup()
+	require.NoError(t, err)
+	assert.Equal(t, 1, cnt)
+
+	client.HEAD(loc).Do().AssertStatus(http.StatusNotFound)
+	client.HEAD(done.Header().Get("Location")).Do().AssertStatus(http.StatusOK)
+}
+
+func TestParseMetadata(t *testing.T) {
+	md, err := ParseMetadata("a YQ==, b ,c")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "a", "b": "", "c": ""}, md)
+	assert.Equal(t, "a YQ==,b,c", FormatMetadata(md))
+
+	_, err = ParseMetadata("a !!")
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+package xin
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/askasoft/pango/cas"
+	"github.com/askasoft/pango/tpl"
+)
+
+func (engine *Engine) nameRoute(name string) {
+	if name == "" {
+		panic("route name can not be empty")
+	}
+	if engine.lastRoute == "" {
+		panic("route name '" + name + "' must be set after a route is registered")
+	}
+	if p, ok := engine.routeNames[name]; ok && p != engine.lastRoute {
+		panic("route name '" + name + "' conflicts with existing route '" + p + "'")
+	}
+	engine.routeNames[name] = engine.lastRoute
+}
+
+// RoutePath returns the registered path of the named route and a boolean true.
+// If no route with the name is found, an empty string and a boolean false are returned.
+func (engine *Engine) RoutePath(name string) (string, bool) {
+	p, ok := engine.routeNames[name]
+	return p, ok
+}
+
+// URL builds the URL of the named route.
+// The params are key/value pairs, the value of the key which matches a `:param` or `*catchAll`
+// segment of the route path will be escaped and filled in the path,
+// the others are appended to the URL as query string.
+// An error is returned if a param value does not satisfy the constraint of the path segment.
+// For example:
+//
+//	router.GET("/users/:id", handler).Name("user.show")
+//	router.URL("user.show", "id", 1, "tab", "profile") // "/users/1?tab=profile"
+func (engine *Engine) URL(name string, params ...any) (string, error) {
+	rp, ok := engine.routeNames[name]
+	if !ok {
+		return "", fmt.Errorf("xin: route %q is not found", name)
+	}
+
+	if len(params)&1 != 0 {
+		return "", fmt.Errorf("xin: URL(%q) invalid params, must be key/value pairs", name)
+	}
+
+	vals := make(map[string]string, len(params)/2)
+	keys := make([]string, 0, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		k, ok := params[i].(string)
+		if !ok {
+			return "", fmt.Errorf("xin: URL(%q) invalid param key %v, must be string", name, params[i])
+		}
+
+		v, err := cas.ToString(params[i+1])
+		if err != nil {
+			return "", fmt.Errorf("xin: URL(%q) invalid param %q: %w", name, k, err)
+		}
+
+		if _, ok := vals[k]; !ok {
+			keys = append(keys, k)
+		}
+		vals[k] = v
+	}
+
+	used := make(map[string]bool, len(vals))
+
+	var sb strings.Builder
+	for p := rp; p != ""; {
+		wildcard, i, _ := findWildcard(p)
+		if i < 0 {
+			sb.WriteString(p)
+			break
+		}
+
+		sb.WriteString(p[:i])
+		p = p[i+len(wildcard):]
+
+		key, _ := splitParam(wildcard)
+		v, ok := vals[key]
+		if !ok {
+			return "", fmt.Errorf("xin: URL(%q) missing param %q for path %q", name, key, rp)
+		}
+		used[key] = true
+
+		if wildcard[0] == ':' {
+			if fc := compileParamConstraint(wildcard, rp); fc != nil && !fc(v) {
+				return "", fmt.Errorf("xin: URL(%q) param %q=%q does not satisfy the constraint of %q", name, key, v, wildcard)
+			}
+			sb.WriteString(url.PathEscape(v))
+			continue
+		}
+
+		// catch-all: the value may contain slashes, escape each segment
+		v = strings.TrimPrefix(v, "/")
+		for j, s := range strings.Split(v, "/") {
+			if j > 0 {
+				sb.WriteByte('/')
+			}
+			sb.WriteString(url.PathEscape(s))
+		}
+	}
+
+	if len(used) < len(keys) {
+		qs := url.Values{}
+		for _, k := range keys {
+			if !used[k] {
+				qs.Add(k, vals[k])
+			}
+		}
+		sb.WriteByte('?')
+		sb.WriteString(qs.Encode())
+	}
+
+	return sb.String(), nil
+}
+
+// Functions returns the template functions bound to the engine.
+// The names do not conflict with tpl.Functions(), so the two maps can be merged.
+//   - RouteURL: build the URL of the named route, see Engine.URL().
+func (engine *Engine) Functions() tpl.FuncMap {
+	return tpl.FuncMap{
+		"RouteURL": engine.URL,
+	}
+}
@@ -0,0 +1,130 @@
+package xin
+
+import (
+	"bytes"
+	"html/template"
+	"maps"
+	"testing"
+
+	"github.com/askasoft/pango/test/assert"
+	"github.com/askasoft/pango/tpl"
+)
+
+func TestEngineURL(t *testing.T) {
+	router := New()
+	router.GET("/", Nop).Name("home")
+	router.GET("/users/:id", Nop).Name("user.show")
+	router.GET("/users/:id/posts/:pid", Nop).Name("user.post")
+
+	v1 := router.Group("/v1")
+	v1.GET("/src/*filepath", Nop).Name("src")
+
+	cs := []struct {
+		name   string
+		params []any
+		want   string
+	}{
+		{"home", nil, "/"},
+		{"home", []any{"q", "a b"}, "/?q=a+b"},
+		{"user.show", []any{"id", 1}, "/users/1"},
+		{"user.show", []any{"id", "a/b c"}, "/users/a%2Fb%20c"},
+		{"user.show", []any{"id", 1, "tab", "profile", "a", "&"}, "/users/1?a=%26&tab=profile"},
+		{"user.post", []any{"pid", 2, "id", 1}, "/users/1/posts/2"},
+		{"src", []any{"filepath", "/js/a b.js"}, "/v1/src/js/a%20b.js"},
+		{"src", []any{"filepath", "css/x.css"}, "/v1/src/css/x.css"},
+	}
+
+	for i, c := range cs {
+		a, err := router.URL(c.name, c.params...)
+		if assert.NoError(t, err, "[%d] %s", i, c.name) {
+			assert.Equal(t, c.want, a, "[%d] %s", i, c.name)
+		}
+	}
+}
+
+func TestEngineURLError(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", Nop).Name("user.show")
+
+	_, err := router.URL("unknown")
+	assert.Error(t, err)
+
+	_, err = router.URL("user.show")
+	assert.Error(t, err)
+
+	_, err = router.URL("user.show", "id")
+	assert.Error(t, err)
+
+	_, err = router.URL("user.show", 1, 1)
+	assert.Error(t, err)
+}
+
+func TestEngineNameRoute(t *testing.T) {
+	router := New()
+	assert.Panics(t, func() {
+		router.Name("none")
+	})
+
+	router.Any("/any", Nop).Name("any")
+	p, ok := router.RoutePath("any")
+	assert.True(t, ok)
+	assert.Equal(t, "/any", p)
+
+	router.GET("/other", Nop)
+	assert.Panics(t, func() {
+		router.Name("any")
+	})
+	assert.Panics(t, func() {
+		router.Name("")
+	})
+}
+
+func TestEngineFunctions(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", Nop).Name("user.show")
+
+	tpl := template.Must(template.New("").Funcs(template.FuncMap(router.Functions())).Parse(`<a href="{{ RouteURL "user.show" "id" .ID "q" "x" }}">`))
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, tpl.Execute(buf, map[string]any{"ID": 3}))
+	assert.Equal(t, `<a href="/users/3?q=x">`, buf.String())
+}
+
+func TestEngineFunctionsMerge(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", Nop).Name("user.show")
+
+	// merge in both orders, the functions of tpl must not be overridden
+	for i, fms := range [][]tpl.FuncMap{
+		{tpl.Functions(), router.Functions()},
+		{router.Functions(), tpl.Functions()},
+	} {
+		fm := template.FuncMap{}
+		for _, m := range fms {
+			maps.Copy(fm, m)
+		}
+
+		tt := template.Must(template.New("").Funcs(fm).Parse(`<a href="{{ RouteURL "user.show" "id" .ID }}" data-u="{{ URL .U }}">`))
+
+		buf := &bytes.Buffer{}
+		assert.NoError(t, tt.Execute(buf, map[string]any{"ID": 3, "U": "javascript:x"}), "#%d", i)
+		assert.Equal(t, `<a href="/users/3" data-u="javascript:x">`, buf.String(), "#%d", i)
+	}
+}
+
+func TestEngineURLConstraint(t *testing.T) {
+	router := New()
+	router.GET("/users/:id<int>", Nop).Name("user.show")
+	router.GET("/posts/:slug<[a-z/-]+>/edit", Nop).Name("post.edit")
+
+	a, err := router.URL("user.show", "id", 12)
+	assert.NoError(t, err)
+	assert.Equal(t, "/users/12", a)
+
+	_, err = router.URL("user.show", "id", "abc")
+	assert.Error(t, err)
+
+	a, err = router.URL("post.edit", "slug", "a-b")
+	assert.NoError(t, err)
+	assert.Equal(t, "/posts/a-b/edit", a)
+}
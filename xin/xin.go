@@ -49,6 +49,7 @@ func (c HandlersChain) Last() HandlerFunc {
 
 // RouteInfo represents a request route's specification which contains method and path and its handler.
 type RouteInfo struct {
+	Host        string
 	Method      string
 	Path        string
 	Handler     string
@@ -145,9 +146,12 @@ type Engine struct {
 	noMethod         HandlersChain
 	pool             sync.Pool
 	trees            methodTrees
+	hosts            []*hostTree
 	maxParams        uint16
 	maxSections      uint16
 	trustedProxies   []*net.IPNet
+	routeNames       map[string]string
+	lastRoute        string
 }
 
 // New returns a new blank Engine instance without any middleware attached.
@@ -178,6 +182,7 @@ func New() *Engine {
 		Validator:              validate.NewStructValidator(),
 		Logger:                 log.GetLogger("XIN"),
 		trees:                  make(methodTrees, 0, 9),
+		routeNames:             make(map[string]string),
 		secureJSONPrefix:       ")]}',\n",
 	}
 	engine.engine = engine
@@ -248,6 +253,10 @@ func (engine *Engine) rebuild405Handlers() {
 }
 
 func (engine *Engine) addRoute(method, path string, handlers HandlersChain) {
+	engine.addHostRoute(nil, method, path, handlers)
+}
+
+func (engine *Engine) addHostRoute(host *hostTree, method, path string, handlers HandlersChain) {
 	if path[0] != '/' {
 		panic("path must begin with '/'")
 	}
@@ -258,21 +267,31 @@ func (engine *Engine) addRoute(method, path string, handlers HandlersChain) {
 		panic("there must be at least one handler")
 	}
 
+	trees, hostname := &engine.trees, ""
+	if host != nil {
+		trees, hostname = &host.trees, host.pattern
+	}
+
 	if engine.Logger.IsInfoEnabled() {
 		nuHandlers := len(handlers)
 		handlerName := ref.NameOfFunc(handlers.Last())
-		engine.Logger.Infof("%-6s %-25s --> %s (%d handlers)", method, path, handlerName, nuHandlers)
+		engine.Logger.Infof("%-6s %-25s --> %s (%d handlers)", method, hostname+path, handlerName, nuHandlers)
 	}
 
-	root := engine.trees.get(method)
+	root := trees.get(method)
 	if root == nil {
 		root = new(node)
 		root.fullPath = "/"
-		engine.trees = append(engine.trees, methodTree{method: method, root: root})
+		*trees = append(*trees, methodTree{method: method, root: root})
 	}
 	root.addRoute(path, handlers)
+	engine.lastRoute = path
 
-	if paramsCount := countParams(path); paramsCount > engine.maxParams {
+	paramsCount := countParams(path)
+	if host != nil {
+		paramsCount += uint16(len(host.names))
+	}
+	if paramsCount > engine.maxParams {
 		engine.maxParams = paramsCount
 	}
 
@@ -285,16 +304,22 @@ func (engine *Engine) addRoute(method, path string, handlers HandlersChain) {
 // the http method, path and the handler name.
 func (engine *Engine) Routes() (routes RoutesInfo) {
 	for _, tree := range engine.trees {
-		routes = iterate("", tree.method, routes, tree.root)
+		routes = iterate("", "", tree.method, routes, tree.root)
+	}
+	for _, ht := range engine.hosts {
+		for _, tree := range ht.trees {
+			routes = iterate(ht.pattern, "", tree.method, routes, tree.root)
+		}
 	}
 	return routes
 }
 
-func iterate(path, method string, routes RoutesInfo, root *node) RoutesInfo {
+func iterate(host, path, method string, routes RoutesInfo, root *node) RoutesInfo {
 	path += root.path
 	if len(root.handlers) > 0 {
 		handlerFunc := root.handlers.Last()
 		routes = append(routes, RouteInfo{
+			Host:        host,
 			Method:      method,
 			Path:        path,
 			Handler:     ref.NameOfFunc(handlerFunc),
@@ -302,7 +327,9 @@ func iterate(path, method string, routes RoutesInfo, root *node) RoutesInfo {
 		})
 	}
 	for _, child := range root.children {
-		routes = iterate(path, method, routes, child)
+		for ; child != nil; child = child.next {
+			routes = iterate(host, path, method, routes, child)
+		}
 	}
 	return routes
 }
@@ -396,8 +423,13 @@ func (engine *Engine) handleHTTPRequest(c *Context) {
 		rPath = cleanPath(rPath)
 	}
 
+	// Find the route trees of the request host
+	t, hps := engine.trees, Params(nil)
+	if len(engine.hosts) > 0 {
+		t, hps = engine.matchHost(c.RequestHostname())
+	}
+
 	// Find root of the tree for the given HTTP method
-	t := engine.trees
 	for i, tl := 0, len(t); i < tl; i++ {
 		if t[i].method != httpMethod {
 			continue
@@ -408,6 +440,9 @@ func (engine *Engine) handleHTTPRequest(c *Context) {
 		if value.params != nil {
 			c.Params = *value.params
 		}
+		if hps != nil {
+			c.Params = append(c.Params, hps...)
+		}
 		if value.handlers != nil {
 			c.handlers = value.handlers
 			c.fullPath = value.fullPath
@@ -431,7 +466,7 @@ func (engine *Engine) handleHTTPRequest(c *Context) {
 		// According to RFC 7231 section 6.5.5, MUST generate an Allow header field in response
 		// containing a list of the target resource's currently supported methods.
 		allowed := make([]string, 0, len(t)-1)
-		for _, tree := range engine.trees {
+		for _, tree := range t {
 			if tree.method == httpMethod {
 				continue
 			}
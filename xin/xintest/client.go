@@ -0,0 +1,135 @@
+// Package xintest provides a fluent in-process test client for the xin engine.
+//
+//	client := xintest.NewClient(t, router)
+//	client.GET("/users").WithQuery("page", "2").Do().
+//		AssertStatus(http.StatusOK).
+//		AssertJSON("data.0.name", "alice")
+package xintest
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+
+	"github.com/askasoft/pango/test/require"
+	"github.com/askasoft/pango/xin"
+	"github.com/askasoft/pango/xin/middleware"
+)
+
+// Client drives a xin engine in-process.
+// The cookies of the responses are stored in the cookie jar and sent with the following requests.
+type Client struct {
+	// T the testing object for assertions
+	T require.TestingT
+
+	// Engine the xin engine to serve the requests
+	Engine *xin.Engine
+
+	// BaseURL the base url of the requests, default is "https://example.com".
+	// The https scheme is required to send back the secure cookies.
+	BaseURL string
+
+	// Header the default headers of the requests
+	Header http.Header
+
+	// Jar the cookie jar, nil to disable the cookie management
+	Jar http.CookieJar
+
+	// TokenCookieName the cookie name of the CSRF token, default is middleware.TokenCookieName.
+	// If the cookie is present in the Jar, the value is sent as the TokenHeaderName header
+	// with the DELETE, PATCH, POST, PUT requests automatically.
+	// Set it to empty to disable the automatic CSRF token handling.
+	TokenCookieName string
+
+	// TokenHeaderName the header name of the CSRF token, default is middleware.TokenHeaderName
+	TokenHeaderName string
+}
+
+// NewClient create a test client for the engine
+func NewClient(t require.TestingT, engine *xin.Engine) *Client {
+	jar, _ := cookiejar.New(nil)
+
+	return &Client{
+		T:               t,
+		Engine:          engine,
+		BaseURL:         "https://example.com",
+		Header:          http.Header{},
+		Jar:             jar,
+		TokenCookieName: middleware.TokenCookieName,
+		TokenHeaderName: middleware.TokenHeaderName,
+	}
+}
+
+// GET creates a GET request
+func (c *Client) GET(path string) *Request {
+	return c.Request(http.MethodGet, path)
+}
+
+// HEAD creates a HEAD request
+func (c *Client) HEAD(path string) *Request {
+	return c.Request(http.MethodHead, path)
+}
+
+// OPTIONS creates a OPTIONS request
+func (c *Client) OPTIONS(path string) *Request {
+	return c.Request(http.MethodOptions, path)
+}
+
+// POST creates a POST request
+func (c *Client) POST(path string) *Request {
+	return c.Request(http.MethodPost, path)
+}
+
+// PUT creates a PUT request
+func (c *Client) PUT(path string) *Request {
+	return c.Request(http.MethodPut, path)
+}
+
+// PATCH creates a PATCH request
+func (c *Client) PATCH(path string) *Request {
+	return c.Request(http.MethodPatch, path)
+}
+
+// DELETE creates a DELETE request
+func (c *Client) DELETE(path string) *Request {
+	return c.Request(http.MethodDelete, path)
+}
+
+// Request creates a request with the method and path.
+// The path may contain the query string.
+func (c *Client) Request(method, path string) *Request {
+	return &Request{
+		client: c,
+		method: method,
+		path:   path,
+		query:  url.Values{},
+		header: c.Header.Clone(),
+	}
+}
+
+// Cookie returns the cookie value of the name for the BaseURL in the cookie jar.
+func (c *Client) Cookie(name string) string {
+	if c.Jar == nil {
+		return ""
+	}
+
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return ""
+	}
+
+	for _, ck := range c.Jar.Cookies(u) {
+		if ck.Name == name {
+			return ck.Value
+		}
+	}
+	return ""
+}
+
+// Token returns the CSRF token in the cookie jar.
+func (c *Client) Token() string {
+	if c.TokenCookieName == "" {
+		return ""
+	}
+	return c.Cookie(c.TokenCookieName)
+}
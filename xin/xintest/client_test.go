@@ -0,0 +1,122 @@
+package xintest
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/askasoft/pango/xin"
+	"github.com/askasoft/pango/xin/middleware"
+)
+
+func newTestEngine() *xin.Engine {
+	router := xin.New()
+
+	tp := middleware.NewTokenProtector("1234567890123456")
+	router.Use(tp.Handle)
+
+	router.GET("/token", func(c *xin.Context) {
+		c.String(http.StatusOK, tp.RefreshToken(c))
+	})
+	router.GET("/users", func(c *xin.Context) {
+		c.JSON(http.StatusOK, xin.H{
+			"page": c.Query("page"),
+			"data": []xin.H{{"name": "alice", "age": 20}, {"name": "bob", "age": 30}},
+		})
+	})
+	router.POST("/users", func(c *xin.Context) {
+		var u struct {
+			Name string `json:"name"`
+		}
+		if err := c.BindJSON(&u); err != nil {
+			return
+		}
+		c.JSON(http.StatusCreated, u)
+	})
+	router.POST("/login", func(c *xin.Context) {
+		c.SetCookie(&http.Cookie{Name: "sid", Value: c.PostForm("user"), Path: "/"})
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/me", func(c *xin.Context) {
+		sid, _ := c.Cookie("sid")
+		c.Header("X-User", sid)
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(
+			`<html><body><div id="main"><ul class="list"><li class="item active"><a href="/a">A</a></li><li class="item">B  <b>b</b></li></ul></div><p>P</p></body></html>`,
+		))
+	})
+
+	return router
+}
+
+func TestClientJSON(t *testing.T) {
+	client := NewClient(t, newTestEngine())
+
+	client.GET("/users").WithQuery("page", "2").Do().
+		AssertStatus(http.StatusOK).
+		AssertHeaderContains("Content-Type", "application/json").
+		AssertJSON("page", "2").
+		AssertJSON("data.0.name", "alice").
+		AssertJSON("data.1.age", 30).
+		AssertJSON("data.1", map[string]any{"name": "bob", "age": 30})
+
+	res := client.GET("/users?page=3").Do()
+	if _, ok := res.JSON("data.2"); ok {
+		t.Error("data.2 should not exist")
+	}
+	if v, ok := res.JSON("page"); !ok || v != "3" {
+		t.Errorf("page = %v, want 3", v)
+	}
+}
+
+func TestClientCSRF(t *testing.T) {
+	client := NewClient(t, newTestEngine())
+
+	client.POST("/users").WithJSON(xin.H{"name": "carol"}).Do().
+		AssertStatus(http.StatusForbidden)
+
+	client.GET("/token").Do().AssertStatus(http.StatusOK)
+	if client.Token() == "" {
+		t.Fatal("empty token")
+	}
+
+	client.POST("/users").WithJSON(xin.H{"name": "carol"}).Do().
+		AssertStatus(http.StatusCreated).
+		AssertJSON("name", "carol")
+
+	client.TokenCookieName = ""
+	client.POST("/users").WithJSON(xin.H{"name": "carol"}).Do().
+		AssertStatus(http.StatusForbidden)
+}
+
+func TestClientCookieJar(t *testing.T) {
+	client := NewClient(t, newTestEngine())
+
+	client.GET("/token").Do()
+	client.POST("/login").WithForm(url.Values{"user": {"alice"}}).Do().
+		AssertStatus(http.StatusNoContent)
+
+	if sid := client.Cookie("sid"); sid != "alice" {
+		t.Errorf("sid = %q, want alice", sid)
+	}
+
+	client.GET("/me").Do().AssertHeader("X-User", "alice")
+	client.GET("/me").WithCookie("sid", "bob").Do().AssertHeader("X-User", "alice")
+
+	client.Jar = nil
+	client.GET("/me").WithCookie("sid", "bob").Do().AssertHeader("X-User", "bob")
+}
+
+func TestClientHTML(t *testing.T) {
+	client := NewClient(t, newTestEngine())
+
+	client.GET("/me").Do().
+		AssertStatus(http.StatusOK).
+		AssertHTML("#main li.active a", "A").
+		AssertHTML("ul .item", "A").
+		AssertHTML("li.item[class='item']", "B b").
+		AssertHTMLCount("ul > li", 2).
+		AssertHTMLCount("div > li", 0).
+		AssertHTMLCount("a[href='/a']", 1).
+		AssertHTMLCount("li, p", 3).
+		AssertHTML("#main .item b", "b")
+}
@@ -0,0 +1,139 @@
+package xintest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/askasoft/pango/test/require"
+	"github.com/askasoft/pango/xin/binding"
+)
+
+// Request is a fluent request builder of the Client
+type Request struct {
+	client  *Client
+	method  string
+	path    string
+	query   url.Values
+	header  http.Header
+	cookies []*http.Cookie
+	body    io.Reader
+	err     error
+}
+
+// WithQuery adds the query parameter values
+func (r *Request) WithQuery(key string, values ...string) *Request {
+	for _, v := range values {
+		r.query.Add(key, v)
+	}
+	return r
+}
+
+// WithQueries adds the query parameters
+func (r *Request) WithQueries(vs url.Values) *Request {
+	for k, v := range vs {
+		r.query[k] = append(r.query[k], v...)
+	}
+	return r
+}
+
+// WithHeader sets the request header
+func (r *Request) WithHeader(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// WithCookie adds the request cookie
+func (r *Request) WithCookie(name, value string) *Request {
+	r.cookies = append(r.cookies, &http.Cookie{Name: name, Value: value})
+	return r
+}
+
+// WithBody sets the request body and the Content-Type header
+func (r *Request) WithBody(contentType string, body io.Reader) *Request {
+	r.header.Set("Content-Type", contentType)
+	r.body = body
+	return r
+}
+
+// WithJSON sets the JSON encoded v as the request body
+func (r *Request) WithJSON(v any) *Request {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	return r.WithBody(binding.MIMEJSON, bytes.NewReader(bs))
+}
+
+// WithForm sets the url encoded form values as the request body
+func (r *Request) WithForm(vs url.Values) *Request {
+	return r.WithBody(binding.MIMEPOSTForm, strings.NewReader(vs.Encode()))
+}
+
+// Do serves the request by the engine and returns the response
+func (r *Request) Do() *Response {
+	c := r.client
+	t := c.T
+
+	require.NoError(t, r.err, "%s %s", r.method, r.path)
+
+	u, err := url.Parse(c.BaseURL)
+	require.NoError(t, err, "%s %s", r.method, r.path)
+
+	ref, err := url.Parse(r.path)
+	require.NoError(t, err, "%s %s", r.method, r.path)
+
+	u = u.ResolveReference(ref)
+	if len(r.query) > 0 {
+		q := u.Query()
+		for k, v := range r.query {
+			q[k] = append(q[k], v...)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	req := httptest.NewRequest(r.method, u.String(), r.body)
+	req.Header = r.header
+
+	if c.Jar != nil {
+		for _, ck := range c.Jar.Cookies(u) {
+			req.AddCookie(ck)
+		}
+	}
+	for _, ck := range r.cookies {
+		req.AddCookie(ck)
+	}
+
+	if c.TokenHeaderName != "" && req.Header.Get(c.TokenHeaderName) == "" && isUnsafeMethod(r.method) {
+		if ts := c.Token(); ts != "" {
+			req.Header.Set(c.TokenHeaderName, ts)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	c.Engine.ServeHTTP(w, req)
+
+	if c.Jar != nil {
+		c.Jar.SetCookies(u, w.Result().Cookies())
+	}
+
+	return &Response{
+		T:        t,
+		Request:  req,
+		Recorder: w,
+	}
+}
+
+func isUnsafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return false
+	default:
+		return true
+	}
+}
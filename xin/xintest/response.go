@@ -0,0 +1,204 @@
+package xintest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/askasoft/pango/test/assert"
+	"github.com/askasoft/pango/test/require"
+	"golang.org/x/net/html"
+)
+
+// Response is the recorded response of the Request
+type Response struct {
+	T        require.TestingT
+	Request  *http.Request
+	Recorder *httptest.ResponseRecorder
+
+	json any
+	jerr error
+	doc  *html.Node
+	herr error
+}
+
+func (r *Response) title() string {
+	return r.Request.Method + " " + r.Request.URL.RequestURI()
+}
+
+// Code returns the status code of the response
+func (r *Response) Code() int {
+	return r.Recorder.Code
+}
+
+// Header returns the header of the response
+func (r *Response) Header() http.Header {
+	return r.Recorder.Header()
+}
+
+// Body returns the body string of the response
+func (r *Response) Body() string {
+	return r.Recorder.Body.String()
+}
+
+// Cookies returns the cookies set by the response
+func (r *Response) Cookies() []*http.Cookie {
+	return r.Recorder.Result().Cookies()
+}
+
+// Decode decodes the JSON response body to v
+func (r *Response) Decode(v any) error {
+	return json.Unmarshal(r.Recorder.Body.Bytes(), v)
+}
+
+// JSON returns the value of the JSON response body at the dot separated path,
+// e.g. "data.items.0.name". An empty path returns the entire document.
+func (r *Response) JSON(path string) (any, bool) {
+	if r.json == nil && r.jerr == nil {
+		r.jerr = r.Decode(&r.json)
+	}
+	if r.jerr != nil {
+		return nil, false
+	}
+	return lookupJSON(r.json, path)
+}
+
+// Find returns the HTML element nodes of the response body matched the CSS selector.
+// The supported selectors are: `tag`, `#id`, `.class`, `[attr]`, `[attr=value]`,
+// the descendant combinator (` `), the child combinator (`>`) and the selector group (`,`).
+func (r *Response) Find(selector string) []*html.Node {
+	ns, _ := r.find(selector)
+	return ns
+}
+
+func (r *Response) find(selector string) ([]*html.Node, error) {
+	sel, err := ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.doc == nil && r.herr == nil {
+		r.doc, r.herr = html.Parse(bytes.NewReader(r.Recorder.Body.Bytes()))
+	}
+	if r.herr != nil {
+		return nil, r.herr
+	}
+	return sel.Find(r.doc), nil
+}
+
+// AssertStatus asserts the status code of the response
+func (r *Response) AssertStatus(code int) *Response {
+	assert.Equal(r.T, code, r.Recorder.Code, "%s status", r.title())
+	return r
+}
+
+// AssertHeader asserts the header value of the response
+func (r *Response) AssertHeader(key, value string) *Response {
+	assert.Equal(r.T, value, r.Header().Get(key), "%s header %s", r.title(), key)
+	return r
+}
+
+// AssertHeaderContains asserts the header value of the response contains the substring
+func (r *Response) AssertHeaderContains(key, substr string) *Response {
+	assert.Contains(r.T, r.Header().Get(key), substr, "%s header %s", r.title(), key)
+	return r
+}
+
+// AssertBody asserts the body of the response
+func (r *Response) AssertBody(body string) *Response {
+	assert.Equal(r.T, body, r.Body(), "%s body", r.title())
+	return r
+}
+
+// AssertBodyContains asserts the body of the response contains the substring
+func (r *Response) AssertBodyContains(substr string) *Response {
+	assert.Contains(r.T, r.Body(), substr, "%s body", r.title())
+	return r
+}
+
+// AssertJSON asserts the value of the JSON response body at the path.
+// The want value is normalized by the JSON encoding, so `AssertJSON("count", 1)` is valid.
+func (r *Response) AssertJSON(path string, want any) *Response {
+	a, ok := r.JSON(path)
+	if !ok {
+		if r.jerr != nil {
+			assert.NoError(r.T, r.jerr, "%s json", r.title())
+		} else {
+			assert.Fail(r.T, "%s json path %q not found", r.title(), path)
+		}
+		return r
+	}
+
+	w, err := normalizeJSON(want)
+	if !assert.NoError(r.T, err, "%s json %q", r.title(), path) {
+		return r
+	}
+
+	assert.Equal(r.T, w, a, "%s json %q", r.title(), path)
+	return r
+}
+
+// AssertHTML asserts the text of the first HTML element matched the selector
+func (r *Response) AssertHTML(selector, text string) *Response {
+	ns, err := r.find(selector)
+	if !assert.NoError(r.T, err, "%s html %q", r.title(), selector) {
+		return r
+	}
+	if len(ns) == 0 {
+		assert.Fail(r.T, "%s html %q not found", r.title(), selector)
+		return r
+	}
+
+	assert.Equal(r.T, text, nodeText(ns[0]), "%s html %q", r.title(), selector)
+	return r
+}
+
+// AssertHTMLCount asserts the count of the HTML elements matched the selector
+func (r *Response) AssertHTMLCount(selector string, count int) *Response {
+	ns, err := r.find(selector)
+	if !assert.NoError(r.T, err, "%s html %q", r.title(), selector) {
+		return r
+	}
+	assert.Equal(r.T, count, len(ns), "%s html %q count", r.title(), selector)
+	return r
+}
+
+func normalizeJSON(v any) (any, error) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var n any
+	err = json.Unmarshal(bs, &n)
+	return n, err
+}
+
+func lookupJSON(v any, path string) (any, bool) {
+	if path == "" {
+		return v, true
+	}
+
+	for key := range strings.SplitSeq(path, ".") {
+		switch o := v.(type) {
+		case map[string]any:
+			e, ok := o[key]
+			if !ok {
+				return nil, false
+			}
+			v = e
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(o) {
+				return nil, false
+			}
+			v = o[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
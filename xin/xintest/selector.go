@@ -0,0 +1,239 @@
+package xintest
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Selector is a compiled simple CSS selector
+type Selector []*complexSelector
+
+// complexSelector is a sequence of compound selectors joined by combinators
+type complexSelector struct {
+	compounds   []*compoundSelector
+	combinators []byte // ' ' or '>', combinators[i] joins compounds[i] and compounds[i+1]
+}
+
+type attrSelector struct {
+	key   string
+	value string
+	exist bool // match [key] only
+}
+
+type compoundSelector struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrSelector
+}
+
+// ParseSelector parses the CSS selector.
+// The supported selectors are: `tag`, `*`, `#id`, `.class`, `[attr]`, `[attr=value]`,
+// the descendant combinator (` `), the child combinator (`>`) and the selector group (`,`).
+func ParseSelector(s string) (Selector, error) {
+	var sel Selector
+
+	for g := range strings.SplitSeq(s, ",") {
+		cs, err := parseComplexSelector(g)
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, cs)
+	}
+	return sel, nil
+}
+
+func parseComplexSelector(s string) (*complexSelector, error) {
+	s = strings.ReplaceAll(s, ">", " > ")
+
+	cs := &complexSelector{}
+
+	comb := byte(' ')
+	for _, f := range strings.Fields(s) {
+		if f == ">" {
+			if len(cs.compounds) == 0 || comb == '>' {
+				return nil, errors.New("xintest: invalid selector '" + s + "'")
+			}
+			comb = '>'
+			continue
+		}
+
+		cp, err := parseCompoundSelector(f)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(cs.compounds) > 0 {
+			cs.combinators = append(cs.combinators, comb)
+		}
+		cs.compounds = append(cs.compounds, cp)
+		comb = ' '
+	}
+
+	if len(cs.compounds) == 0 || comb == '>' {
+		return nil, errors.New("xintest: invalid selector '" + s + "'")
+	}
+	return cs, nil
+}
+
+func parseCompoundSelector(s string) (*compoundSelector, error) {
+	invalid := errors.New("xintest: invalid selector '" + s + "'")
+
+	cp := &compoundSelector{}
+
+	i := strings.IndexAny(s, "#.[")
+	if i < 0 {
+		i = len(s)
+	}
+	if tag := s[:i]; tag != "*" {
+		cp.tag = strings.ToLower(tag)
+	}
+
+	for s = s[i:]; s != ""; {
+		switch s[0] {
+		case '[':
+			j := strings.IndexByte(s, ']')
+			if j < 0 {
+				return nil, invalid
+			}
+
+			k, v, ok := strings.Cut(s[1:j], "=")
+			if k == "" {
+				return nil, invalid
+			}
+			cp.attrs = append(cp.attrs, attrSelector{key: k, value: strings.Trim(v, `"'`), exist: !ok})
+			s = s[j+1:]
+		case '#', '.':
+			j := strings.IndexAny(s[1:], "#.[")
+			if j < 0 {
+				j = len(s) - 1
+			}
+
+			name := s[1 : j+1]
+			if name == "" {
+				return nil, invalid
+			}
+
+			if s[0] == '#' {
+				cp.id = name
+			} else {
+				cp.classes = append(cp.classes, name)
+			}
+			s = s[j+1:]
+		default:
+			return nil, invalid
+		}
+	}
+	return cp, nil
+}
+
+// Find returns the element nodes under the root that match the selector, in document order.
+func (sel Selector) Find(root *html.Node) (ns []*html.Node) {
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && sel.Match(c) {
+				ns = append(ns, c)
+			}
+			walk(c)
+		}
+	}
+	walk(root)
+	return
+}
+
+// Match returns true if the element node matches the selector.
+func (sel Selector) Match(n *html.Node) bool {
+	for _, cs := range sel {
+		if cs.match(n, len(cs.compounds)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cs *complexSelector) match(n *html.Node, i int) bool {
+	if !cs.compounds[i].match(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+
+	if cs.combinators[i-1] == '>' {
+		p := n.Parent
+		return p != nil && p.Type == html.ElementNode && cs.match(p, i-1)
+	}
+
+	for p := n.Parent; p != nil && p.Type == html.ElementNode; p = p.Parent {
+		if cs.match(p, i-1) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cp *compoundSelector) match(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if cp.tag != "" && cp.tag != n.Data {
+		return false
+	}
+	if cp.id != "" && getAttr(n, "id") != cp.id {
+		return false
+	}
+
+	if len(cp.classes) > 0 {
+		cls := strings.Fields(getAttr(n, "class"))
+		for _, c := range cp.classes {
+			if !slices.Contains(cls, c) {
+				return false
+			}
+		}
+	}
+
+	for _, a := range cp.attrs {
+		v, ok := lookupAttr(n, a.key)
+		if !ok || (!a.exist && v != a.value) {
+			return false
+		}
+	}
+	return true
+}
+
+func lookupAttr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func getAttr(n *html.Node, key string) string {
+	v, _ := lookupAttr(n, key)
+	return v
+}
+
+// nodeText returns the whitespace compacted text content of the node
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
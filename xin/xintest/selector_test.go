@@ -0,0 +1,58 @@
+package xintest
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestSelectorFind(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<div id="a" class="x y"><p class="x">1</p><span><p data-k="v">2</p></span></div><p>3</p>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs := []struct {
+		sel  string
+		want string
+	}{
+		{"p", "1 2 3"},
+		{"*#a", "12"},
+		{".x", "12 1"},
+		{".x.y", "12"},
+		{"div p", "1 2"},
+		{"div > p", "1"},
+		{"div>span>p", "2"},
+		{"body > p", "3"},
+		{"[data-k]", "2"},
+		{"p[data-k=v]", "2"},
+		{`p[data-k="w"]`, ""},
+		{"#a p.x, span", "1 2"},
+		{"table", ""},
+	}
+
+	for i, c := range cs {
+		sel, err := ParseSelector(c.sel)
+		if err != nil {
+			t.Errorf("[%d] ParseSelector(%q) = %v", i, c.sel, err)
+			continue
+		}
+
+		var ts []string
+		for _, n := range sel.Find(doc) {
+			ts = append(ts, nodeText(n))
+		}
+		if a := strings.Join(ts, " "); a != c.want {
+			t.Errorf("[%d] Find(%q) = %q, want %q", i, c.sel, a, c.want)
+		}
+	}
+}
+
+func TestParseSelectorInvalid(t *testing.T) {
+	for i, s := range []string{"", "> p", "p >", "p > > a", "p,", "#", "p.", "a[", "a[=b]"} {
+		if _, err := ParseSelector(s); err == nil {
+			t.Errorf("[%d] ParseSelector(%q) should fail", i, s)
+		}
+	}
+}